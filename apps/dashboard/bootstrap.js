@@ -17,8 +17,8 @@ window.navigateTo = (path) => {
 }
 
 // Global logout handler
-window.handleLogout = () => {
-    auth.logout()
+window.handleLogout = async () => {
+    await auth.logout()
     router.navigate('/login')
 }
 
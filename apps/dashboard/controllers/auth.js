@@ -134,8 +134,8 @@ export const authController = {
         }
     },
 
-    handleLogout() {
-        auth.logout()
+    async handleLogout() {
+        await auth.logout()
         window.navigateTo('/login')
     }
 }
\ No newline at end of file
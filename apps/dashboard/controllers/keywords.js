@@ -3,17 +3,43 @@ import { keywords } from '/model/index.js'
 
 export const keywordsController = {
     allKeywords: [],
+    events: null,
 
     async loadKeywords() {
         try {
             this.allKeywords = await keywords.loadKeywords()
             this.displayKeywords(this.allKeywords)
+            this.subscribeEvents()
         } catch (error) {
             document.getElementById('keywords-list').innerHTML = 
                 '<div class="error">Failed to load keywords</div>'
         }
     },
 
+    // Keep the list up to date with the live status changes, until the keywords screen is left
+    subscribeEvents() {
+        if (this.events) return
+
+        const events = new AbortController()
+        this.events = events
+
+        keywords.subscribe((type, event) => {
+            if (!document.getElementById('keywords-list')) {
+                events.abort()
+                return
+            }
+            if (type !== 'keyword') return
+
+            const i = this.allKeywords.findIndex(keyword => keyword.id === event.id)
+            if (i === -1) return
+
+            this.allKeywords[i] = keywords.applyEvent(this.allKeywords[i], event)
+            this.filterKeywords()
+        }, events.signal).catch(() => {}).finally(() => {
+            if (this.events === events) this.events = null
+        })
+    },
+
     displayKeywords(keywordsList) {
         const listEl = document.getElementById('keywords-list')
         
@@ -60,6 +86,10 @@ export const keywordsController = {
         return await keywords.getById(id)
     },
 
+    async getKeywordHtml(id) {
+        return await keywords.getHtml(id)
+    },
+
     formatKeyword(keyword) {
         return keywords.formatKeyword(keyword)
     },
@@ -254,11 +254,17 @@ export class Router {
                 document.getElementById('error-message').textContent = formatted.errorMessage
             }
             
-            // Show content
-            if (formatted.htmlContent) {
-                document.getElementById('html-preview').innerHTML = formatted.htmlContent
+            // Show content in a sandboxed iframe
+            const htmlContent = await keywordsController.getKeywordHtml(keywordId).catch(() => null)
+            const htmlPreview = document.getElementById('html-preview')
+            if (typeof htmlContent === 'string') {
+                const iframe = document.createElement('iframe')
+                iframe.setAttribute('sandbox', '')
+                iframe.className = 'html-preview-frame'
+                iframe.srcdoc = htmlContent
+                htmlPreview.replaceChildren(iframe)
             } else {
-                document.getElementById('html-preview').innerHTML = '<p>No content available</p>'
+                htmlPreview.innerHTML = '<p>No content available</p>'
             }
             
             // Show raw data
@@ -87,7 +87,9 @@ export const uploadController = {
             successMessage.style.border = '1px solid var(--success, #22c55e)'
             successMessage.style.borderRadius = '4px'
             successMessage.style.backgroundColor = 'var(--success-bg, #f0fdf4)'
-            successMessage.textContent = 'Keywords uploaded successfully! Processing has started.'
+            successMessage.textContent = response && typeof response.created === 'number'
+                ? `Keywords uploaded: ${response.created} created, ${response.duplicate} duplicate, ${response.invalid} invalid. Processing has started.`
+                : 'Keywords uploaded successfully! Processing has started.'
             
             // Insert success message before the error message element
             errorEl.parentNode.insertBefore(successMessage, errorEl)
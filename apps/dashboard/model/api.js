@@ -19,6 +19,52 @@ class ApiClient {
     localStorage.removeItem('refresh_token')
   }
 
+  // Exchange the refresh token for a new pair of tokens. Concurrent callers share the same request,
+  // as a refresh token can be used only once and reusing it signs the user out.
+  refreshTokens() {
+    if (!this.refreshing) {
+      this.refreshing = (async () => {
+        const refresh = localStorage.getItem('refresh_token')
+        if (!refresh) return false
+
+        try {
+          const response = await fetch(`${this.baseUrl}/users/refresh`, {
+            method: 'POST',
+            headers: { 'Content-Type': 'application/json' },
+            body: JSON.stringify({ refresh })
+          })
+          if (!response.ok) return false
+
+          const tokens = await response.json()
+          this.setToken(tokens.access)
+          localStorage.setItem('refresh_token', tokens.refresh)
+          return true
+        } catch (e) {
+          return false
+        }
+      })().finally(() => {
+        this.refreshing = null
+      })
+    }
+
+    return this.refreshing
+  }
+
+  // Revoke the current session on the server; signing out locally must not depend on it
+  async signOut() {
+    const token = this.getToken()
+    if (!token) return
+
+    try {
+      await fetch(`${this.baseUrl}/users/sign-out`, {
+        method: 'POST',
+        headers: { 'Authorization': `Bearer ${token}` }
+      })
+    } catch (e) {
+      // Ignore, the tokens are cleared anyway
+    }
+  }
+
   async request(endpoint, options = {}) {
     const url = `${this.baseUrl}${endpoint}`
     
@@ -44,6 +90,11 @@ class ApiClient {
       const response = await fetch(url, config)
       
       if (response.status === 401) {
+        // Retry once with new tokens if the access token has expired
+        if (endpoint !== '/users/sign-in' && !options.retried && await this.refreshTokens()) {
+          return this.request(endpoint, { ...options, retried: true })
+        }
+
         // Check if this is a login request - don't redirect for login failures
         if (endpoint === '/users/sign-in') {
           // Let login errors fall through to normal error handling
@@ -125,7 +176,7 @@ class ApiClient {
     })
   }
 
-  async postFormData(endpoint, formData) {
+  async postFormData(endpoint, formData, retried = false) {
     const url = `${this.baseUrl}${endpoint}`
     
     const headers = {}
@@ -147,6 +198,11 @@ class ApiClient {
       const response = await fetch(url, config)
       
       if (response.status === 401) {
+        // Retry once with new tokens if the access token has expired
+        if (!retried && await this.refreshTokens()) {
+          return this.postFormData(endpoint, formData, true)
+        }
+
         // Check if this is a login request - don't redirect for login failures
         if (endpoint === '/users/sign-in') {
           // Let login errors fall through to normal error handling
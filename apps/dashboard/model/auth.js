@@ -64,7 +64,8 @@ export const auth = {
     return response
   },
 
-  logout() {
+  async logout() {
+    await api.signOut()
     api.clearToken()
     localStorage.removeItem('user_email')
     localStorage.removeItem('pending_activation_email')
@@ -3,11 +3,12 @@ import { api } from '/model/api.js'
 
 export const keywords = {
   // GET /keywords - Get the list of keywords uploaded by current user
-  // Returns: KeywordDTO[]
+  // Query: page, per_page (max 100), status, search_engine, created_from, created_to, q, sort
+  // Returns: KeywordDTO[] with the total count in X-Total-Count header
   // Requires: BearerToken authentication
   async getAll() {
-    console.log('Fetching all keywords...')
-    return await api.get('/keywords')
+    console.log('Fetching latest keywords...')
+    return await api.get('/keywords?per_page=100')
   },
 
   // GET /keywords/{id} - Get the result of a keyword uploaded by current user
@@ -19,9 +20,63 @@ export const keywords = {
     return await api.get(`/keywords/${id}`)
   },
 
-  // POST /keywords - Upload the keywords CSV file to scrape on web
-  // Content-Type: multipart/form-data or text/csv
-  // Returns: 202 Accepted
+  // GET /keywords/{id}/html - Get the cached search results page of a keyword, with scripts stripped
+  // Parameters: id (string) - Keyword ID
+  // Returns: text/html
+  // Requires: BearerToken authentication
+  async getHtml(id) {
+    console.log('Fetching keyword HTML by ID:', id)
+    return await api.get(`/keywords/${id}/html`)
+  },
+
+  // GET /keywords/events - Stream the status and result changes of the keywords uploaded by current user
+  // Returns: text/event-stream with "keyword" events; id, status and the changed fields of a KeywordDTO
+  // Requires: BearerToken authentication, sent with fetch as EventSource can't set headers
+  // Resolves when the stream ends; abort the signal to close it
+  async subscribe(onEvent, signal) {
+    const response = await fetch(`${api.baseUrl}/keywords/events`, {
+      headers: { 'Authorization': `Bearer ${api.getToken()}` },
+      signal
+    })
+    if (!response.ok) {
+      throw new Error(`${response.status}: Failed to subscribe keyword events`)
+    }
+
+    const reader = response.body.pipeThrough(new TextDecoderStream()).getReader()
+    let buffer = ''
+    while (true) {
+      const { value, done } = await reader.read()
+      if (done) return
+
+      buffer += value
+      const messages = buffer.split('\n\n')
+      buffer = messages.pop()
+
+      for (const message of messages) {
+        let type = 'message'
+        let data = ''
+        for (const line of message.split('\n')) {
+          if (line.startsWith('event: ')) type = line.slice(7)
+          if (line.startsWith('data: ')) data += line.slice(6)
+        }
+        if (data) onEvent(type, JSON.parse(data))
+      }
+    }
+  },
+
+  // Merge a keyword event into the keyword; the error is only kept for failed keywords
+  applyEvent(keyword, event) {
+    const { id, ...changes } = event
+    return {
+      ...keyword,
+      ...changes,
+      errorMessage: event.status === 'failed' ? (event.errorMessage ?? keyword.errorMessage) : null
+    }
+  },
+
+  // POST /keywords - Upload the keywords file to scrape on web
+  // Content-Type: multipart/form-data with a .csv, .tsv, .json or .xlsx file, or a raw text/csv, text/tab-separated-values or application/json body
+  // Returns: 202 Accepted with RespUpload; created, duplicate and invalid counts, created IDs and per row outcomes
   // Requires: BearerToken authentication
   async uploadCsv(file) {
     console.log('Uploading CSV file:', file.name)
@@ -39,8 +94,7 @@ export const keywords = {
   //   status: string,
   //   adCount: integer,
   //   linkCount: integer,
-  //   errorMessage: string,
-  //   htmlContent: string
+  //   errorMessage: string
   // }
   formatKeyword(keyword) {
     return {
@@ -50,8 +104,7 @@ export const keywords = {
       adCount: keyword.adCount || 0,
       linkCount: keyword.linkCount || 0,
       hasError: !!keyword.errorMessage,
-      errorMessage: keyword.errorMessage,
-      htmlContent: keyword.htmlContent
+      errorMessage: keyword.errorMessage
     }
   },
 
@@ -92,8 +145,8 @@ export const keywords = {
     }
     
     // Validate file type
-    if (file.type !== 'text/csv' && !file.name.endsWith('.csv')) {
-      throw new Error('Please select a CSV file')
+    if (!/\.(csv|tsv|json|xlsx)$/i.test(file.name)) {
+      throw new Error('Please select a CSV, TSV, JSON or XLSX file')
     }
     
     // Validate file size
@@ -10,13 +10,18 @@ import (
 	"syscall"
 
 	"github.com/hibiken/asynq"
+	"github.com/redis/go-redis/v9"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 	gormlogger "gorm.io/gorm/logger"
 
 	"web-scraper.dev/internal/api/router"
+	"web-scraper.dev/internal/attempts"
 	"web-scraper.dev/internal/config"
+	"web-scraper.dev/internal/events"
+	"web-scraper.dev/internal/fairqueue"
 	"web-scraper.dev/internal/mailer"
+	"web-scraper.dev/internal/revocation"
 	"web-scraper.dev/internal/utils/logger"
 	"web-scraper.dev/internal/utils/validator"
 )
@@ -48,11 +53,18 @@ func main() {
 	if len(redisHosts) == 0 {
 		l.Fatal().Msg("Redis connection failure")
 	}
-	asyq := asynq.NewClient(asynq.RedisClientOpt{
+	redisConnOpt := asynq.RedisClientOpt{
 		Addr: redisHosts[0],
-	})
+	}
+	asyq := asynq.NewClient(redisConnOpt)
+	inspector := asynq.NewInspector(redisConnOpt)
+	rdb := redisConnOpt.MakeRedisClient().(redis.UniversalClient)
+	broker := events.NewBroker(rdb)
+	denylist := revocation.NewDenylist(rdb, c.Ed25519JWT.AccessTokenLifetime)
+	limiter := attempts.NewLimiter(rdb, attempts.DefaultPolicy)
+	fair := fairqueue.New(rdb)
 
-	r := router.New(c.Server.TimeoutRead, c.Server.TimeoutWrite, db, ml, l, v, asyq)
+	r := router.New(c.Server.TimeoutRead, c.Server.TimeoutWrite, db, ml, l, v, asyq, inspector, broker, fair, denylist, limiter, c.Server.AdminEmails)
 
 	s := &http.Server{
 		Addr:         fmt.Sprintf(":%d", c.Server.Port),
@@ -61,6 +73,8 @@ func main() {
 		WriteTimeout: c.Server.TimeoutWrite,
 		IdleTimeout:  c.Server.TimeoutIdle,
 	}
+	// Shutdown doesn't wait for the event streams, which stay open until the clients leave
+	s.RegisterOnShutdown(broker.Close)
 
 	closed := make(chan struct{})
 	go func() {
@@ -77,6 +91,18 @@ func main() {
 			l.Error().Err(err).Msg("Server shutdown failure")
 		}
 
+		if err := asyq.Close(); err != nil {
+			l.Error().Err(err).Msg("Asynq client closing failure")
+		}
+
+		if err := inspector.Close(); err != nil {
+			l.Error().Err(err).Msg("Asynq inspector closing failure")
+		}
+
+		if err := rdb.Close(); err != nil {
+			l.Error().Err(err).Msg("Redis client closing failure")
+		}
+
 		sqlDB, err := db.DB()
 		if err == nil {
 			if err = sqlDB.Close(); err != nil {
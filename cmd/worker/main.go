@@ -1,7 +1,11 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"expvar"
 	"fmt"
+	"net/http"
 	"os"
 	"os/signal"
 	"strings"
@@ -12,7 +16,13 @@ import (
 	"gorm.io/gorm"
 	gormlogger "gorm.io/gorm/logger"
 
+	"web-scraper.dev/internal/blocks"
 	"web-scraper.dev/internal/config"
+	"web-scraper.dev/internal/mailer"
+	"web-scraper.dev/internal/proxies"
+	"web-scraper.dev/internal/ratelimit"
+	"web-scraper.dev/internal/searchengine"
+	"web-scraper.dev/internal/tasks"
 	"web-scraper.dev/internal/utils/logger"
 	"web-scraper.dev/internal/workers"
 )
@@ -22,6 +32,7 @@ const fmtDBString = "host=%s user=%s password=%s dbname=%s port=%d sslmode=disab
 func main() {
 	c := config.NewWorkerConf()
 	l := logger.New(true)
+	ml := mailerM(&c.Mailer)
 	db, err := gormDB(&c.DB)
 	if err != nil {
 		l.Fatal().Err(err).Msg("DB connection start failure")
@@ -35,7 +46,63 @@ func main() {
 		Addr: redisHosts[0],
 	}
 
-	scrapeWorker := workers.NewScrapeWorker(redisConnOpt, db, l)
+	envProxies := make([]*proxies.Proxy, 0, len(c.Proxies.URLs))
+	for _, v := range c.Proxies.URLs {
+		if v = strings.TrimSpace(v); v == "" {
+			continue
+		}
+
+		u, err := proxies.ParseURL(v)
+		if err != nil {
+			l.Fatal().Err(err).Msg("Proxy URL parsing failure")
+		}
+		envProxies = append(envProxies, proxies.New(0, u))
+	}
+
+	policy := proxies.DefaultPolicy
+	policy.MinInterval = c.Proxies.MinInterval
+	pool := proxies.NewPool(policy)
+
+	blockPolicy := blocks.Policy{
+		Window:        c.Blocks.Window,
+		MinSamples:    c.Blocks.MinSamples,
+		Threshold:     c.Blocks.Threshold,
+		PauseDuration: c.Blocks.PauseDuration,
+	}
+
+	ratePolicy := ratelimit.Policy{
+		Engine:  ratelimit.Limit{Rate: c.RateLimits.Engine, Burst: c.RateLimits.EngineBurst},
+		Engines: make(map[string]ratelimit.Limit, len(c.RateLimits.Engines)),
+		Proxy:   ratelimit.Limit{Rate: c.RateLimits.Proxy, Burst: c.RateLimits.ProxyBurst},
+	}
+	for engine, rate := range c.RateLimits.Engines {
+		ratePolicy.Engines[engine] = ratelimit.Limit{Rate: rate, Burst: c.RateLimits.EngineBurst}
+	}
+
+	srvConf := asynq.Config{
+		Concurrency:    c.Queues.Concurrency,
+		Queues:         tasks.WorkerQueues(c.Queues.Weights, searchengine.Names()),
+		StrictPriority: c.Queues.StrictPriority,
+	}
+
+	scrapeWorker := workers.NewScrapeWorker(redisConnOpt, srvConf, db, ml, l, pool, envProxies, c.Proxies.SyncInterval, blockPolicy, ratePolicy,
+		c.Fairness.FeedInterval, c.Fairness.Backlog, c.Fairness.UserMaxInFlight)
+
+	var metricsSrv *http.Server
+	if c.MetricsPort != 0 {
+		mux := http.NewServeMux()
+		mux.Handle("/debug/vars", expvar.Handler())
+		metricsSrv = &http.Server{
+			Addr:    fmt.Sprintf(":%d", c.MetricsPort),
+			Handler: mux,
+		}
+
+		go func() {
+			if err := metricsSrv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				l.Error().Err(err).Msg("Metrics server failure")
+			}
+		}()
+	}
 
 	closed := make(chan struct{})
 	go func() {
@@ -49,6 +116,12 @@ func main() {
 			l.Error().Err(err).Msg("Server shutdown failure")
 		}
 
+		if metricsSrv != nil {
+			if err := metricsSrv.Shutdown(context.Background()); err != nil {
+				l.Error().Err(err).Msg("Metrics server shutdown failure")
+			}
+		}
+
 		sqlDB, err := db.DB()
 		if err == nil {
 			if err = sqlDB.Close(); err != nil {
@@ -79,3 +152,20 @@ func gormDB(conf *config.ConfDB) (*gorm.DB, error) {
 	dbString := fmt.Sprintf(fmtDBString, conf.Host, conf.Username, conf.Password, conf.DBName, conf.Port)
 	return gorm.Open(postgres.Open(dbString), &gorm.Config{Logger: gormlogger.Default.LogMode(logLevel)})
 }
+
+func mailerM(conf *config.MailerConf) *mailer.Mailer {
+	appMailerConf := &mailer.Conf{
+		Host: conf.Host,
+		Port: conf.Port,
+		User: conf.User,
+		Pass: conf.Pass,
+		Senders: &mailer.Senders{
+			NoReply: conf.FromNoReply,
+		},
+		Links: &mailer.Links{
+			WebsiteHost: conf.WebsiteHost,
+		},
+	}
+
+	return mailer.New(appMailerConf)
+}
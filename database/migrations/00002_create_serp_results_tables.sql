@@ -0,0 +1,37 @@
+-- +goose Up
+
+CREATE TABLE "serp_results"
+(
+    "id"          BIGSERIAL                NOT NULL,
+    "keyword_id"  INTEGER                  NOT NULL,
+    "position"    INTEGER                  NOT NULL,
+    "title"       TEXT                     NOT NULL,
+    "url"         TEXT                     NOT NULL,
+    "display_url" TEXT,
+    "snippet"     TEXT,
+    "created_at"  TIMESTAMP with time zone NOT NULL,
+    "updated_at"  TIMESTAMP with time zone NOT NULL,
+    PRIMARY KEY ("id")
+);
+
+CREATE INDEX idx_serp_results_keyword_id ON "serp_results" ("keyword_id");
+
+CREATE TABLE "serp_ads"
+(
+    "id"                BIGSERIAL                NOT NULL,
+    "keyword_id"        INTEGER                  NOT NULL,
+    "position"          INTEGER                  NOT NULL,
+    "block"             TEXT                     NOT NULL,
+    "advertiser_domain" TEXT,
+    "headline"          TEXT,
+    "created_at"        TIMESTAMP with time zone NOT NULL,
+    "updated_at"        TIMESTAMP with time zone NOT NULL,
+    PRIMARY KEY ("id")
+);
+
+CREATE INDEX idx_serp_ads_keyword_id ON "serp_ads" ("keyword_id");
+
+-- +goose Down
+
+DROP TABLE IF EXISTS "serp_results";
+DROP TABLE IF EXISTS "serp_ads";
@@ -0,0 +1,50 @@
+-- +goose Up
+
+CREATE TABLE "keyword_scrapes"
+(
+    "id"            BIGSERIAL                NOT NULL,
+    "keyword_id"    INTEGER                  NOT NULL,
+    "status"        TEXT                     NOT NULL,
+    "search_engine" TEXT                     NOT NULL,
+    "worker_host"   TEXT,
+    "ad_count"      BIGINT,
+    "link_count"    BIGINT,
+    "error_message" TEXT,
+    "started_at"    TIMESTAMP with time zone NOT NULL,
+    "finished_at"   TIMESTAMP with time zone,
+    "created_at"    TIMESTAMP with time zone NOT NULL,
+    "updated_at"    TIMESTAMP with time zone NOT NULL,
+    PRIMARY KEY ("id")
+);
+
+CREATE INDEX idx_keyword_scrapes_keyword_id_started_at ON "keyword_scrapes" ("keyword_id", "started_at" DESC);
+
+ALTER TABLE "keywords" ADD COLUMN "last_scrape_id" BIGINT;
+ALTER TABLE "serp_results" ADD COLUMN "keyword_scrape_id" BIGINT;
+ALTER TABLE "serp_ads" ADD COLUMN "keyword_scrape_id" BIGINT;
+
+CREATE INDEX idx_serp_results_keyword_scrape_id ON "serp_results" ("keyword_scrape_id");
+CREATE INDEX idx_serp_ads_keyword_scrape_id ON "serp_ads" ("keyword_scrape_id");
+
+-- Keep the results of the already scraped keywords as their first run
+INSERT INTO "keyword_scrapes" ("keyword_id", "status", "search_engine", "ad_count", "link_count", "error_message",
+                               "started_at", "finished_at", "created_at", "updated_at")
+SELECT "id", "status", "search_engine", "ad_count", "link_count", "error_message",
+       "updated_at", "updated_at", "updated_at", "updated_at"
+FROM "keywords"
+WHERE "status" IN ('completed', 'failed');
+
+UPDATE "keywords" k SET "last_scrape_id" = s."id" FROM "keyword_scrapes" s WHERE s."keyword_id" = k."id";
+UPDATE "serp_results" r SET "keyword_scrape_id" = k."last_scrape_id" FROM "keywords" k WHERE r."keyword_id" = k."id";
+UPDATE "serp_ads" a SET "keyword_scrape_id" = k."last_scrape_id" FROM "keywords" k WHERE a."keyword_id" = k."id";
+
+-- +goose Down
+
+DROP INDEX IF EXISTS idx_serp_results_keyword_scrape_id;
+DROP INDEX IF EXISTS idx_serp_ads_keyword_scrape_id;
+
+ALTER TABLE "serp_results" DROP COLUMN IF EXISTS "keyword_scrape_id";
+ALTER TABLE "serp_ads" DROP COLUMN IF EXISTS "keyword_scrape_id";
+ALTER TABLE "keywords" DROP COLUMN IF EXISTS "last_scrape_id";
+
+DROP TABLE IF EXISTS "keyword_scrapes";
@@ -0,0 +1,17 @@
+-- +goose Up
+
+ALTER TABLE "keywords" ADD COLUMN "schedule" TEXT;
+ALTER TABLE "keywords" ADD COLUMN "next_scrape_at" TIMESTAMP with time zone;
+ALTER TABLE "keywords" ADD COLUMN "last_scraped_at" TIMESTAMP with time zone;
+
+UPDATE "keywords" k SET "last_scraped_at" = s."finished_at" FROM "keyword_scrapes" s WHERE s."id" = k."last_scrape_id";
+
+CREATE INDEX idx_keywords_schedule ON "keywords" ("schedule") WHERE "schedule" IS NOT NULL;
+
+-- +goose Down
+
+DROP INDEX IF EXISTS idx_keywords_schedule;
+
+ALTER TABLE "keywords" DROP COLUMN IF EXISTS "schedule";
+ALTER TABLE "keywords" DROP COLUMN IF EXISTS "next_scrape_at";
+ALTER TABLE "keywords" DROP COLUMN IF EXISTS "last_scraped_at";
@@ -0,0 +1,10 @@
+-- +goose Up
+
+-- Reverse html.EscapeString; "&amp;" must be replaced last
+UPDATE "keywords" SET "html_content" = replace(replace(replace(replace(replace("html_content", '&lt;', '<'), '&gt;', '>'), '&#39;', ''''), '&#34;', '"'), '&amp;', '&')
+WHERE "html_content" IS NOT NULL;
+
+-- +goose Down
+
+UPDATE "keywords" SET "html_content" = replace(replace(replace(replace(replace("html_content", '&', '&amp;'), '''', '&#39;'), '<', '&lt;'), '>', '&gt;'), '"', '&#34;')
+WHERE "html_content" IS NOT NULL;
@@ -0,0 +1,28 @@
+-- +goose Up
+
+CREATE TABLE "uploads"
+(
+    "id"            BIGSERIAL                NOT NULL,
+    "user_id"       UUID                     NOT NULL,
+    "filename"      TEXT                     NOT NULL,
+    "row_count"     INTEGER                  NOT NULL DEFAULT 0,
+    "created_count" INTEGER                  NOT NULL DEFAULT 0,
+    "skipped_count" INTEGER                  NOT NULL DEFAULT 0,
+    "created_at"    TIMESTAMP with time zone NOT NULL,
+    "updated_at"    TIMESTAMP with time zone NOT NULL,
+    PRIMARY KEY ("id")
+);
+
+CREATE INDEX idx_uploads_user_id_created_at ON "uploads" ("user_id", "created_at" DESC);
+
+ALTER TABLE "keywords" ADD COLUMN "upload_id" BIGINT;
+
+CREATE INDEX idx_keywords_upload_id ON "keywords" ("upload_id");
+
+-- +goose Down
+
+DROP INDEX IF EXISTS idx_keywords_upload_id;
+
+ALTER TABLE "keywords" DROP COLUMN IF EXISTS "upload_id";
+
+DROP TABLE IF EXISTS "uploads";
@@ -0,0 +1,20 @@
+-- +goose Up
+
+ALTER TABLE "keywords" ADD COLUMN "market" TEXT NOT NULL DEFAULT '';
+ALTER TABLE "keywords" ADD COLUMN "device" TEXT NOT NULL DEFAULT 'desktop';
+ALTER TABLE "keywords" ADD COLUMN "tags" JSONB;
+
+-- The same keyword can be tracked in different markets and devices
+ALTER TABLE "keywords" DROP CONSTRAINT IF EXISTS uix_keyword_user_keyword_search_engine;
+ALTER TABLE "keywords" ADD CONSTRAINT uix_keyword_user_keyword_search_engine_market_device UNIQUE ("user_id", "keyword", "search_engine", "market", "device");
+
+-- +goose Down
+
+DELETE FROM "keywords" WHERE "market" <> '' OR "device" <> 'desktop';
+
+ALTER TABLE "keywords" DROP CONSTRAINT IF EXISTS uix_keyword_user_keyword_search_engine_market_device;
+ALTER TABLE "keywords" ADD CONSTRAINT uix_keyword_user_keyword_search_engine UNIQUE ("user_id", "keyword", "search_engine");
+
+ALTER TABLE "keywords" DROP COLUMN IF EXISTS "market";
+ALTER TABLE "keywords" DROP COLUMN IF EXISTS "device";
+ALTER TABLE "keywords" DROP COLUMN IF EXISTS "tags";
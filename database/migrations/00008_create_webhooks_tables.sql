@@ -0,0 +1,49 @@
+-- +goose Up
+
+CREATE TABLE "webhooks"
+(
+    "id"         BIGSERIAL                NOT NULL,
+    "user_id"    UUID                     NOT NULL,
+    "url"        TEXT                     NOT NULL,
+    "secret"     TEXT                     NOT NULL,
+    "events"     JSONB                    NOT NULL,
+    "active"     BOOLEAN                  NOT NULL DEFAULT TRUE,
+    "created_at" TIMESTAMP with time zone NOT NULL,
+    "updated_at" TIMESTAMP with time zone NOT NULL,
+    PRIMARY KEY ("id")
+);
+
+CREATE INDEX idx_webhooks_user_id ON "webhooks" ("user_id");
+
+CREATE TABLE "webhook_deliveries"
+(
+    "id"            BIGSERIAL                NOT NULL,
+    "webhook_id"    BIGINT                   NOT NULL,
+    "event_id"      UUID                     NOT NULL,
+    "event_type"    TEXT                     NOT NULL,
+    "payload"       TEXT                     NOT NULL,
+    "status"        TEXT                     NOT NULL,
+    "attempts"      INTEGER                  NOT NULL DEFAULT 0,
+    "response_code" INTEGER,
+    "response_body" TEXT,
+    "error_message" TEXT,
+    "delivered_at"  TIMESTAMP with time zone,
+    "created_at"    TIMESTAMP with time zone NOT NULL,
+    "updated_at"    TIMESTAMP with time zone NOT NULL,
+    PRIMARY KEY ("id")
+);
+
+CREATE INDEX idx_webhook_deliveries_webhook_id_created_at ON "webhook_deliveries" ("webhook_id", "created_at" DESC);
+
+-- Set once all the keywords of an upload are finished, to send upload.completed only once
+ALTER TABLE "uploads" ADD COLUMN "completed_at" TIMESTAMP with time zone;
+
+-- +goose Down
+
+ALTER TABLE "uploads" DROP COLUMN IF EXISTS "completed_at";
+
+DROP INDEX IF EXISTS idx_webhook_deliveries_webhook_id_created_at;
+DROP TABLE IF EXISTS "webhook_deliveries";
+
+DROP INDEX IF EXISTS idx_webhooks_user_id;
+DROP TABLE IF EXISTS "webhooks";
@@ -0,0 +1,14 @@
+-- +goose Up
+
+CREATE TABLE "user_preferences"
+(
+    "user_id"              UUID                     NOT NULL,
+    "upload_summary_email" BOOLEAN                  NOT NULL DEFAULT TRUE,
+    "created_at"           TIMESTAMP with time zone NOT NULL,
+    "updated_at"           TIMESTAMP with time zone NOT NULL,
+    PRIMARY KEY ("user_id")
+);
+
+-- +goose Down
+
+DROP TABLE IF EXISTS "user_preferences";
@@ -0,0 +1,24 @@
+-- +goose Up
+
+CREATE TABLE "refresh_tokens"
+(
+    "id"         TEXT                     NOT NULL,
+    "family_id"  UUID                     NOT NULL,
+    "user_id"    UUID                     NOT NULL,
+    "expires_at" TIMESTAMP with time zone NOT NULL,
+    "used_at"    TIMESTAMP with time zone,
+    "revoked_at" TIMESTAMP with time zone,
+    "created_at" TIMESTAMP with time zone NOT NULL,
+    "updated_at" TIMESTAMP with time zone NOT NULL,
+    PRIMARY KEY ("id")
+);
+
+CREATE INDEX idx_refresh_tokens_family_id ON "refresh_tokens" ("family_id");
+CREATE INDEX idx_refresh_tokens_user_id ON "refresh_tokens" ("user_id");
+
+-- +goose Down
+
+DROP INDEX IF EXISTS idx_refresh_tokens_family_id;
+DROP INDEX IF EXISTS idx_refresh_tokens_user_id;
+
+DROP TABLE IF EXISTS "refresh_tokens";
@@ -0,0 +1,23 @@
+-- +goose Up
+
+CREATE TABLE "api_keys"
+(
+    "id"           BIGSERIAL                NOT NULL,
+    "user_id"      UUID                     NOT NULL,
+    "name"         TEXT                     NOT NULL,
+    "prefix"       TEXT                     NOT NULL,
+    "key_hash"     TEXT                     NOT NULL,
+    "scope"        TEXT                     NOT NULL,
+    "last_used_at" TIMESTAMP with time zone,
+    "revoked_at"   TIMESTAMP with time zone,
+    "created_at"   TIMESTAMP with time zone NOT NULL,
+    "updated_at"   TIMESTAMP with time zone NOT NULL,
+    PRIMARY KEY ("id"),
+    CONSTRAINT uix_api_keys_prefix UNIQUE ("prefix")
+);
+
+CREATE INDEX idx_api_keys_user_id ON "api_keys" ("user_id");
+
+-- +goose Down
+
+DROP TABLE IF EXISTS "api_keys";
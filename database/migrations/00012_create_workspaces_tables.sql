@@ -0,0 +1,85 @@
+-- +goose Up
+
+CREATE TABLE "workspaces"
+(
+    "id"         UUID                     NOT NULL,
+    "name"       TEXT                     NOT NULL,
+    "created_at" TIMESTAMP with time zone NOT NULL,
+    "updated_at" TIMESTAMP with time zone NOT NULL,
+    PRIMARY KEY ("id")
+);
+
+CREATE TABLE "workspace_members"
+(
+    "workspace_id" UUID                     NOT NULL,
+    "user_id"      UUID                     NOT NULL,
+    "role"         TEXT                     NOT NULL,
+    "created_at"   TIMESTAMP with time zone NOT NULL,
+    "updated_at"   TIMESTAMP with time zone NOT NULL,
+    PRIMARY KEY ("workspace_id", "user_id")
+);
+
+CREATE INDEX idx_workspace_members_user_id ON "workspace_members" ("user_id");
+
+CREATE TABLE "workspace_invitations"
+(
+    "id"           BIGSERIAL                NOT NULL,
+    "workspace_id" UUID                     NOT NULL,
+    "email"        TEXT                     NOT NULL,
+    "role"         TEXT                     NOT NULL,
+    "token"        TEXT                     NOT NULL,
+    "invited_by"   UUID                     NOT NULL,
+    "expires_at"   TIMESTAMP with time zone NOT NULL,
+    "accepted_at"  TIMESTAMP with time zone,
+    "created_at"   TIMESTAMP with time zone NOT NULL,
+    "updated_at"   TIMESTAMP with time zone NOT NULL,
+    PRIMARY KEY ("id"),
+    CONSTRAINT uix_workspace_invitations_token UNIQUE ("token")
+);
+
+CREATE INDEX idx_workspace_invitations_workspace_id ON "workspace_invitations" ("workspace_id");
+
+-- Each user has a personal workspace with the same ID, which owns the keywords and the uploads so far
+INSERT INTO "workspaces" ("id", "name", "created_at", "updated_at")
+SELECT "id", 'Personal', NOW(), NOW() FROM "users";
+
+INSERT INTO "workspace_members" ("workspace_id", "user_id", "role", "created_at", "updated_at")
+SELECT "id", "id", 'owner', NOW(), NOW() FROM "users";
+
+ALTER TABLE "keywords" ADD COLUMN "workspace_id" UUID;
+UPDATE "keywords" SET "workspace_id" = "user_id";
+ALTER TABLE "keywords" ALTER COLUMN "workspace_id" SET NOT NULL;
+
+ALTER TABLE "keywords" DROP CONSTRAINT IF EXISTS uix_keyword_user_keyword_search_engine_market_device;
+ALTER TABLE "keywords" ADD CONSTRAINT uix_keyword_workspace_keyword_search_engine_market_device UNIQUE ("workspace_id", "keyword", "search_engine", "market", "device");
+
+ALTER TABLE "uploads" ADD COLUMN "workspace_id" UUID;
+UPDATE "uploads" SET "workspace_id" = "user_id";
+ALTER TABLE "uploads" ALTER COLUMN "workspace_id" SET NOT NULL;
+
+DROP INDEX IF EXISTS idx_uploads_user_id_created_at;
+CREATE INDEX idx_uploads_workspace_id_created_at ON "uploads" ("workspace_id", "created_at" DESC);
+
+-- +goose Down
+
+-- Only the keywords and the uploads of the personal workspaces can go back to their users
+DELETE FROM "keywords" WHERE "workspace_id" <> "user_id";
+DELETE FROM "uploads" WHERE "workspace_id" <> "user_id";
+
+DROP INDEX IF EXISTS idx_uploads_workspace_id_created_at;
+CREATE INDEX idx_uploads_user_id_created_at ON "uploads" ("user_id", "created_at" DESC);
+
+ALTER TABLE "uploads" DROP COLUMN IF EXISTS "workspace_id";
+
+ALTER TABLE "keywords" DROP CONSTRAINT IF EXISTS uix_keyword_workspace_keyword_search_engine_market_device;
+ALTER TABLE "keywords" ADD CONSTRAINT uix_keyword_user_keyword_search_engine_market_device UNIQUE ("user_id", "keyword", "search_engine", "market", "device");
+
+ALTER TABLE "keywords" DROP COLUMN IF EXISTS "workspace_id";
+
+DROP INDEX IF EXISTS idx_workspace_invitations_workspace_id;
+DROP TABLE IF EXISTS "workspace_invitations";
+
+DROP INDEX IF EXISTS idx_workspace_members_user_id;
+DROP TABLE IF EXISTS "workspace_members";
+
+DROP TABLE IF EXISTS "workspaces";
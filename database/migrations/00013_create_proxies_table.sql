@@ -0,0 +1,25 @@
+-- +goose Up
+
+CREATE TABLE "proxies"
+(
+    "id"                BIGSERIAL                NOT NULL,
+    "url"               TEXT                     NOT NULL,
+    "active"            BOOLEAN                  NOT NULL DEFAULT TRUE,
+    "success_count"     BIGINT                   NOT NULL DEFAULT 0,
+    "block_count"       BIGINT                   NOT NULL DEFAULT 0,
+    "failure_count"     BIGINT                   NOT NULL DEFAULT 0,
+    "last_used_at"      TIMESTAMP with time zone,
+    "quarantined_until" TIMESTAMP with time zone,
+    "created_at"        TIMESTAMP with time zone NOT NULL,
+    "updated_at"        TIMESTAMP with time zone NOT NULL,
+    PRIMARY KEY ("id"),
+    CONSTRAINT uix_proxies_url UNIQUE ("url")
+);
+
+ALTER TABLE "keyword_scrapes" ADD COLUMN "proxy" TEXT;
+
+-- +goose Down
+
+ALTER TABLE "keyword_scrapes" DROP COLUMN IF EXISTS "proxy";
+
+DROP TABLE IF EXISTS "proxies";
@@ -0,0 +1,9 @@
+-- +goose Up
+
+ALTER TABLE "keywords" ADD COLUMN "classification" TEXT;
+ALTER TABLE "keyword_scrapes" ADD COLUMN "classification" TEXT;
+
+-- +goose Down
+
+ALTER TABLE "keyword_scrapes" DROP COLUMN IF EXISTS "classification";
+ALTER TABLE "keywords" DROP COLUMN IF EXISTS "classification";
@@ -13,20 +13,46 @@ var (
 	RespJSONEncodeFailure = []byte(`{"error": "json encode failure"}`)
 	RespJSONDecodeFailure = []byte(`{"error": "json decode failure"}`)
 
-	RespHashGenerationFailure     = []byte(`{"error": "hash generation failure"}`)
-	RespJWTTokenGenerationFailure = []byte(`{"error": "jwt token generation failure"}`)
-	RespEmailSendingFailure       = []byte(`{"error": "email sending failure"}`)
+	RespHashGenerationFailure       = []byte(`{"error": "hash generation failure"}`)
+	RespJWTTokenGenerationFailure   = []byte(`{"error": "jwt token generation failure"}`)
+	RespEmailSendingFailure         = []byte(`{"error": "email sending failure"}`)
+	RespTaskEnqueueFailure          = []byte(`{"error": "task enqueue failure"}`)
+	RespEventsSubscribeFailure      = []byte(`{"error": "events subscribe failure"}`)
+	RespSecretGenerationFailure     = []byte(`{"error": "secret generation failure"}`)
+	RespTokenRevocationFailure      = []byte(`{"error": "token revocation failure"}`)
+	RespTokenRevocationCheckFailure = []byte(`{"error": "token revocation check failure"}`)
+	RespAttemptsCheckFailure        = []byte(`{"error": "attempts check failure"}`)
 
 	RespInvalidActivationRequest = []byte(`{"error": "invalid activation request"}`)
 	RespTokenExpired             = []byte(`{"error": "token expired"}`)
 	RespTokenInvalid             = []byte(`{"error": "invalid token"}`)
+	RespTokenReused              = []byte(`{"error": "token reused"}`)
 	RespUnauthorized             = []byte(`{"error": "unauthorized"}`)
 	RespFalseAuthentication      = []byte(`{"error": "false authentication"}`)
 	RespPendingActivation        = []byte(`{"error": "pending activation"}`)
+	RespTooManyAttempts          = []byte(`{"error": "too many attempts"}`)
 
 	RespInvalidID                = []byte(`{"error": "invalid ID"}`)
+	RespInvalidFilter            = []byte(`{"error": "invalid filter"}`)
+	RespInvalidInclude           = []byte(`{"error": "invalid include: must be htmlContent"}`)
 	RespInvalidFile              = []byte(`{"error": "invalid file"}`)
 	RespInvalidFileExceedMaxRows = []byte(`{"error": "invalid file: exceed maximum rows"}`)
+	RespInvalidFileFormat        = []byte(`{"error": "invalid file format: must be csv, tsv, json or xlsx"}`)
+	RespInvalidExportFormat      = []byte(`{"error": "invalid export format: must be csv, xlsx, json or ndjson"}`)
+	RespInvalidExportColumns     = []byte(`{"error": "invalid export columns"}`)
+	RespInvalidSearchEngine      = []byte(`{"error": "invalid search engine"}`)
+	RespKeywordProcessing        = []byte(`{"error": "keyword is being processed"}`)
+	RespKeywordNotCancellable    = []byte(`{"error": "keyword is already completed or cancelled"}`)
+	RespInvalidSchedule          = []byte(`{"error": "invalid schedule: must be hourly, daily, weekly or a cron expression"}`)
+	RespInvalidWebhookURL        = []byte(`{"error": "invalid webhook URL: must resolve to public addresses"}`)
+	RespWebhookInactive          = []byte(`{"error": "webhook is inactive"}`)
+	RespInsufficientRole         = []byte(`{"error": "insufficient workspace role"}`)
+	RespLastWorkspaceOwner       = []byte(`{"error": "workspace must keep an owner"}`)
+	RespPersonalWorkspaceOwner   = []byte(`{"error": "owner of a personal workspace can't be changed"}`)
+	RespInvitationEmailMismatch  = []byte(`{"error": "invitation is for another email"}`)
+	RespWorkspaceMemberExists    = []byte(`{"error": "already a workspace member"}`)
+	RespInvalidProxyURL          = []byte(`{"error": "invalid proxy URL: must be http, https or socks5 with a host"}`)
+	RespProxyExists              = []byte(`{"error": "proxy already exists"}`)
 )
 
 type Error struct {
@@ -61,3 +87,8 @@ func Conflict(w http.ResponseWriter, error []byte) {
 	w.WriteHeader(http.StatusConflict)
 	w.Write(error)
 }
+
+func TooManyRequests(w http.ResponseWriter, error []byte) {
+	w.WriteHeader(http.StatusTooManyRequests)
+	w.Write(error)
+}
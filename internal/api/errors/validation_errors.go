@@ -52,6 +52,10 @@ func ToValidationErrors(err error) *ValidationErrors {
 				resp.Errors[i] = fmt.Sprintf("%s must be at least in %v characters", err.Field(), err.Param())
 			case "email":
 				resp.Errors[i] = fmt.Sprintf("%s must be a valid email address", err.Field())
+			case "http_url":
+				resp.Errors[i] = fmt.Sprintf("%s must be a valid HTTP or HTTPS URL", err.Field())
+			case "oneof":
+				resp.Errors[i] = fmt.Sprintf("%s must be one of %s", err.Field(), err.Param())
 			default:
 				resp.Errors[i] = fmt.Sprintf("something wrong on %s; %s", err.Field(), err.Tag())
 			}
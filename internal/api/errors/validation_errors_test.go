@@ -48,6 +48,20 @@ func TestToValidationErrors(t *testing.T) {
 			}{Email: "mail@"},
 			expected: "email must be a valid email address",
 		},
+		{
+			name: "http_url",
+			input: struct {
+				URL string `json:"url" validate:"http_url"`
+			}{URL: "ftp://example.com"},
+			expected: "url must be a valid HTTP or HTTPS URL",
+		},
+		{
+			name: "oneof",
+			input: struct {
+				Events []string `json:"events" validate:"dive,oneof=keyword.completed keyword.failed"`
+			}{Events: []string{"keyword.created"}},
+			expected: "events[0] must be one of keyword.completed keyword.failed",
+		},
 	}
 
 	for _, tc := range tests {
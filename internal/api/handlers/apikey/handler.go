@@ -0,0 +1,294 @@
+package apikey
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	v "github.com/go-playground/validator/v10"
+	"gorm.io/gorm"
+
+	e "web-scraper.dev/internal/api/errors"
+	"web-scraper.dev/internal/apikeys"
+	"web-scraper.dev/internal/model"
+	"web-scraper.dev/internal/repository"
+	"web-scraper.dev/internal/utils/ctxutil"
+	l "web-scraper.dev/internal/utils/logger"
+	"web-scraper.dev/internal/utils/pageutil"
+)
+
+type API struct {
+	db        *repository.Db
+	logger    *l.Logger
+	validator *v.Validate
+}
+
+func New(db *gorm.DB, logger *l.Logger, validator *v.Validate) *API {
+	return &API{
+		db:        repository.New(db),
+		logger:    logger,
+		validator: validator,
+	}
+}
+
+// GetAPIKeys godoc
+// @summary Get the list of API keys
+// @description Get a page of API keys of current user with their prefixes and last uses, latest first
+// @tags api-keys
+//
+// @router /users/me/api-keys [GET]
+// @accept json
+// @produce json
+// @security BearerToken
+// @param page query int false "Page number, starts from 1"
+// @param per_page query int false "Number of API keys per page, max 100"
+//
+// @success 200 {array} model.APIKeyDTO
+// @header 200 {integer} X-Total-Count "Total number of API keys"
+// @failure 401 {object} e.Error
+// @failure 403 {object} e.Error
+// @failure 500 {object} e.Error
+func (a *API) GetAPIKeys(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	reqID, ctxUser := ctxutil.RequestID(ctx), ctxutil.UserFromCtx(ctx)
+
+	page := pageutil.FromRequest(r)
+	keys, total, err := a.db.ListAPIKeysByUserId(*ctxUser.ID, page.Offset(), page.Limit())
+	if err != nil {
+		a.logger.Error().Str(l.KeyReqID, reqID).Err(err).Msg("")
+		e.ServerError(w, e.RespDBDataAccessFailure)
+		return
+	}
+
+	pageutil.SetTotalCount(w, total)
+
+	dto := keys.ToDTOs()
+	if err := json.NewEncoder(w).Encode(&dto); err != nil {
+		a.logger.Error().Str(l.KeyReqID, reqID).Err(err).Msg("")
+		e.ServerError(w, e.RespJSONEncodeFailure)
+		return
+	}
+}
+
+// CreateAPIKey godoc
+// @summary Create an API key
+// @description Create a long-lived API key for scripts to use in an "Authorization: ApiKey <key>" or an "X-API-Key: <key>" header.
+// @description The read scope allows reading the keywords and the uploads; the upload scope allows uploading, rescraping, cancelling and scheduling the keywords too.
+// @description The response has the key, which is not shown again.
+// @tags api-keys
+//
+// @router /users/me/api-keys [POST]
+// @accept json
+// @produce json
+// @security BearerToken
+// @param body body FormAPIKey true "API key form"
+//
+// @success 201 {object} model.APIKeyDTO
+// @failure 400 {object} e.Error
+// @failure 400 {object} e.ValidationErrors
+// @failure 401 {object} e.Error
+// @failure 403 {object} e.Error
+// @failure 500 {object} e.Error
+func (a *API) CreateAPIKey(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	reqID, ctxUser := ctxutil.RequestID(ctx), ctxutil.UserFromCtx(ctx)
+
+	form := &FormAPIKey{}
+	if e.JSONBindAndValidateErrorHandled(w, r, a.logger, a.validator, form, reqID) {
+		return
+	}
+
+	key, prefix, err := apikeys.New()
+	if err != nil {
+		a.logger.Error().Str(l.KeyReqID, reqID).Err(err).Msg("")
+		e.ServerError(w, e.RespSecretGenerationFailure)
+		return
+	}
+
+	apiKey := &model.APIKey{
+		UserID:  *ctxUser.ID,
+		Name:    form.Name,
+		Prefix:  prefix,
+		KeyHash: apikeys.Hash(key),
+		Scope:   form.Scope,
+	}
+
+	if err := a.db.CreateAPIKey(apiKey); err != nil {
+		a.logger.Error().Str(l.KeyReqID, reqID).Err(err).Msg("")
+		e.ServerError(w, e.RespDBDataInsertFailure)
+		return
+	}
+
+	dto := apiKey.ToDTO()
+	dto.Key = key
+
+	w.WriteHeader(http.StatusCreated)
+	if err := json.NewEncoder(w).Encode(dto); err != nil {
+		a.logger.Error().Str(l.KeyReqID, reqID).Err(err).Msg("")
+		return
+	}
+}
+
+// GetAPIKey godoc
+// @summary Get an API key
+// @description Get an API key of current user, without the key itself
+// @tags api-keys
+//
+// @router /users/me/api-keys/{id} [GET]
+// @accept json
+// @produce json
+// @security BearerToken
+// @param id path string true "API key ID"
+//
+// @success 200 {object} model.APIKeyDTO
+// @failure 400 {object} e.Error
+// @failure 401 {object} e.Error
+// @failure 403 {object} e.Error
+// @failure 404
+// @failure 500 {object} e.Error
+func (a *API) GetAPIKey(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	reqID, ctxUser := ctxutil.RequestID(ctx), ctxutil.UserFromCtx(ctx)
+
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil || id < 1 {
+		e.BadRequest(w, e.RespInvalidID)
+		return
+	}
+
+	apiKey, err := a.db.ReadAPIKeyByIdAndUserId(int64(id), *ctxUser.ID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		a.logger.Error().Str(l.KeyReqID, reqID).Err(err).Msg("")
+		e.ServerError(w, e.RespDBDataAccessFailure)
+		return
+	}
+
+	if err := json.NewEncoder(w).Encode(apiKey.ToDTO()); err != nil {
+		a.logger.Error().Str(l.KeyReqID, reqID).Err(err).Msg("")
+		e.ServerError(w, e.RespJSONEncodeFailure)
+		return
+	}
+}
+
+// UpdateAPIKey godoc
+// @summary Update an API key
+// @description Rename an API key of current user or change its scope; the key itself is kept
+// @tags api-keys
+//
+// @router /users/me/api-keys/{id} [PUT]
+// @accept json
+// @produce json
+// @security BearerToken
+// @param id path string true "API key ID"
+// @param body body FormAPIKey true "API key form"
+//
+// @success 200 {object} model.APIKeyDTO
+// @failure 400 {object} e.Error
+// @failure 400 {object} e.ValidationErrors
+// @failure 401 {object} e.Error
+// @failure 403 {object} e.Error
+// @failure 404
+// @failure 500 {object} e.Error
+func (a *API) UpdateAPIKey(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	reqID, ctxUser := ctxutil.RequestID(ctx), ctxutil.UserFromCtx(ctx)
+
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil || id < 1 {
+		e.BadRequest(w, e.RespInvalidID)
+		return
+	}
+
+	form := &FormAPIKey{}
+	if e.JSONBindAndValidateErrorHandled(w, r, a.logger, a.validator, form, reqID) {
+		return
+	}
+
+	updates := map[string]any{
+		"name":  form.Name,
+		"scope": form.Scope,
+	}
+
+	rowsAffected, err := a.db.UpdateAPIKeyByIdAndUserId(int64(id), *ctxUser.ID, updates)
+	if err != nil {
+		a.logger.Error().Str(l.KeyReqID, reqID).Err(err).Msg("")
+		e.ServerError(w, e.RespDBDataUpdateFailure)
+		return
+	}
+
+	if rowsAffected == 0 {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	apiKey, err := a.db.ReadAPIKeyByIdAndUserId(int64(id), *ctxUser.ID)
+	if err != nil {
+		a.logger.Error().Str(l.KeyReqID, reqID).Err(err).Msg("")
+		e.ServerError(w, e.RespDBDataAccessFailure)
+		return
+	}
+
+	if err := json.NewEncoder(w).Encode(apiKey.ToDTO()); err != nil {
+		a.logger.Error().Str(l.KeyReqID, reqID).Err(err).Msg("")
+		e.ServerError(w, e.RespJSONEncodeFailure)
+		return
+	}
+}
+
+// RevokeAPIKey godoc
+// @summary Revoke an API key
+// @description Revoke an API key of current user; the requests with it are rejected right away. It stays in the list with its last use.
+// @tags api-keys
+//
+// @router /users/me/api-keys/{id} [DELETE]
+// @accept json
+// @produce json
+// @security BearerToken
+// @param id path string true "API key ID"
+//
+// @success 204
+// @failure 400 {object} e.Error
+// @failure 401 {object} e.Error
+// @failure 403 {object} e.Error
+// @failure 404
+// @failure 500 {object} e.Error
+func (a *API) RevokeAPIKey(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	reqID, ctxUser := ctxutil.RequestID(ctx), ctxutil.UserFromCtx(ctx)
+
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil || id < 1 {
+		e.BadRequest(w, e.RespInvalidID)
+		return
+	}
+
+	rowsAffected, err := a.db.RevokeAPIKeyByIdAndUserId(int64(id), *ctxUser.ID)
+	if err != nil {
+		a.logger.Error().Str(l.KeyReqID, reqID).Err(err).Msg("")
+		e.ServerError(w, e.RespDBDataUpdateFailure)
+		return
+	}
+
+	// Revoking a revoked key again is not an error
+	if rowsAffected == 0 {
+		if _, err := a.db.ReadAPIKeyByIdAndUserId(int64(id), *ctxUser.ID); err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+
+			a.logger.Error().Str(l.KeyReqID, reqID).Err(err).Msg("")
+			e.ServerError(w, e.RespDBDataAccessFailure)
+			return
+		}
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,6 @@
+package apikey
+
+type FormAPIKey struct {
+	Name  string `json:"name" validate:"required,max=100"`
+	Scope string `json:"scope" validate:"required,oneof=read upload"`
+}
@@ -1,75 +1,191 @@
 package keyword
 
 import (
-	"encoding/csv"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
+	"mime"
 	"net/http"
+	"net/url"
+	"path/filepath"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
+	"unicode"
+	"unicode/utf8"
 
 	"github.com/go-chi/chi/v5"
 	v "github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
 	"github.com/hibiken/asynq"
 	"gorm.io/gorm"
 
 	e "web-scraper.dev/internal/api/errors"
+	"web-scraper.dev/internal/events"
+	"web-scraper.dev/internal/export"
+	"web-scraper.dev/internal/fairqueue"
+	"web-scraper.dev/internal/keywordfile"
 	"web-scraper.dev/internal/model"
 	"web-scraper.dev/internal/repository"
+	"web-scraper.dev/internal/scheduler"
+	"web-scraper.dev/internal/searchengine"
 	"web-scraper.dev/internal/tasks"
 	"web-scraper.dev/internal/utils/ctxutil"
+	"web-scraper.dev/internal/utils/htmlutil"
 	l "web-scraper.dev/internal/utils/logger"
+	"web-scraper.dev/internal/utils/pageutil"
 )
 
-const SearchEngineBing = "bing"
+const (
+	formKeyFile         = "file"
+	formKeySearchEngine = "searchEngine"
+	formKeySchedule     = "schedule"
+
+	queryKeyRunID = "runId"
+
+	queryKeyStatus       = "status"
+	queryKeySearchEngine = "search_engine"
+	queryKeyUploadID     = "upload_id"
+	queryKeyCreatedFrom  = "created_from"
+	queryKeyCreatedTo    = "created_to"
+	queryKeyQ            = "q"
+	queryKeySort         = "sort"
+	queryKeyInclude      = "include"
+
+	includeHTMLContent = "htmlContent"
+
+	dateLayout = "2006-01-02"
+
+	// Keywords re-scraped by status at most per request, and by batch
+	rescrapeMaxKeywords = 1000
+	rescrapeBatchSize   = 100
+
+	uploadMaxSize          = 32 << 20
+	uploadMaxKeywords      = 1000
+	uploadKeywordMaxLength = 255
+
+	uploadRowStatusCreated   = "created"
+	uploadRowStatusDuplicate = "duplicate"
+	uploadRowStatusInvalid   = "invalid"
+
+	uploadRowReasonEmpty               = "empty"
+	uploadRowReasonTooLong             = "too long"
+	uploadRowReasonControlChars        = "control characters"
+	uploadRowReasonInvalidEncoding     = "invalid encoding"
+	uploadRowReasonInvalidSearchEngine = "invalid search engine"
+	uploadRowReasonInvalidMarket       = "invalid market"
+	uploadRowReasonInvalidDevice       = "invalid device"
+	uploadRowReasonInvalidSchedule     = "invalid schedule"
+	uploadRowReasonDuplicateInFile     = "duplicate in file"
+	uploadRowReasonAlreadyExists       = "already exists"
+
+	// Comments sent on idle event streams, to keep the proxies and the write deadline from closing them
+	eventsHeartbeatInterval = 15 * time.Second
+	eventsRetry             = 3 * time.Second
+
+	// Scripts, forms, plugins and same origin access are blocked; the page can only load its images, styles and fonts
+	htmlContentSecurityPolicy = "sandbox; default-src 'none'; img-src https: data:; style-src https: 'unsafe-inline'; font-src https: data:"
+)
+
+var (
+	keywordStatuses = map[string]bool{
+		model.KeywordStatusPending:    true,
+		model.KeywordStatusProcessing: true,
+		model.KeywordStatusCompleted:  true,
+		model.KeywordStatusFailed:     true,
+		model.KeywordStatusCancelled:  true,
+	}
+
+	keywordsSortColumns = map[string]bool{
+		"ad_count":   true,
+		"link_count": true,
+		"created_at": true,
+	}
+)
 
 type API struct {
 	db        *repository.Db
 	logger    *l.Logger
 	validator *v.Validate
 	asyq      *asynq.Client
+	inspector *asynq.Inspector
+	events    *events.Broker
+	fair      *fairqueue.Queue
 }
 
-func New(db *gorm.DB, logger *l.Logger, validator *v.Validate, asyq *asynq.Client) *API {
+func New(db *gorm.DB, logger *l.Logger, validator *v.Validate, asyq *asynq.Client, inspector *asynq.Inspector, broker *events.Broker, fair *fairqueue.Queue) *API {
 	return &API{
 		db:        repository.New(db),
 		logger:    logger,
 		validator: validator,
 		asyq:      asyq,
+		inspector: inspector,
+		events:    broker,
+		fair:      fair,
 	}
 }
 
 // GetKeywords godoc
 // @summary Get the list of keywords
-// @description Get the list of keywords uploaded by current user
+// @description Get a page of keywords of current workspace, without their HTML content unless it is included
 // @tags keywords
 //
 // @router /keywords [GET]
 // @accept json
 // @produce json
 // @security BearerToken
+// @param page query int false "Page number, starts from 1"
+// @param per_page query int false "Number of keywords per page, max 100"
+// @param status query string false "Filter by status" Enums(pending, processing, completed, failed, cancelled)
+// @param search_engine query string false "Filter by search engine" Enums(bing, google, duckduckgo)
+// @param upload_id query int false "Filter by upload ID"
+// @param created_from query string false "Filter by created date from, inclusive; RFC 3339 or YYYY-MM-DD"
+// @param created_to query string false "Filter by created date to, exclusive; RFC 3339 or YYYY-MM-DD (inclusive)"
+// @param q query string false "Search in keyword text"
+// @param sort query string false "Sort by ad_count, link_count or created_at; prefix with - for descending order" default(-created_at)
+// @param include query string false "Comma separated optional fields to include" Enums(htmlContent)
+//
 // @success 200 {array} model.KeywordDTO
+// @header 200 {integer} X-Total-Count "Total number of keywords matching the filters"
+// @failure 400 {object} e.Error
 // @failure 401 {object} e.Error
 // @failure 500 {object} e.Error
 func (a *API) GetKeywords(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
-	reqID, ctxUser := ctxutil.RequestID(ctx), ctxutil.UserFromCtx(ctx)
+	reqID, ctxWorkspace := ctxutil.RequestID(ctx), ctxutil.WorkspaceFromCtx(ctx)
+
+	filter, ok := keywordsFilterFromQuery(r.URL.Query())
+	if !ok {
+		e.BadRequest(w, e.RespInvalidFilter)
+		return
+	}
+
+	filter.WithHTMLContent, ok = includesHTMLContent(r.URL.Query())
+	if !ok {
+		e.BadRequest(w, e.RespInvalidInclude)
+		return
+	}
 
-	keywords, err := a.db.ListKeywordsByUserId(*ctxUser.ID)
+	page := pageutil.FromRequest(r)
+	keywords, total, err := a.db.ListKeywordsByWorkspaceId(ctxWorkspace.ID, filter, page.Offset(), page.Limit())
 	if err != nil {
 		a.logger.Error().Str(l.KeyReqID, reqID).Err(err).Msg("")
 		e.ServerError(w, e.RespDBDataAccessFailure)
 		return
 	}
 
-	if keywords == nil {
-		fmt.Fprint(w, "[]")
-		return
-	}
+	pageutil.SetTotalCount(w, total)
 
 	dto := keywords.ToDTOs()
+	if filter.WithHTMLContent {
+		for i, v := range keywords {
+			dto[i].HTMLContent = v.HTMLContent
+		}
+	}
+	a.setQueuePositions(ctx, reqID, ctxWorkspace.ID, dto...)
 	if err := json.NewEncoder(w).Encode(&dto); err != nil {
 		a.logger.Error().Str(l.KeyReqID, reqID).Err(err).Msg("")
 		e.ServerError(w, e.RespJSONEncodeFailure)
@@ -77,16 +193,139 @@ func (a *API) GetKeywords(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// ExportKeywords godoc
+// @summary Export keywords
+// @description Export the keywords of current workspace as a file, streamed from the database. The results column adds the organic results and ads of the latest runs.
+// @tags keywords
+//
+// @router /keywords/export [GET]
+// @produce text/csv
+// @produce application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @produce json
+// @produce application/x-ndjson
+// @security BearerToken
+// @param format query string false "Export format" Enums(csv, xlsx, json, ndjson) default(csv)
+// @param columns query string false "Comma separated columns; id, keyword, search_engine, market, device, tags, status, ad_count, link_count, error, scraped_at and results. Defaults to keyword, search_engine, status, ad_count, link_count, error and scraped_at"
+// @param status query string false "Filter by status" Enums(pending, processing, completed, failed, cancelled)
+// @param search_engine query string false "Filter by search engine" Enums(bing, google, duckduckgo)
+// @param upload_id query int false "Filter by upload ID"
+// @param created_from query string false "Filter by created date from, inclusive; RFC 3339 or YYYY-MM-DD"
+// @param created_to query string false "Filter by created date to, exclusive; RFC 3339 or YYYY-MM-DD (inclusive)"
+// @param q query string false "Search in keyword text"
+// @param sort query string false "Sort by ad_count, link_count or created_at; prefix with - for descending order" default(-created_at)
+//
+// @success 200 {file} file
+// @failure 400 {object} e.Error
+// @failure 401 {object} e.Error
+// @failure 500 {object} e.Error
+func (a *API) ExportKeywords(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	reqID, ctxWorkspace := ctxutil.RequestID(ctx), ctxutil.WorkspaceFromCtx(ctx)
+
+	filter, ok := keywordsFilterFromQuery(r.URL.Query())
+	if !ok {
+		e.BadRequest(w, e.RespInvalidFilter)
+		return
+	}
+
+	opts, err := export.OptionsFromQuery(r.URL.Query())
+	if err != nil {
+		if errors.Is(err, export.ErrInvalidFormat) {
+			e.BadRequest(w, e.RespInvalidExportFormat)
+			return
+		}
+
+		e.BadRequest(w, e.RespInvalidExportColumns)
+		return
+	}
+
+	filename := "keywords-" + time.Now().Format("20060102-150405")
+	started, err := export.Keywords(w, repository.New(a.db.WithContext(ctx)), ctxWorkspace.ID, filter, opts, filename)
+	if err != nil {
+		a.logger.Error().Str(l.KeyReqID, reqID).Err(err).Msg("")
+		if !started {
+			e.ServerError(w, e.RespDBDataAccessFailure)
+		}
+		return
+	}
+}
+
+// GetKeywordEvents godoc
+// @summary Stream keyword status changes
+// @description Stream the status and result changes of the keywords of current workspace as server-sent events.
+// @description Each "keyword" event has the keyword ID, its status and the changed fields. Idle streams get a comment every 15 seconds.
+// @tags keywords
+//
+// @router /keywords/events [GET]
+// @produce text/event-stream
+// @security BearerToken
+//
+// @success 200 {object} events.Keyword
+// @failure 401 {object} e.Error
+// @failure 500 {object} e.Error
+func (a *API) GetKeywordEvents(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	reqID, ctxWorkspace := ctxutil.RequestID(ctx), ctxutil.WorkspaceFromCtx(ctx)
+
+	sub, err := a.events.Subscribe(ctx, ctxWorkspace.ID)
+	if err != nil {
+		a.logger.Error().Str(l.KeyReqID, reqID).Err(err).Msg("")
+		e.ServerError(w, e.RespEventsSubscribeFailure)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no")
+
+	rc := http.NewResponseController(w)
+	if _, err := fmt.Fprintf(w, "retry: %d\n\n", eventsRetry.Milliseconds()); err != nil {
+		return
+	}
+	if err := rc.Flush(); err != nil {
+		a.logger.Error().Str(l.KeyReqID, reqID).Err(err).Msg("")
+		return
+	}
+
+	heartbeat := time.NewTicker(eventsHeartbeatInterval)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-heartbeat.C:
+			if _, err := io.WriteString(w, ": heartbeat\n\n"); err != nil {
+				return
+			}
+		case event, ok := <-sub.C:
+			// The broker is closed on shutdown
+			if !ok {
+				return
+			}
+
+			if _, err := fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event.Type, event.Data); err != nil {
+				return
+			}
+		}
+
+		if err := rc.Flush(); err != nil {
+			return
+		}
+	}
+}
+
 // GetKeyword godoc
 // @summary Get the result of a keyword
-// @description Get the result of a keyword uploaded by current user
+// @description Get the result of a keyword of current workspace, without its HTML content unless it is included
 // @tags keywords
 //
 // @router /keywords/{id} [GET]
 // @accept json
 // @produce json
 // @security BearerToken
-// @param id path string true "Keyword ID in uuid format"
+// @param id path string true "Keyword ID"
+// @param include query string false "Comma separated optional fields to include" Enums(htmlContent)
 //
 // @success 200 {object} model.KeywordDTO
 // @failure 400 {object} e.Error
@@ -95,7 +334,7 @@ func (a *API) GetKeywords(w http.ResponseWriter, r *http.Request) {
 // @failure 500 {object} e.Error
 func (a *API) GetKeyword(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
-	reqID, ctxUser := ctxutil.RequestID(ctx), ctxutil.UserFromCtx(ctx)
+	reqID, ctxWorkspace := ctxutil.RequestID(ctx), ctxutil.WorkspaceFromCtx(ctx)
 
 	id, err := strconv.Atoi(chi.URLParam(r, "id"))
 	if err != nil || id < 1 {
@@ -103,7 +342,13 @@ func (a *API) GetKeyword(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	keyword, err := a.db.ReadKeywordByIdAndUserId(int64(id), *ctxUser.ID)
+	withHTMLContent, ok := includesHTMLContent(r.URL.Query())
+	if !ok {
+		e.BadRequest(w, e.RespInvalidInclude)
+		return
+	}
+
+	keyword, err := a.db.ReadKeywordByIdAndWorkspaceId(int64(id), ctxWorkspace.ID)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			w.WriteHeader(http.StatusNotFound)
@@ -115,95 +360,1041 @@ func (a *API) GetKeyword(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := json.NewEncoder(w).Encode(&keyword); err != nil {
+	dto := keyword.ToDTO()
+	if withHTMLContent {
+		dto.HTMLContent = keyword.HTMLContent
+	}
+	a.setQueuePositions(ctx, reqID, ctxWorkspace.ID, dto)
+
+	if err := json.NewEncoder(w).Encode(dto); err != nil {
 		a.logger.Error().Str(l.KeyReqID, reqID).Err(err).Msg("")
 		e.ServerError(w, e.RespJSONEncodeFailure)
 		return
 	}
 }
 
-// UploadKeywords godoc
-// @summary Upload the keywords CSV file
-// @description Upload the keywords CSV file to scrape on web
+// GetKeywordHTML godoc
+// @summary Get the HTML content of a keyword
+// @description Get the cached search results page of a keyword of current workspace, with scripts stripped.
+// @description It is served in a sandbox to be embedded safely in an iframe.
 // @tags keywords
 //
-// @router /keywords [POST]
-// @Accept multipart/form-data
-// @Accept text/csv
+// @router /keywords/{id}/html [GET]
+// @produce html
+// @security BearerToken
+// @param id path string true "Keyword ID"
+//
+// @success 200 {string} string
+// @failure 400 {object} e.Error
+// @failure 401 {object} e.Error
+// @failure 404
+// @failure 500 {object} e.Error
+func (a *API) GetKeywordHTML(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	reqID, ctxWorkspace := ctxutil.RequestID(ctx), ctxutil.WorkspaceFromCtx(ctx)
+
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil || id < 1 {
+		e.BadRequest(w, e.RespInvalidID)
+		return
+	}
+
+	htmlContent, err := a.db.ReadKeywordHTMLContentByIdAndWorkspaceId(int64(id), ctxWorkspace.ID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		a.logger.Error().Str(l.KeyReqID, reqID).Err(err).Msg("")
+		e.ServerError(w, e.RespDBDataAccessFailure)
+		return
+	}
+
+	if htmlContent == nil {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Content-Security-Policy", htmlContentSecurityPolicy)
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+
+	if err := htmlutil.StripScripts(w, strings.NewReader(*htmlContent)); err != nil {
+		a.logger.Error().Str(l.KeyReqID, reqID).Err(err).Msg("")
+		return
+	}
+}
+
+// GetKeywordResults godoc
+// @summary Get the structured results of a keyword
+// @description Get the organic results and ads extracted from the search results page of a keyword of current workspace
+// @tags keywords
+//
+// @router /keywords/{id}/results [GET]
+// @accept json
 // @produce json
 // @security BearerToken
-// @Param file formData file true "CSV file with keywords"
+// @param id path string true "Keyword ID"
+// @param runId query int false "Run ID, defaults to the latest run"
 //
-// @success 202
+// @success 200 {object} model.KeywordResultsDTO
 // @failure 400 {object} e.Error
 // @failure 401 {object} e.Error
+// @failure 404
 // @failure 500 {object} e.Error
-func (a *API) UploadKeywords(w http.ResponseWriter, r *http.Request) {
+func (a *API) GetKeywordResults(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	reqID, ctxWorkspace := ctxutil.RequestID(ctx), ctxutil.WorkspaceFromCtx(ctx)
+
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil || id < 1 {
+		e.BadRequest(w, e.RespInvalidID)
+		return
+	}
+
+	keyword, err := a.db.ReadKeywordByIdAndWorkspaceId(int64(id), ctxWorkspace.ID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		a.logger.Error().Str(l.KeyReqID, reqID).Err(err).Msg("")
+		e.ServerError(w, e.RespDBDataAccessFailure)
+		return
+	}
+
+	dto := &model.KeywordResultsDTO{
+		KeywordID:      keyword.ID,
+		RunID:          keyword.LastScrapeID,
+		OrganicResults: make([]*model.SerpResultDTO, 0),
+		Ads:            make([]*model.SerpAdDTO, 0),
+	}
+
+	if v := r.URL.Query().Get(queryKeyRunID); v != "" {
+		runID, err := strconv.Atoi(v)
+		if err != nil || runID < 1 {
+			e.BadRequest(w, e.RespInvalidID)
+			return
+		}
+
+		keywordScrape, err := a.db.ReadKeywordScrapeByIdAndKeywordId(int64(runID), keyword.ID)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+
+			a.logger.Error().Str(l.KeyReqID, reqID).Err(err).Msg("")
+			e.ServerError(w, e.RespDBDataAccessFailure)
+			return
+		}
+
+		dto.RunID = &keywordScrape.ID
+	}
+
+	if dto.RunID != nil {
+		results, err := a.db.ListSerpResultsByKeywordScrapeId(*dto.RunID)
+		if err != nil {
+			a.logger.Error().Str(l.KeyReqID, reqID).Err(err).Msg("")
+			e.ServerError(w, e.RespDBDataAccessFailure)
+			return
+		}
+
+		ads, err := a.db.ListSerpAdsByKeywordScrapeId(*dto.RunID)
+		if err != nil {
+			a.logger.Error().Str(l.KeyReqID, reqID).Err(err).Msg("")
+			e.ServerError(w, e.RespDBDataAccessFailure)
+			return
+		}
+
+		dto.OrganicResults, dto.Ads = results.ToDTOs(), ads.ToDTOs()
+	}
+
+	if err := json.NewEncoder(w).Encode(dto); err != nil {
+		a.logger.Error().Str(l.KeyReqID, reqID).Err(err).Msg("")
+		e.ServerError(w, e.RespJSONEncodeFailure)
+		return
+	}
+}
+
+// GetKeywordRuns godoc
+// @summary Get the scrape runs of a keyword
+// @description Get the history of scrape runs of a keyword of current workspace, latest first
+// @tags keywords
+//
+// @router /keywords/{id}/runs [GET]
+// @accept json
+// @produce json
+// @security BearerToken
+// @param id path string true "Keyword ID"
+// @param page query int false "Page number, starts from 1"
+// @param per_page query int false "Number of runs per page, max 100"
+//
+// @success 200 {array} model.KeywordScrapeDTO
+// @header 200 {integer} X-Total-Count "Total number of runs"
+// @failure 400 {object} e.Error
+// @failure 401 {object} e.Error
+// @failure 404
+// @failure 500 {object} e.Error
+func (a *API) GetKeywordRuns(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
-	reqID, ctxUser := ctxutil.RequestID(ctx), ctxutil.UserFromCtx(ctx)
+	reqID, ctxWorkspace := ctxutil.RequestID(ctx), ctxutil.WorkspaceFromCtx(ctx)
+
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil || id < 1 {
+		e.BadRequest(w, e.RespInvalidID)
+		return
+	}
+
+	keyword, err := a.db.ReadKeywordByIdAndWorkspaceId(int64(id), ctxWorkspace.ID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
 
-	if err := r.ParseMultipartForm(32 << 20); err != nil {
 		a.logger.Error().Str(l.KeyReqID, reqID).Err(err).Msg("")
-		e.BadRequest(w, e.RespInvalidFile)
+		e.ServerError(w, e.RespDBDataAccessFailure)
 		return
 	}
 
-	file, _, err := r.FormFile("file")
+	page := pageutil.FromRequest(r)
+	keywordScrapes, total, err := a.db.ListKeywordScrapesByKeywordId(keyword.ID, page.Offset(), page.Limit())
 	if err != nil {
-		e.BadRequest(w, e.RespInvalidFile)
+		a.logger.Error().Str(l.KeyReqID, reqID).Err(err).Msg("")
+		e.ServerError(w, e.RespDBDataAccessFailure)
 		return
 	}
-	defer file.Close()
 
-	reader := csv.NewReader(file)
-	records, err := reader.ReadAll()
-	if err != nil || len(records) == 0 {
-		e.BadRequest(w, e.RespInvalidFile)
+	pageutil.SetTotalCount(w, total)
+
+	dto := keywordScrapes.ToDTOs()
+	if err := json.NewEncoder(w).Encode(&dto); err != nil {
+		a.logger.Error().Str(l.KeyReqID, reqID).Err(err).Msg("")
+		e.ServerError(w, e.RespJSONEncodeFailure)
 		return
 	}
+}
+
+// UploadKeywords godoc
+// @summary Upload the keywords file
+// @description Upload a CSV, TSV, JSON or XLSX keywords file to scrape on web, as a multipart form file or the raw request body.
+// @description A header row may name the keyword, search_engine, market, device, tags and schedule columns; otherwise the first column is the keyword.
+// @description JSON bodies are arrays of keywords or of objects with keyword, searchEngine, market, device, tags and schedule fields.
+// @description Keywords which already exist or repeat in the file are skipped, and the response reports the outcome of each row by line number.
+// @tags keywords
+//
+// @router /keywords [POST]
+// @Accept multipart/form-data
+// @Accept text/csv
+// @Accept text/tab-separated-values
+// @Accept application/json
+// @Accept application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @produce json
+// @security BearerToken
+// @Param file formData file false "Keywords file; .csv, .tsv, .json or .xlsx"
+// @Param searchEngine formData []string false "Search engines to scrape on; bing (default), google, duckduckgo. In the query for raw bodies" collectionFormat(multi)
+// @Param schedule formData string false "Recurring scrape schedule; hourly, daily, weekly or a cron expression. In the query for raw bodies"
+//
+// @success 202 {object} RespUpload
+// @failure 400 {object} e.Error
+// @failure 401 {object} e.Error
+// @failure 500 {object} e.Error
+func (a *API) UploadKeywords(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	reqID, ctxUser, ctxWorkspace := ctxutil.RequestID(ctx), ctxutil.UserFromCtx(ctx), ctxutil.WorkspaceFromCtx(ctx)
+
+	var file io.Reader
+	var filename, format string
+	if mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); mediaType == "multipart/form-data" {
+		if err := r.ParseMultipartForm(uploadMaxSize); err != nil {
+			a.logger.Error().Str(l.KeyReqID, reqID).Err(err).Msg("")
+			e.BadRequest(w, e.RespInvalidFile)
+			return
+		}
+
+		formFile, fileHeader, err := r.FormFile(formKeyFile)
+		if err != nil {
+			e.BadRequest(w, e.RespInvalidFile)
+			return
+		}
+		defer formFile.Close()
 
-	var keywords []string
+		file, filename, format = formFile, filepath.Base(fileHeader.Filename), keywordfile.FormatFromFilename(fileHeader.Filename)
+	} else {
+		var ok bool
+		if format, ok = keywordfile.FormatFromContentType(r.Header.Get("Content-Type")); !ok {
+			e.BadRequest(w, e.RespInvalidFileFormat)
+			return
+		}
 
-	for _, record := range records {
-		if len(record) > 0 && strings.TrimSpace(record[0]) != "" {
-			keywords = append(keywords, strings.TrimSpace(record[0]))
+		// Options of raw bodies are given in the query
+		if err := r.ParseForm(); err != nil {
+			e.BadRequest(w, e.RespInvalidFile)
+			return
 		}
 
-		if len(keywords) > 100 {
-			e.BadRequest(w, e.RespInvalidFileExceedMaxRows)
+		file = http.MaxBytesReader(w, r.Body, uploadMaxSize)
+	}
+
+	searchEngines, ok := searchEnginesFromForm(r.Form[formKeySearchEngine])
+	if !ok {
+		e.BadRequest(w, e.RespInvalidSearchEngine)
+		return
+	}
+
+	var schedule *string
+	var nextScrapeAt *time.Time
+	if v := r.FormValue(formKeySchedule); v != "" {
+		normalized, next, err := normalizeSchedule(v)
+		if err != nil {
+			e.BadRequest(w, e.RespInvalidSchedule)
 			return
 		}
+		schedule, nextScrapeAt = &normalized, next
 	}
 
-	if len(keywords) == 0 {
-		e.BadRequest(w, e.RespInvalidFile)
+	records, err := keywordfile.Read(file, format)
+	if err != nil {
+		var lineErr *keywordfile.LineError
+		if !errors.As(err, &lineErr) {
+			e.BadRequest(w, e.RespInvalidFile)
+			return
+		}
+
+		respBody, err := json.Marshal(&e.ValidationErrors{Errors: []string{lineErr.Error()}})
+		if err != nil {
+			a.logger.Error().Str(l.KeyReqID, reqID).Err(err).Msg("")
+			e.ServerError(w, e.RespJSONEncodeFailure)
+			return
+		}
+
+		e.BadRequest(w, respBody)
 		return
 	}
 
-	userID := *ctxUser.ID
-	keywordModels := make([]*model.Keyword, len(keywords))
-	for i, v := range keywords {
-		keywordModels[i] = &model.Keyword{
-			UserID:       userID,
-			Keyword:      v,
-			Status:       "pending",
-			SearchEngine: SearchEngineBing,
+	rows := make([]*uploadRow, len(records))
+	var keywordCount int
+	for i, v := range records {
+		rows[i] = newUploadRow(v, searchEngines, schedule, nextScrapeAt)
+		if rows[i].reason == "" {
+			keywordCount++
 		}
 	}
 
-	if err := a.db.Create(&keywordModels).Error; err != nil {
+	if keywordCount > uploadMaxKeywords {
+		e.BadRequest(w, e.RespInvalidFileExceedMaxRows)
+		return
+	}
+
+	resp := &RespUpload{CreatedIDs: make([]int64, 0), Rows: make([]*RespUploadRow, 0, len(rows))}
+	createdKeywords := make(model.Keywords, 0)
+
+	userID, workspaceID := *ctxUser.ID, ctxWorkspace.ID
+	upload := &model.Upload{
+		UserID:      userID,
+		WorkspaceID: workspaceID,
+		Filename:    filename,
+		RowCount:    len(rows),
+	}
+
+	tx := a.db.TxBegin()
+	if err := tx.CreateUpload(upload); err != nil {
+		tx.Rollback()
 		a.logger.Error().Str(l.KeyReqID, reqID).Err(err).Msg("")
 		e.ServerError(w, e.RespDBDataInsertFailure)
 		return
 	}
+	resp.UploadID = upload.ID
 
-	for _, v := range keywordModels {
-		task := tasks.NewScrapeKeywordTask(v.ID)
-		// Enqueue with a delay to avoid rate limiting
-		if _, err := a.asyq.Enqueue(task, asynq.ProcessIn(tasks.ScrapeKeywordDelayInSeconds*time.Second)); err != nil {
-			a.logger.Error().Str(l.KeyReqID, reqID).Err(err).Str("task", "scrape-keyword").Msg("")
+	seen := make(map[string]bool)
+	for _, row := range rows {
+		if row.reason != "" {
+			resp.Invalid++
+			resp.Rows = append(resp.Rows, &RespUploadRow{Line: row.Line, Keyword: row.Keyword, Status: uploadRowStatusInvalid, Reason: row.reason})
+			continue
 		}
-	}
 
-	w.WriteHeader(http.StatusAccepted)
+		for _, se := range row.searchEngines {
+			result := &RespUploadRow{Line: row.Line, Keyword: row.Keyword, SearchEngine: se, Market: row.market, Device: row.device}
+			resp.Rows = append(resp.Rows, result)
+
+			key := strings.Join([]string{row.Keyword, se, row.market, row.device}, "\x00")
+			if seen[key] {
+				result.Status, result.Reason = uploadRowStatusDuplicate, uploadRowReasonDuplicateInFile
+				resp.Duplicate++
+				continue
+			}
+			seen[key] = true
+
+			keyword := &model.Keyword{
+				UserID:       userID,
+				WorkspaceID:  workspaceID,
+				Keyword:      row.Keyword,
+				Status:       model.KeywordStatusPending,
+				SearchEngine: se,
+				Market:       row.market,
+				Device:       row.device,
+				Tags:         row.Tags,
+				Schedule:     row.schedule,
+				NextScrapeAt: row.nextScrapeAt,
+				UploadID:     &upload.ID,
+			}
+
+			created, err := tx.CreateKeywordIfNotExists(keyword)
+			if err != nil {
+				tx.Rollback()
+				a.logger.Error().Str(l.KeyReqID, reqID).Err(err).Msg("")
+				e.ServerError(w, e.RespDBDataInsertFailure)
+				return
+			}
+
+			if !created {
+				result.Status, result.Reason = uploadRowStatusDuplicate, uploadRowReasonAlreadyExists
+				resp.Duplicate++
+				continue
+			}
+
+			result.Status, result.ID = uploadRowStatusCreated, &keyword.ID
+			resp.Created++
+			resp.CreatedIDs = append(resp.CreatedIDs, keyword.ID)
+			createdKeywords = append(createdKeywords, keyword)
+		}
+	}
+
+	uploadUpdates := map[string]any{
+		"created_count": resp.Created,
+		"skipped_count": resp.Duplicate + resp.Invalid,
+	}
+
+	if err := tx.UpdateUploadById(upload.ID, uploadUpdates); err != nil {
+		tx.Rollback()
+		a.logger.Error().Str(l.KeyReqID, reqID).Err(err).Msg("")
+		e.ServerError(w, e.RespDBDataInsertFailure)
+		return
+	}
+	tx.Commit()
+
+	if err := a.pushFair(ctx, tasks.QueueDefault, workspaceID, createdKeywords); err != nil {
+		a.logger.Error().Str(l.KeyReqID, reqID).Err(err).Str("task", "scrape-keyword").Msg("")
+	}
+
+	for _, v := range createdKeywords {
+		if err := a.events.Publish(ctx, workspaceID, events.TypeKeyword, &events.Keyword{ID: v.ID, Status: model.KeywordStatusPending}); err != nil {
+			a.logger.Error().Str(l.KeyReqID, reqID).Err(err).Msg("")
+		}
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		a.logger.Error().Str(l.KeyReqID, reqID).Err(err).Msg("")
+		return
+	}
+}
+
+// UpdateKeywordSchedule godoc
+// @summary Update the recurring schedule of a keyword
+// @description Set the recurring scrape schedule of a keyword of current workspace. A null schedule stops the recurring scrapes.
+// @tags keywords
+//
+// @router /keywords/{id}/schedule [PUT]
+// @accept json
+// @produce json
+// @security BearerToken
+// @param id path string true "Keyword ID"
+// @param body body FormSchedule true "Schedule Form; hourly, daily, weekly, a cron expression or null"
+//
+// @success 200 {object} model.KeywordDTO
+// @failure 400 {object} e.Error
+// @failure 401 {object} e.Error
+// @failure 404
+// @failure 500 {object} e.Error
+func (a *API) UpdateKeywordSchedule(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	reqID, ctxWorkspace := ctxutil.RequestID(ctx), ctxutil.WorkspaceFromCtx(ctx)
+
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil || id < 1 {
+		e.BadRequest(w, e.RespInvalidID)
+		return
+	}
+
+	form := &FormSchedule{}
+	if e.JSONBindAndValidateErrorHandled(w, r, a.logger, a.validator, form, reqID) {
+		return
+	}
+
+	var schedule *string
+	var nextScrapeAt *time.Time
+	if form.Schedule != nil && *form.Schedule != "" {
+		normalized, next, err := normalizeSchedule(*form.Schedule)
+		if err != nil {
+			e.BadRequest(w, e.RespInvalidSchedule)
+			return
+		}
+		schedule, nextScrapeAt = &normalized, next
+	}
+
+	rowsAffected, err := a.db.UpdateKeywordScheduleByIdAndWorkspaceId(int64(id), ctxWorkspace.ID, schedule, nextScrapeAt)
+	if err != nil {
+		a.logger.Error().Str(l.KeyReqID, reqID).Err(err).Msg("")
+		e.ServerError(w, e.RespDBDataUpdateFailure)
+		return
+	}
+
+	if rowsAffected == 0 {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	keyword, err := a.db.ReadKeywordByIdAndWorkspaceId(int64(id), ctxWorkspace.ID)
+	if err != nil {
+		a.logger.Error().Str(l.KeyReqID, reqID).Err(err).Msg("")
+		e.ServerError(w, e.RespDBDataAccessFailure)
+		return
+	}
+
+	if err := json.NewEncoder(w).Encode(keyword.ToDTO()); err != nil {
+		a.logger.Error().Str(l.KeyReqID, reqID).Err(err).Msg("")
+		e.ServerError(w, e.RespJSONEncodeFailure)
+		return
+	}
+}
+
+// RescrapeKeyword godoc
+// @summary Re-scrape a keyword
+// @description Move a keyword of current workspace back to pending and enqueue a new scrape
+// @tags keywords
+//
+// @router /keywords/{id}/rescrape [POST]
+// @accept json
+// @produce json
+// @security BearerToken
+// @param id path string true "Keyword ID"
+//
+// @success 202
+// @failure 400 {object} e.Error
+// @failure 401 {object} e.Error
+// @failure 404
+// @failure 409 {object} e.Error
+// @failure 500 {object} e.Error
+func (a *API) RescrapeKeyword(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	reqID, ctxWorkspace := ctxutil.RequestID(ctx), ctxutil.WorkspaceFromCtx(ctx)
+
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil || id < 1 {
+		e.BadRequest(w, e.RespInvalidID)
+		return
+	}
+
+	keyword, err := a.db.ReadKeywordByIdAndWorkspaceId(int64(id), ctxWorkspace.ID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		a.logger.Error().Str(l.KeyReqID, reqID).Err(err).Msg("")
+		e.ServerError(w, e.RespDBDataAccessFailure)
+		return
+	}
+
+	ids, err := a.rescrapeKeywords(ctx, reqID, ctxWorkspace.ID, model.Keywords{keyword})
+	if err != nil {
+		a.logger.Error().Str(l.KeyReqID, reqID).Err(err).Msg("")
+		e.ServerError(w, e.RespTaskEnqueueFailure)
+		return
+	}
+
+	if len(ids) == 0 {
+		e.Conflict(w, e.RespKeywordProcessing)
+		return
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// RescrapeKeywords godoc
+// @summary Re-scrape keywords in bulk
+// @description Move the keywords of current workspace, selected by IDs or by status, back to pending and enqueue new scrapes.
+// @description Keywords being processed are skipped. Up to 1000 keywords are re-scraped by status per request; more is true if some are left.
+// @tags keywords
+//
+// @router /keywords/rescrape [POST]
+// @accept json
+// @produce json
+// @security BearerToken
+// @param body body FormRescrape true "Rescrape Form"
+//
+// @success 202 {object} RespRescrape
+// @failure 400 {object} e.Error
+// @failure 400 {object} e.ValidationErrors
+// @failure 401 {object} e.Error
+// @failure 500 {object} e.Error
+func (a *API) RescrapeKeywords(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	reqID, ctxWorkspace := ctxutil.RequestID(ctx), ctxutil.WorkspaceFromCtx(ctx)
+
+	form := &FormRescrape{}
+	if e.JSONBindAndValidateErrorHandled(w, r, a.logger, a.validator, form, reqID) {
+		return
+	}
+
+	var keywords model.Keywords
+	var err error
+	if len(form.IDs) > 0 {
+		keywords, err = a.db.ListKeywordEnginesByIdsAndWorkspaceId(form.IDs, ctxWorkspace.ID)
+	} else {
+		keywords, err = a.db.ListKeywordEnginesByStatusAndWorkspaceId(form.Status, ctxWorkspace.ID, rescrapeMaxKeywords+1)
+	}
+	if err != nil {
+		a.logger.Error().Str(l.KeyReqID, reqID).Err(err).Msg("")
+		e.ServerError(w, e.RespDBDataAccessFailure)
+		return
+	}
+
+	// The keywords left in the status are re-scraped by the next requests
+	more := len(keywords) > rescrapeMaxKeywords
+	if more {
+		keywords = keywords[:rescrapeMaxKeywords]
+	}
+
+	ids, err := a.rescrapeKeywords(ctx, reqID, ctxWorkspace.ID, keywords)
+	if err != nil {
+		a.logger.Error().Str(l.KeyReqID, reqID).Err(err).Msg("")
+		e.ServerError(w, e.RespTaskEnqueueFailure)
+		return
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+	if err := json.NewEncoder(w).Encode(&RespRescrape{IDs: ids, More: more}); err != nil {
+		a.logger.Error().Str(l.KeyReqID, reqID).Err(err).Msg("")
+		return
+	}
+}
+
+// CancelKeyword godoc
+// @summary Cancel the scrape of a keyword
+// @description Delete the pending scrape task of a keyword of current workspace or cancel the in-flight one.
+// @description The recurring schedule of the keyword is cleared too.
+// @tags keywords
+//
+// @router /keywords/{id}/cancel [POST]
+// @accept json
+// @produce json
+// @security BearerToken
+// @param id path string true "Keyword ID"
+//
+// @success 200 {object} model.KeywordDTO
+// @failure 400 {object} e.Error
+// @failure 401 {object} e.Error
+// @failure 404
+// @failure 409 {object} e.Error
+// @failure 500 {object} e.Error
+func (a *API) CancelKeyword(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	reqID, ctxWorkspace := ctxutil.RequestID(ctx), ctxutil.WorkspaceFromCtx(ctx)
+
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil || id < 1 {
+		e.BadRequest(w, e.RespInvalidID)
+		return
+	}
+
+	keyword, err := a.db.ReadKeywordByIdAndWorkspaceId(int64(id), ctxWorkspace.ID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		a.logger.Error().Str(l.KeyReqID, reqID).Err(err).Msg("")
+		e.ServerError(w, e.RespDBDataAccessFailure)
+		return
+	}
+
+	// Failed keywords may still have retries left
+	cancellableStatuses := []string{model.KeywordStatusPending, model.KeywordStatusProcessing, model.KeywordStatusFailed}
+
+	// Update the status first, so the worker doesn't store the results of an in-flight scrape. The schedule is cleared,
+	// as the scheduled runs of a cancelled keyword are skipped
+	rowsAffected, err := a.db.CancelKeywordByIdAndStatuses(keyword.ID, cancellableStatuses)
+	if err != nil {
+		a.logger.Error().Str(l.KeyReqID, reqID).Err(err).Msg("")
+		e.ServerError(w, e.RespDBDataUpdateFailure)
+		return
+	}
+
+	if rowsAffected == 0 {
+		e.Conflict(w, e.RespKeywordNotCancellable)
+		return
+	}
+
+	// The cancellation holds once stored, as the worker skips the cancelled keywords; the queues are only cleared up
+	if err := a.fair.Remove(ctx, tasks.FairScrapeKeywordQueues(keyword.SearchEngine), ctxWorkspace.ID, keyword.ID); err != nil {
+		a.logger.Error().Str(l.KeyReqID, reqID).Err(err).Msg("")
+	}
+
+	if err := tasks.CancelTask(a.inspector, tasks.ScrapeKeywordQueues(keyword.SearchEngine), tasks.ScrapeKeywordTaskID(keyword.ID)); err != nil {
+		a.logger.Error().Str(l.KeyReqID, reqID).Err(err).Msg("")
+	}
+
+	keyword.Status, keyword.Schedule, keyword.NextScrapeAt = model.KeywordStatusCancelled, nil, nil
+	if err := a.events.PublishKeyword(ctx, keyword); err != nil {
+		a.logger.Error().Str(l.KeyReqID, reqID).Err(err).Msg("")
+	}
+
+	// The cancelled keyword may be the last unfinished one of its upload
+	if keyword.UploadID != nil {
+		if err := a.completeUpload(*keyword.UploadID); err != nil {
+			a.logger.Error().Str(l.KeyReqID, reqID).Err(err).Msg("")
+		}
+	}
+
+	if err := json.NewEncoder(w).Encode(keyword.ToDTO()); err != nil {
+		a.logger.Error().Str(l.KeyReqID, reqID).Err(err).Msg("")
+		e.ServerError(w, e.RespJSONEncodeFailure)
+		return
+	}
+}
+
+// completeUpload enqueues the upload completed task once none of the keywords of the upload are pending or processing.
+func (a *API) completeUpload(uploadID int64) error {
+	failed, err := a.db.ListFailedKeywordEnginesByUploadId(uploadID)
+	if err != nil {
+		return err
+	}
+
+	// Failed keywords may still have retries left
+	retrying := make([]int64, 0)
+	for _, v := range failed {
+		ok, err := tasks.TaskRetrying(a.inspector, tasks.ScrapeKeywordQueues(v.SearchEngine), tasks.ScrapeKeywordTaskID(v.ID))
+		if err != nil {
+			return err
+		}
+		if ok {
+			retrying = append(retrying, v.ID)
+		}
+	}
+
+	completed, err := a.db.CompleteUploadById(uploadID, retrying)
+	if err != nil || !completed {
+		return err
+	}
+
+	_, err = a.asyq.Enqueue(tasks.NewUploadCompletedTask(uploadID))
+	return err
+}
+
+// rescrapeKeywords moves the given keywords back to pending and pushes them to the critical fair queues by batch,
+// skipping the ones being processed, so the keywords already handled are re-scraped even if a later batch fails.
+// Returns the IDs of the pushed keywords.
+func (a *API) rescrapeKeywords(ctx context.Context, reqID string, workspaceID uuid.UUID, keywords model.Keywords) ([]int64, error) {
+	rescrapeIds := make([]int64, 0, len(keywords))
+	for batch := range slices.Chunk(keywords, rescrapeBatchSize) {
+		ids, err := a.rescrapeBatch(ctx, reqID, workspaceID, batch)
+		rescrapeIds = append(rescrapeIds, ids...)
+		if err != nil {
+			return rescrapeIds, err
+		}
+	}
+
+	return rescrapeIds, nil
+}
+
+// rescrapeBatch moves a batch of keywords back to pending and pushes them to the critical fair queues, skipping the ones being processed.
+func (a *API) rescrapeBatch(ctx context.Context, reqID string, workspaceID uuid.UUID, keywords model.Keywords) ([]int64, error) {
+	rescrapes := make(model.Keywords, 0, len(keywords))
+	rescrapeIds := make([]int64, 0, len(keywords))
+	for _, v := range keywords {
+		err := tasks.DeleteTask(a.inspector, tasks.ScrapeKeywordQueues(v.SearchEngine), tasks.ScrapeKeywordTaskID(v.ID))
+		if errors.Is(err, tasks.ErrTaskActive) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		// A pending keyword may still be waiting in a fair queue
+		if err := a.fair.Remove(ctx, tasks.FairScrapeKeywordQueues(v.SearchEngine), workspaceID, v.ID); err != nil {
+			return nil, err
+		}
+
+		rescrapes = append(rescrapes, v)
+		rescrapeIds = append(rescrapeIds, v.ID)
+	}
+
+	if len(rescrapeIds) == 0 {
+		return rescrapeIds, nil
+	}
+
+	if err := a.db.UpdateKeywordsStatusByIds(rescrapeIds, model.KeywordStatusPending); err != nil {
+		return nil, err
+	}
+
+	if err := a.pushFair(ctx, tasks.QueueCritical, workspaceID, rescrapes); err != nil {
+		a.logger.Error().Str(l.KeyReqID, reqID).Err(err).Str("task", "scrape-keyword").Msg("")
+	}
+
+	for _, v := range rescrapes {
+		if err := a.events.Publish(ctx, workspaceID, events.TypeKeyword, &events.Keyword{ID: v.ID, Status: model.KeywordStatusPending}); err != nil {
+			a.logger.Error().Str(l.KeyReqID, reqID).Err(err).Msg("")
+		}
+	}
+
+	return rescrapeIds, nil
+}
+
+// pushFair pushes the keywords of the workspace to the fair queues of the priority and their search engines,
+// from which the workers enqueue their scrape tasks round-robin across the workspaces.
+func (a *API) pushFair(ctx context.Context, priority string, workspaceID uuid.UUID, keywords model.Keywords) error {
+	ids := make(map[string][]int64)
+	for _, v := range keywords {
+		ids[v.SearchEngine] = append(ids[v.SearchEngine], v.ID)
+	}
+
+	for engine, v := range ids {
+		if err := a.fair.Push(ctx, tasks.ScrapeKeywordQueue(priority, engine), workspaceID, v...); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// setQueuePositions sets the queue positions of the pending keywords of the workspace waiting in the fair queues,
+// reading the fair queues of each search engine once.
+func (a *API) setQueuePositions(ctx context.Context, reqID string, workspaceID uuid.UUID, dtos ...*model.KeywordDTO) {
+	pending := make(map[string][]*model.KeywordDTO)
+	for _, v := range dtos {
+		if v.Status == model.KeywordStatusPending {
+			pending[v.SearchEngine] = append(pending[v.SearchEngine], v)
+		}
+	}
+
+	for engine, engineDtos := range pending {
+		ids := make([]int64, len(engineDtos))
+		for i, v := range engineDtos {
+			ids[i] = v.ID
+		}
+
+		positions, err := a.fair.Positions(ctx, tasks.FairScrapeKeywordQueues(engine), workspaceID, ids...)
+		if err != nil {
+			a.logger.Error().Str(l.KeyReqID, reqID).Err(err).Msg("")
+			return
+		}
+
+		for _, v := range engineDtos {
+			if position, ok := positions[v.ID]; ok {
+				v.QueuePosition = &position
+			}
+		}
+	}
+}
+
+// normalizeSchedule validates the given schedule and returns it with its next activation time.
+func normalizeSchedule(v string) (string, *time.Time, error) {
+	schedule, err := scheduler.Normalize(v)
+	if err != nil {
+		return "", nil, err
+	}
+
+	next, err := scheduler.Next(schedule, time.Now())
+	if err != nil {
+		return "", nil, err
+	}
+
+	return schedule, &next, nil
+}
+
+// uploadRow is a record of an uploaded file with its options resolved, or the reason why it is invalid.
+type uploadRow struct {
+	*keywordfile.Record
+	searchEngines []string
+	market        string
+	device        string
+	schedule      *string
+	nextScrapeAt  *time.Time
+	reason        string
+}
+
+// newUploadRow validates the record; its empty options default to the ones given with the file.
+func newUploadRow(record *keywordfile.Record, searchEngines []string, schedule *string, nextScrapeAt *time.Time) *uploadRow {
+	row := &uploadRow{
+		Record:        record,
+		searchEngines: searchEngines,
+		device:        model.KeywordDeviceDesktop,
+		schedule:      schedule,
+		nextScrapeAt:  nextScrapeAt,
+	}
+
+	if row.reason = invalidKeywordReason(record.Keyword); row.reason != "" {
+		return row
+	}
+
+	if record.SearchEngine != "" {
+		var ok bool
+		if row.searchEngines, ok = searchEnginesFromForm([]string{record.SearchEngine}); !ok {
+			row.reason = uploadRowReasonInvalidSearchEngine
+			return row
+		}
+	}
+
+	if record.Market != "" {
+		var ok bool
+		if row.market, ok = searchengine.NormalizeMarket(record.Market); !ok {
+			row.reason = uploadRowReasonInvalidMarket
+			return row
+		}
+	}
+
+	if record.Device != "" {
+		row.device = strings.ToLower(record.Device)
+		if row.device != model.KeywordDeviceDesktop && row.device != model.KeywordDeviceMobile {
+			row.reason = uploadRowReasonInvalidDevice
+			return row
+		}
+	}
+
+	if record.Schedule != "" {
+		normalized, next, err := normalizeSchedule(record.Schedule)
+		if err != nil {
+			row.reason = uploadRowReasonInvalidSchedule
+			return row
+		}
+		row.schedule, row.nextScrapeAt = &normalized, next
+	}
+
+	return row
+}
+
+func invalidKeywordReason(keyword string) string {
+	switch {
+	case keyword == "":
+		return uploadRowReasonEmpty
+	case !utf8.ValidString(keyword):
+		return uploadRowReasonInvalidEncoding
+	case utf8.RuneCountInString(keyword) > uploadKeywordMaxLength:
+		return uploadRowReasonTooLong
+	case strings.IndexFunc(keyword, unicode.IsControl) >= 0:
+		return uploadRowReasonControlChars
+	}
+
+	return ""
+}
+
+// includesHTMLContent reports whether the HTML content is requested via the include query parameter.
+func includesHTMLContent(q url.Values) (bool, bool) {
+	var result bool
+	for _, v := range strings.Split(q.Get(queryKeyInclude), ",") {
+		switch strings.TrimSpace(v) {
+		case "":
+		case includeHTMLContent:
+			result = true
+		default:
+			return false, false
+		}
+	}
+
+	return result, true
+}
+
+// keywordsFilterFromQuery reads the keyword list filters and sort order from the query params.
+// Returns false if any of the given values is invalid.
+func keywordsFilterFromQuery(q url.Values) (*repository.KeywordsFilter, bool) {
+	filter := &repository.KeywordsFilter{
+		Status:       q.Get(queryKeyStatus),
+		SearchEngine: q.Get(queryKeySearchEngine),
+		Query:        strings.TrimSpace(q.Get(queryKeyQ)),
+		SortBy:       "created_at",
+		SortDesc:     true,
+	}
+
+	if filter.Status != "" && !keywordStatuses[filter.Status] {
+		return nil, false
+	}
+
+	if filter.SearchEngine != "" && !searchengine.IsSupported(filter.SearchEngine) {
+		return nil, false
+	}
+
+	if v := q.Get(queryKeyUploadID); v != "" {
+		id, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || id < 1 {
+			return nil, false
+		}
+		filter.UploadID = &id
+	}
+
+	if v := q.Get(queryKeyCreatedFrom); v != "" {
+		t, _, err := parseTimeOrDate(v)
+		if err != nil {
+			return nil, false
+		}
+		filter.CreatedFrom = &t
+	}
+
+	if v := q.Get(queryKeyCreatedTo); v != "" {
+		t, isDate, err := parseTimeOrDate(v)
+		if err != nil {
+			return nil, false
+		}
+
+		// A date includes the whole day
+		if isDate {
+			t = t.AddDate(0, 0, 1)
+		}
+		filter.CreatedTo = &t
+	}
+
+	if v := q.Get(queryKeySort); v != "" {
+		filter.SortBy, filter.SortDesc = strings.TrimPrefix(v, "-"), strings.HasPrefix(v, "-")
+		if !keywordsSortColumns[filter.SortBy] {
+			return nil, false
+		}
+	}
+
+	return filter, true
+}
+
+// parseTimeOrDate parses an RFC 3339 time or a date in the local time zone, and reports whether it was a date.
+func parseTimeOrDate(v string) (time.Time, bool, error) {
+	if t, err := time.ParseInLocation(dateLayout, v, time.Local); err == nil {
+		return t, true, nil
+	}
+
+	t, err := time.Parse(time.RFC3339, v)
+	return t, false, err
+}
+
+// searchEnginesFromForm returns the unique search engines in the given form values, which may also be comma separated.
+// Defaults to Bing when no value is given and returns false if any value is not a supported search engine.
+func searchEnginesFromForm(values []string) ([]string, bool) {
+	var searchEngines []string
+	seen := make(map[string]bool)
+
+	for _, value := range values {
+		for _, v := range strings.Split(value, ",") {
+			v = strings.ToLower(strings.TrimSpace(v))
+			if v == "" || seen[v] {
+				continue
+			}
+
+			if !searchengine.IsSupported(v) {
+				return nil, false
+			}
+
+			seen[v] = true
+			searchEngines = append(searchEngines, v)
+		}
+	}
+
+	if len(searchEngines) == 0 {
+		searchEngines = []string{searchengine.Bing}
+	}
+
+	return searchEngines, true
 }
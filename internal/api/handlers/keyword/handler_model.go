@@ -0,0 +1,37 @@
+package keyword
+
+type FormSchedule struct {
+	Schedule *string `json:"schedule"`
+}
+
+type FormRescrape struct {
+	IDs    []int64 `json:"ids" validate:"required_without=Status,max=1000,dive,min=1"`
+	Status string  `json:"status" validate:"required_without=IDs,omitempty,oneof=completed failed cancelled"`
+}
+
+type RespRescrape struct {
+	IDs []int64 `json:"ids"`
+	// More is true if keywords of the status are left to re-scrape by another request
+	More bool `json:"more"`
+}
+
+type RespUpload struct {
+	UploadID   int64            `json:"uploadId"`
+	Created    int              `json:"created"`
+	Duplicate  int              `json:"duplicate"`
+	Invalid    int              `json:"invalid"`
+	CreatedIDs []int64          `json:"createdIds"`
+	Rows       []*RespUploadRow `json:"rows"`
+}
+
+// RespUploadRow is the outcome of a row of the uploaded file; valid rows have one per search engine.
+type RespUploadRow struct {
+	Line         int    `json:"line"`
+	Keyword      string `json:"keyword"`
+	SearchEngine string `json:"searchEngine,omitempty"`
+	Market       string `json:"market,omitempty"`
+	Device       string `json:"device,omitempty"`
+	Status       string `json:"status" enums:"created,duplicate,invalid"`
+	Reason       string `json:"reason,omitempty"`
+	ID           *int64 `json:"id,omitempty"`
+}
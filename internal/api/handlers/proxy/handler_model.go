@@ -0,0 +1,9 @@
+package proxy
+
+type FormProxy struct {
+	URL string `json:"url" validate:"required,max=2048"`
+}
+
+type FormUpdateProxy struct {
+	Active *bool `json:"active" validate:"required"`
+}
@@ -0,0 +1,260 @@
+package upload
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"gorm.io/gorm"
+
+	e "web-scraper.dev/internal/api/errors"
+	"web-scraper.dev/internal/export"
+	"web-scraper.dev/internal/repository"
+	"web-scraper.dev/internal/utils/ctxutil"
+	l "web-scraper.dev/internal/utils/logger"
+	"web-scraper.dev/internal/utils/pageutil"
+)
+
+type API struct {
+	db     *repository.Db
+	logger *l.Logger
+}
+
+func New(db *gorm.DB, logger *l.Logger) *API {
+	return &API{
+		db:     repository.New(db),
+		logger: logger,
+	}
+}
+
+// GetUploads godoc
+// @summary Get the list of uploads
+// @description Get a page of keyword files uploaded to current workspace with their progress, latest first
+// @tags uploads
+//
+// @router /uploads [GET]
+// @accept json
+// @produce json
+// @security BearerToken
+// @param page query int false "Page number, starts from 1"
+// @param per_page query int false "Number of uploads per page, max 100"
+//
+// @success 200 {array} model.UploadDTO
+// @header 200 {integer} X-Total-Count "Total number of uploads"
+// @failure 401 {object} e.Error
+// @failure 500 {object} e.Error
+func (a *API) GetUploads(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	reqID, ctxWorkspace := ctxutil.RequestID(ctx), ctxutil.WorkspaceFromCtx(ctx)
+
+	page := pageutil.FromRequest(r)
+	uploads, total, err := a.db.ListUploadsByWorkspaceId(ctxWorkspace.ID, page.Offset(), page.Limit())
+	if err != nil {
+		a.logger.Error().Str(l.KeyReqID, reqID).Err(err).Msg("")
+		e.ServerError(w, e.RespDBDataAccessFailure)
+		return
+	}
+
+	ids := make([]int64, len(uploads))
+	for i, v := range uploads {
+		ids[i] = v.ID
+	}
+
+	counts, err := a.db.CountKeywordsByUploadIds(ids)
+	if err != nil {
+		a.logger.Error().Str(l.KeyReqID, reqID).Err(err).Msg("")
+		e.ServerError(w, e.RespDBDataAccessFailure)
+		return
+	}
+
+	pageutil.SetTotalCount(w, total)
+
+	dto := uploads.ToDTOs(counts)
+	if err := json.NewEncoder(w).Encode(&dto); err != nil {
+		a.logger.Error().Str(l.KeyReqID, reqID).Err(err).Msg("")
+		e.ServerError(w, e.RespJSONEncodeFailure)
+		return
+	}
+}
+
+// GetUpload godoc
+// @summary Get an upload
+// @description Get a keyword file uploaded to current workspace with the progress of its keywords
+// @tags uploads
+//
+// @router /uploads/{id} [GET]
+// @accept json
+// @produce json
+// @security BearerToken
+// @param id path string true "Upload ID"
+//
+// @success 200 {object} model.UploadDTO
+// @failure 400 {object} e.Error
+// @failure 401 {object} e.Error
+// @failure 404
+// @failure 500 {object} e.Error
+func (a *API) GetUpload(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	reqID, ctxWorkspace := ctxutil.RequestID(ctx), ctxutil.WorkspaceFromCtx(ctx)
+
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil || id < 1 {
+		e.BadRequest(w, e.RespInvalidID)
+		return
+	}
+
+	upload, err := a.db.ReadUploadByIdAndWorkspaceId(int64(id), ctxWorkspace.ID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		a.logger.Error().Str(l.KeyReqID, reqID).Err(err).Msg("")
+		e.ServerError(w, e.RespDBDataAccessFailure)
+		return
+	}
+
+	counts, err := a.db.CountKeywordsByUploadIds([]int64{upload.ID})
+	if err != nil {
+		a.logger.Error().Str(l.KeyReqID, reqID).Err(err).Msg("")
+		e.ServerError(w, e.RespDBDataAccessFailure)
+		return
+	}
+
+	if err := json.NewEncoder(w).Encode(upload.ToDTO(counts[upload.ID])); err != nil {
+		a.logger.Error().Str(l.KeyReqID, reqID).Err(err).Msg("")
+		e.ServerError(w, e.RespJSONEncodeFailure)
+		return
+	}
+}
+
+// GetUploadKeywords godoc
+// @summary Get the keywords of an upload
+// @description Get a page of keywords created by a keyword file uploaded to current workspace, in file order
+// @tags uploads
+//
+// @router /uploads/{id}/keywords [GET]
+// @accept json
+// @produce json
+// @security BearerToken
+// @param id path string true "Upload ID"
+// @param page query int false "Page number, starts from 1"
+// @param per_page query int false "Number of keywords per page, max 100"
+//
+// @success 200 {array} model.KeywordDTO
+// @header 200 {integer} X-Total-Count "Total number of keywords"
+// @failure 400 {object} e.Error
+// @failure 401 {object} e.Error
+// @failure 404
+// @failure 500 {object} e.Error
+func (a *API) GetUploadKeywords(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	reqID, ctxWorkspace := ctxutil.RequestID(ctx), ctxutil.WorkspaceFromCtx(ctx)
+
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil || id < 1 {
+		e.BadRequest(w, e.RespInvalidID)
+		return
+	}
+
+	upload, err := a.db.ReadUploadByIdAndWorkspaceId(int64(id), ctxWorkspace.ID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		a.logger.Error().Str(l.KeyReqID, reqID).Err(err).Msg("")
+		e.ServerError(w, e.RespDBDataAccessFailure)
+		return
+	}
+
+	filter := &repository.KeywordsFilter{UploadID: &upload.ID, SortBy: "id"}
+
+	page := pageutil.FromRequest(r)
+	keywords, total, err := a.db.ListKeywordsByWorkspaceId(ctxWorkspace.ID, filter, page.Offset(), page.Limit())
+	if err != nil {
+		a.logger.Error().Str(l.KeyReqID, reqID).Err(err).Msg("")
+		e.ServerError(w, e.RespDBDataAccessFailure)
+		return
+	}
+
+	pageutil.SetTotalCount(w, total)
+
+	dto := keywords.ToDTOs()
+	if err := json.NewEncoder(w).Encode(&dto); err != nil {
+		a.logger.Error().Str(l.KeyReqID, reqID).Err(err).Msg("")
+		e.ServerError(w, e.RespJSONEncodeFailure)
+		return
+	}
+}
+
+// ExportUploadKeywords godoc
+// @summary Export the keywords of an upload
+// @description Export the keywords created by a keyword file uploaded to current workspace as a file, in file order. The results column adds the organic results and ads of the latest runs.
+// @tags uploads
+//
+// @router /uploads/{id}/export [GET]
+// @produce text/csv
+// @produce application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @produce json
+// @produce application/x-ndjson
+// @security BearerToken
+// @param id path string true "Upload ID"
+// @param format query string false "Export format" Enums(csv, xlsx, json, ndjson) default(csv)
+// @param columns query string false "Comma separated columns; id, keyword, search_engine, market, device, tags, status, ad_count, link_count, error, scraped_at and results. Defaults to keyword, search_engine, status, ad_count, link_count, error and scraped_at"
+//
+// @success 200 {file} file
+// @failure 400 {object} e.Error
+// @failure 401 {object} e.Error
+// @failure 404
+// @failure 500 {object} e.Error
+func (a *API) ExportUploadKeywords(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	reqID, ctxWorkspace := ctxutil.RequestID(ctx), ctxutil.WorkspaceFromCtx(ctx)
+
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil || id < 1 {
+		e.BadRequest(w, e.RespInvalidID)
+		return
+	}
+
+	opts, err := export.OptionsFromQuery(r.URL.Query())
+	if err != nil {
+		if errors.Is(err, export.ErrInvalidFormat) {
+			e.BadRequest(w, e.RespInvalidExportFormat)
+			return
+		}
+
+		e.BadRequest(w, e.RespInvalidExportColumns)
+		return
+	}
+
+	upload, err := a.db.ReadUploadByIdAndWorkspaceId(int64(id), ctxWorkspace.ID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		a.logger.Error().Str(l.KeyReqID, reqID).Err(err).Msg("")
+		e.ServerError(w, e.RespDBDataAccessFailure)
+		return
+	}
+
+	filter := &repository.KeywordsFilter{UploadID: &upload.ID, SortBy: "id"}
+
+	filename := fmt.Sprintf("upload-%d-keywords", upload.ID)
+	started, err := export.Keywords(w, repository.New(a.db.WithContext(ctx)), ctxWorkspace.ID, filter, opts, filename)
+	if err != nil {
+		a.logger.Error().Str(l.KeyReqID, reqID).Err(err).Msg("")
+		if !started {
+			e.ServerError(w, e.RespDBDataAccessFailure)
+		}
+		return
+	}
+}
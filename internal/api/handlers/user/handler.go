@@ -1,20 +1,28 @@
 package user
 
 import (
+	"context"
+	"crypto/subtle"
 	"encoding/json"
 	"errors"
+	"math"
+	"net"
 	"net/http"
+	"strconv"
 	"strings"
 	"time"
 
 	v "github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
 	"golang.org/x/crypto/bcrypt"
 	"gorm.io/gorm"
 
 	e "web-scraper.dev/internal/api/errors"
+	"web-scraper.dev/internal/attempts"
 	"web-scraper.dev/internal/mailer"
 	"web-scraper.dev/internal/model"
 	"web-scraper.dev/internal/repository"
+	"web-scraper.dev/internal/revocation"
 	"web-scraper.dev/internal/utils/ctxutil"
 	"web-scraper.dev/internal/utils/jwtutil"
 	l "web-scraper.dev/internal/utils/logger"
@@ -25,14 +33,18 @@ type API struct {
 	mailer    *mailer.Mailer
 	logger    *l.Logger
 	validator *v.Validate
+	denylist  *revocation.Denylist
+	attempts  *attempts.Limiter
 }
 
-func New(db *gorm.DB, mailer *mailer.Mailer, logger *l.Logger, validator *v.Validate) *API {
+func New(db *gorm.DB, mailer *mailer.Mailer, logger *l.Logger, validator *v.Validate, denylist *revocation.Denylist, attempts *attempts.Limiter) *API {
 	return &API{
 		db:        repository.New(db),
 		mailer:    mailer,
 		logger:    logger,
 		validator: validator,
+		denylist:  denylist,
+		attempts:  attempts,
 	}
 }
 
@@ -84,11 +96,30 @@ func (a *API) SignUp(w http.ResponseWriter, r *http.Request) {
 	}
 
 	userModel := model.NewUser(form.Email, string(hashedPassword))
-	if err := a.db.CreateUser(userModel); err != nil {
+
+	// Every user owns a personal workspace with the same ID
+	tx := a.db.TxBegin()
+	if err := tx.CreateUser(userModel); err != nil {
+		tx.Rollback()
+		a.logger.Error().Str(l.KeyReqID, reqID).Err(err).Msg("")
+		e.ServerError(w, e.RespDBDataInsertFailure)
+		return
+	}
+
+	if err := tx.CreateWorkspace(model.NewPersonalWorkspace(userModel.ID)); err != nil {
+		tx.Rollback()
+		a.logger.Error().Str(l.KeyReqID, reqID).Err(err).Msg("")
+		e.ServerError(w, e.RespDBDataInsertFailure)
+		return
+	}
+
+	if err := tx.CreateWorkspaceMember(model.NewWorkspaceMember(userModel.ID, userModel.ID, model.WorkspaceRoleOwner)); err != nil {
+		tx.Rollback()
 		a.logger.Error().Str(l.KeyReqID, reqID).Err(err).Msg("")
 		e.ServerError(w, e.RespDBDataInsertFailure)
 		return
 	}
+	tx.Commit()
 
 	if err := a.mailer.ActivationMail(form.Email, userModel.ActivationToken.Token); err != nil {
 		a.logger.Error().Str(l.KeyReqID, reqID).Err(err).Msg("")
@@ -114,6 +145,7 @@ func (a *API) SignUp(w http.ResponseWriter, r *http.Request) {
 // @success 400 {object} e.Error
 // @failure 401 {object} e.Error
 // @failure 409 {object} e.Error
+// @failure 429 {object} e.Error
 // @failure 500 {object} e.Error
 func (a *API) Activate(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
@@ -126,7 +158,12 @@ func (a *API) Activate(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	user, err := a.db.ReadUserWithActivationTokenByEmail(email)
+	account := strings.ToLower(email)
+	if a.attemptsWaitHandled(w, r, reqID, attempts.ActionActivate, account) {
+		return
+	}
+
+	user, err := a.db.ReadUserWithActivationTokenByEmail(account)
 	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
 		a.logger.Error().Str(l.KeyReqID, reqID).Err(err).Msg("")
 		e.ServerError(w, e.RespDBDataAccessFailure)
@@ -134,6 +171,7 @@ func (a *API) Activate(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if user == nil || user.ActivationToken == nil {
+		a.failAttempt(r, reqID, attempts.ActionActivate, account, nil)
 		e.Unauthorized(w, e.RespUnauthorized)
 		return
 	}
@@ -156,7 +194,15 @@ func (a *API) Activate(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if user.ActivationToken.Token != token {
+	if subtle.ConstantTimeCompare([]byte(user.ActivationToken.Token), []byte(token)) != 1 {
+		// Too many wrong codes; the next attempt sends a new one
+		failure := a.failAttempt(r, reqID, attempts.ActionActivate, account, user)
+		if failure != nil && failure.Failures >= a.attempts.Policy().CodeFailures {
+			if err := a.db.ExpireUserActivationTokenByUserId(user.ID); err != nil {
+				a.logger.Error().Str(l.KeyReqID, reqID).Err(err).Msg("")
+			}
+		}
+
 		e.BadRequest(w, e.RespTokenInvalid)
 		return
 	}
@@ -166,6 +212,8 @@ func (a *API) Activate(w http.ResponseWriter, r *http.Request) {
 		e.ServerError(w, e.RespDBDataDeleteFailure)
 		return
 	}
+
+	a.resetAttempts(ctx, reqID, attempts.ActionActivate, account)
 }
 
 // SignIn godoc
@@ -184,16 +232,23 @@ func (a *API) Activate(w http.ResponseWriter, r *http.Request) {
 // @failure 403 {object} e.Error
 // @failure 409 {object} e.Error
 // @failure 422 {object} e.Error
+// @failure 429 {object} e.Error
 // @failure 500 {object} e.Error
 func (a *API) SignIn(w http.ResponseWriter, r *http.Request) {
-	reqID := ctxutil.RequestID(r.Context())
+	ctx := r.Context()
+	reqID := ctxutil.RequestID(ctx)
 
 	form := &FormSignIn{}
 	if e.JSONBindAndValidateErrorHandled(w, r, a.logger, a.validator, form, reqID) {
 		return
 	}
 
-	user, err := a.db.ReadUserWithActivationTokenAndUserAuthByEmail(strings.ToLower(form.Email))
+	account := strings.ToLower(form.Email)
+	if a.attemptsWaitHandled(w, r, reqID, attempts.ActionSignIn, account) {
+		return
+	}
+
+	user, err := a.db.ReadUserWithActivationTokenAndUserAuthByEmail(account)
 	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
 		a.logger.Error().Str(l.KeyReqID, reqID).Err(err).Msg("")
 		e.ServerError(w, e.RespDBDataAccessFailure)
@@ -201,6 +256,7 @@ func (a *API) SignIn(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if user == nil || user.Auth == nil {
+		a.failAttempt(r, reqID, attempts.ActionSignIn, account, nil)
 		e.Unauthorized(w, e.RespUnauthorized)
 		return
 	}
@@ -212,6 +268,7 @@ func (a *API) SignIn(w http.ResponseWriter, r *http.Request) {
 
 	if err := bcrypt.CompareHashAndPassword([]byte(user.Auth.Password), []byte(form.Password)); err != nil {
 		if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+			a.failAttempt(r, reqID, attempts.ActionSignIn, account, user)
 			e.Unauthorized(w, e.RespFalseAuthentication)
 			return
 		}
@@ -221,17 +278,488 @@ func (a *API) SignIn(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	accessToken, refreshToken, err := jwtutil.NewAccessTokenAndRefreshToken(user.ID.String(), user.Email)
+	a.resetAttempts(ctx, reqID, attempts.ActionSignIn, account)
+
+	// Each sign-in starts a new family of refresh tokens
+	tokens, err := a.newTokens(user.ID, user.Email, uuid.New())
 	if err != nil {
 		a.logger.Error().Str(l.KeyReqID, reqID).Err(err).Msg("")
 		e.ServerError(w, e.RespJWTTokenGenerationFailure)
 		return
 	}
 
-	tokens := RespTokens{Access: accessToken, Refresh: refreshToken}
 	if err := json.NewEncoder(w).Encode(tokens); err != nil {
 		a.logger.Error().Str(l.KeyReqID, reqID).Err(err).Msg("")
 		e.ServerError(w, e.RespJSONEncodeFailure)
 		return
 	}
 }
+
+// ForgotPassword godoc
+// @summary Forgot password
+// @description Send an email with a 6-character long code to reset the password, if a user with the given email exists.
+// @description The response is the same either way, so it can't be used to find out the registered emails.
+// @tags users
+//
+// @router /users/forgot-password [POST]
+// @accept json
+// @produce json
+// @param body body FormForgotPassword true "Forgot Password Form"
+//
+// @success 202 "Accepted"
+// @failure 400 {object} e.Error
+// @failure 400 {object} e.ValidationErrors
+// @failure 429 {object} e.Error
+// @failure 500 {object} e.Error
+func (a *API) ForgotPassword(w http.ResponseWriter, r *http.Request) {
+	reqID := ctxutil.RequestID(r.Context())
+
+	form := &FormForgotPassword{}
+	if e.JSONBindAndValidateErrorHandled(w, r, a.logger, a.validator, form, reqID) {
+		return
+	}
+
+	// Every request is counted, as each one sends an email and replaces the previous code, registered email or not,
+	// so the limit doesn't tell them apart. The owner isn't told about the lock, which would be one more email
+	account := strings.ToLower(form.Email)
+	if a.attemptsWaitHandled(w, r, reqID, attempts.ActionForgotPassword, account) {
+		return
+	}
+	a.failAttempt(r, reqID, attempts.ActionForgotPassword, account, nil)
+
+	user, err := a.db.ReadUserByEmail(account)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		a.logger.Error().Str(l.KeyReqID, reqID).Err(err).Msg("")
+		e.ServerError(w, e.RespDBDataAccessFailure)
+		return
+	}
+
+	if user == nil {
+		w.WriteHeader(http.StatusAccepted)
+		return
+	}
+
+	userResetPasswordTokenModel := model.NewUserResetPasswordToken(user.ID)
+	if err := a.db.CreateOrUpdateUserResetPasswordTokenByUserId(userResetPasswordTokenModel); err != nil {
+		a.logger.Error().Str(l.KeyReqID, reqID).Err(err).Msg("")
+		e.ServerError(w, e.RespDBDataUpdateFailure)
+		return
+	}
+
+	// Sending takes a while, which would tell the registered emails apart by the response time
+	go func() {
+		if err := a.mailer.ResetPasswordMail(user.Email, userResetPasswordTokenModel.Token); err != nil {
+			a.logger.Error().Str(l.KeyReqID, reqID).Err(err).Msg("")
+		}
+	}()
+
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// ResetPassword godoc
+// @summary Reset password
+// @description Set a new password by using the code sent by the forgot password request.
+// @description All the sessions of the user are signed out.
+// @tags users
+//
+// @router /users/reset-password [POST]
+// @accept json
+// @produce json
+// @param body body FormResetPassword true "Reset Password Form"
+//
+// @success 204 "No Content"
+// @failure 400 {object} e.Error
+// @failure 400 {object} e.ValidationErrors
+// @failure 429 {object} e.Error
+// @failure 500 {object} e.Error
+func (a *API) ResetPassword(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	reqID := ctxutil.RequestID(ctx)
+
+	form := &FormResetPassword{}
+	if e.JSONBindAndValidateErrorHandled(w, r, a.logger, a.validator, form, reqID) {
+		return
+	}
+
+	account := strings.ToLower(form.Email)
+	if a.attemptsWaitHandled(w, r, reqID, attempts.ActionResetPassword, account) {
+		return
+	}
+
+	user, err := a.db.ReadUserWithResetPasswordTokenByEmail(account)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		a.logger.Error().Str(l.KeyReqID, reqID).Err(err).Msg("")
+		e.ServerError(w, e.RespDBDataAccessFailure)
+		return
+	}
+
+	if user == nil || user.ResetPasswordToken == nil {
+		a.failAttempt(r, reqID, attempts.ActionResetPassword, account, nil)
+		e.BadRequest(w, e.RespTokenInvalid)
+		return
+	}
+
+	if subtle.ConstantTimeCompare([]byte(user.ResetPasswordToken.Token), []byte(form.Token)) != 1 {
+		// Too many wrong codes; a new one has to be asked for
+		failure := a.failAttempt(r, reqID, attempts.ActionResetPassword, account, user)
+		if failure != nil && failure.Failures >= a.attempts.Policy().CodeFailures {
+			if err := a.db.DeleteUserResetPasswordTokenByUserId(user.ID); err != nil {
+				a.logger.Error().Str(l.KeyReqID, reqID).Err(err).Msg("")
+			}
+		}
+
+		e.BadRequest(w, e.RespTokenInvalid)
+		return
+	}
+
+	if user.ResetPasswordToken.TokenExpiredAt.Before(time.Now()) {
+		if err := a.db.DeleteUserResetPasswordTokenByUserId(user.ID); err != nil {
+			a.logger.Error().Str(l.KeyReqID, reqID).Err(err).Msg("")
+			e.ServerError(w, e.RespDBDataDeleteFailure)
+			return
+		}
+
+		e.BadRequest(w, e.RespTokenExpired)
+		return
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(form.Password), bcrypt.DefaultCost)
+	if err != nil {
+		a.logger.Error().Str(l.KeyReqID, reqID).Err(err).Msg("")
+		e.ServerError(w, e.RespHashGenerationFailure)
+		return
+	}
+
+	// Sign out first; if it fails the code is still valid to try again
+	if err := a.denylist.RevokeUser(ctx, user.ID.String()); err != nil {
+		a.logger.Error().Str(l.KeyReqID, reqID).Err(err).Msg("")
+		e.ServerError(w, e.RespTokenRevocationFailure)
+		return
+	}
+
+	tx := a.db.TxBegin()
+	if err := tx.UpdateUserAuthPasswordByUserId(user.ID, string(hashedPassword)); err != nil {
+		tx.Rollback()
+		a.logger.Error().Str(l.KeyReqID, reqID).Err(err).Msg("")
+		e.ServerError(w, e.RespDBDataUpdateFailure)
+		return
+	}
+
+	if err := tx.DeleteUserResetPasswordTokenByUserId(user.ID); err != nil {
+		tx.Rollback()
+		a.logger.Error().Str(l.KeyReqID, reqID).Err(err).Msg("")
+		e.ServerError(w, e.RespDBDataDeleteFailure)
+		return
+	}
+
+	if err := tx.RevokeRefreshTokensByUserId(user.ID); err != nil {
+		tx.Rollback()
+		a.logger.Error().Str(l.KeyReqID, reqID).Err(err).Msg("")
+		e.ServerError(w, e.RespDBDataUpdateFailure)
+		return
+	}
+	tx.Commit()
+
+	a.resetAttempts(ctx, reqID, attempts.ActionResetPassword, account)
+	a.resetAttempts(ctx, reqID, attempts.ActionForgotPassword, account)
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Refresh godoc
+// @summary Refresh tokens
+// @description Get a new pair of access and refresh tokens by using a refresh token. Each refresh token can be used only once;
+// @description using one again revokes all the refresh tokens rotated from the same sign-in, which then has to be repeated.
+// @tags users
+//
+// @router /users/refresh [POST]
+// @accept json
+// @produce json
+// @param body body FormRefresh true "Refresh Form"
+//
+// @success 200 {object} RespTokens
+// @failure 400 {object} e.Error
+// @failure 400 {object} e.ValidationErrors
+// @failure 401 {object} e.Error
+// @failure 500 {object} e.Error
+func (a *API) Refresh(w http.ResponseWriter, r *http.Request) {
+	reqID := ctxutil.RequestID(r.Context())
+
+	form := &FormRefresh{}
+	if e.JSONBindAndValidateErrorHandled(w, r, a.logger, a.validator, form, reqID) {
+		return
+	}
+
+	claims, err := jwtutil.ClaimsFromRefreshToken(form.Refresh)
+	if err != nil {
+		e.Unauthorized(w, e.RespTokenInvalid)
+		return
+	}
+
+	refreshToken, err := a.db.ReadRefreshTokenById(claims.ID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			e.Unauthorized(w, e.RespTokenInvalid)
+			return
+		}
+
+		a.logger.Error().Str(l.KeyReqID, reqID).Err(err).Msg("")
+		e.ServerError(w, e.RespDBDataAccessFailure)
+		return
+	}
+
+	if refreshToken.UserID.String() != claims.Subject || refreshToken.RevokedAt != nil {
+		e.Unauthorized(w, e.RespTokenInvalid)
+		return
+	}
+
+	used, err := a.db.UseRefreshTokenById(refreshToken.ID)
+	if err != nil {
+		a.logger.Error().Str(l.KeyReqID, reqID).Err(err).Msg("")
+		e.ServerError(w, e.RespDBDataUpdateFailure)
+		return
+	}
+
+	// A used token is presented again; it may have been stolen, so neither party can keep the session
+	if !used {
+		if err := a.db.RevokeRefreshTokensByFamilyId(refreshToken.FamilyID); err != nil {
+			a.logger.Error().Str(l.KeyReqID, reqID).Err(err).Msg("")
+			e.ServerError(w, e.RespDBDataUpdateFailure)
+			return
+		}
+
+		a.logger.Warn().Str(l.KeyReqID, reqID).Str("user_id", claims.Subject).Msg("refresh token reused; token family revoked")
+		e.Unauthorized(w, e.RespTokenReused)
+		return
+	}
+
+	tokens, err := a.newTokens(refreshToken.UserID, claims.UserEmail, refreshToken.FamilyID)
+	if err != nil {
+		a.logger.Error().Str(l.KeyReqID, reqID).Err(err).Msg("")
+		e.ServerError(w, e.RespJWTTokenGenerationFailure)
+		return
+	}
+
+	if err := json.NewEncoder(w).Encode(tokens); err != nil {
+		a.logger.Error().Str(l.KeyReqID, reqID).Err(err).Msg("")
+		e.ServerError(w, e.RespJSONEncodeFailure)
+		return
+	}
+}
+
+// SignOut godoc
+// @summary User signout
+// @description Sign out of the current session; the access token is revoked and the refresh tokens of the same sign-in can't be used anymore.
+// @tags users
+//
+// @router /users/sign-out [POST]
+// @produce json
+// @security BearerToken
+//
+// @success 204 "No Content"
+// @failure 401 {object} e.Error
+// @failure 500 {object} e.Error
+func (a *API) SignOut(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	reqID, ctxUser := ctxutil.RequestID(ctx), ctxutil.UserFromCtx(ctx)
+
+	// Revoke the refresh tokens first, so no new access token can be issued for the session
+	if ctxUser.SessionID != nil {
+		if err := a.db.RevokeRefreshTokensByFamilyId(*ctxUser.SessionID); err != nil {
+			a.logger.Error().Str(l.KeyReqID, reqID).Err(err).Msg("")
+			e.ServerError(w, e.RespDBDataUpdateFailure)
+			return
+		}
+	}
+
+	if err := a.denylist.RevokeToken(ctx, ctxUser.ID.String(), ctxUser.TokenID, ctxUser.TokenExpiresAt); err != nil {
+		a.logger.Error().Str(l.KeyReqID, reqID).Err(err).Msg("")
+		e.ServerError(w, e.RespTokenRevocationFailure)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// SignOutAll godoc
+// @summary User signout from all sessions
+// @description Sign out of all the sessions of current user; all the access and refresh tokens issued so far are revoked.
+// @tags users
+//
+// @router /users/sign-out-all [POST]
+// @produce json
+// @security BearerToken
+//
+// @success 204 "No Content"
+// @failure 401 {object} e.Error
+// @failure 500 {object} e.Error
+func (a *API) SignOutAll(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	reqID, ctxUser := ctxutil.RequestID(ctx), ctxutil.UserFromCtx(ctx)
+
+	if err := a.db.RevokeRefreshTokensByUserId(*ctxUser.ID); err != nil {
+		a.logger.Error().Str(l.KeyReqID, reqID).Err(err).Msg("")
+		e.ServerError(w, e.RespDBDataUpdateFailure)
+		return
+	}
+
+	if err := a.denylist.RevokeUser(ctx, ctxUser.ID.String()); err != nil {
+		a.logger.Error().Str(l.KeyReqID, reqID).Err(err).Msg("")
+		e.ServerError(w, e.RespTokenRevocationFailure)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GetPreferences godoc
+// @summary Get the preferences of current user
+// @description Get the notification preferences of current user; upload summary emails are on by default.
+// @tags users
+//
+// @router /users/me/preferences [GET]
+// @produce json
+// @security BearerToken
+//
+// @success 200 {object} model.UserPreferenceDTO
+// @failure 401 {object} e.Error
+// @failure 500 {object} e.Error
+func (a *API) GetPreferences(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	reqID, ctxUser := ctxutil.RequestID(ctx), ctxutil.UserFromCtx(ctx)
+
+	pref, err := a.db.ReadUserPreferenceByUserId(*ctxUser.ID)
+	if err != nil {
+		a.logger.Error().Str(l.KeyReqID, reqID).Err(err).Msg("")
+		e.ServerError(w, e.RespDBDataAccessFailure)
+		return
+	}
+
+	if err := json.NewEncoder(w).Encode(pref.ToDTO()); err != nil {
+		a.logger.Error().Str(l.KeyReqID, reqID).Err(err).Msg("")
+		e.ServerError(w, e.RespJSONEncodeFailure)
+		return
+	}
+}
+
+// UpdatePreferences godoc
+// @summary Update the preferences of current user
+// @description Turn the summary email sent once all the keywords of an upload are finished on or off.
+// @tags users
+//
+// @router /users/me/preferences [PUT]
+// @accept json
+// @produce json
+// @security BearerToken
+// @param body body FormPreferences true "Preferences Form"
+//
+// @success 200 {object} model.UserPreferenceDTO
+// @failure 400 {object} e.Error
+// @failure 400 {object} e.ValidationErrors
+// @failure 401 {object} e.Error
+// @failure 500 {object} e.Error
+func (a *API) UpdatePreferences(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	reqID, ctxUser := ctxutil.RequestID(ctx), ctxutil.UserFromCtx(ctx)
+
+	form := &FormPreferences{}
+	if e.JSONBindAndValidateErrorHandled(w, r, a.logger, a.validator, form, reqID) {
+		return
+	}
+
+	pref := model.NewUserPreference(*ctxUser.ID)
+	pref.UploadSummaryEmail = *form.UploadSummaryEmail
+
+	if err := a.db.CreateOrUpdateUserPreference(pref); err != nil {
+		a.logger.Error().Str(l.KeyReqID, reqID).Err(err).Msg("")
+		e.ServerError(w, e.RespDBDataUpdateFailure)
+		return
+	}
+
+	if err := json.NewEncoder(w).Encode(pref.ToDTO()); err != nil {
+		a.logger.Error().Str(l.KeyReqID, reqID).Err(err).Msg("")
+		e.ServerError(w, e.RespJSONEncodeFailure)
+		return
+	}
+}
+
+// newTokens issues a pair of tokens and keeps track of the refresh token in the given family.
+func (a *API) newTokens(userID uuid.UUID, userEmail string, familyID uuid.UUID) (*RespTokens, error) {
+	tokens, err := jwtutil.NewAccessTokenAndRefreshToken(userID.String(), userEmail, familyID.String())
+	if err != nil {
+		return nil, err
+	}
+
+	refreshToken := model.NewRefreshToken(tokens.RefreshID, familyID, userID, tokens.RefreshExpiresAt)
+	if err := a.db.CreateRefreshToken(refreshToken); err != nil {
+		return nil, err
+	}
+
+	return &RespTokens{Access: tokens.Access, Refresh: tokens.Refresh}, nil
+}
+
+// lockedActions are what the lockout email says the failed attempts were to do.
+var lockedActions = map[string]string{
+	attempts.ActionSignIn:        "sign in to",
+	attempts.ActionActivate:      "activate",
+	attempts.ActionResetPassword: "reset the password of",
+}
+
+// attemptsWaitHandled rejects the attempt if the account or the IP address has to wait after its failed attempts.
+func (a *API) attemptsWaitHandled(w http.ResponseWriter, r *http.Request, reqID, action, account string) bool {
+	wait, err := a.attempts.Wait(r.Context(), action, account, clientIP(r))
+	if err != nil {
+		a.logger.Error().Str(l.KeyReqID, reqID).Err(err).Msg("")
+		e.ServerError(w, e.RespAttemptsCheckFailure)
+		return true
+	}
+
+	if wait > 0 {
+		w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+		e.TooManyRequests(w, e.RespTooManyAttempts)
+		return true
+	}
+
+	return false
+}
+
+// failAttempt counts a failed attempt and tells the owner when it locks the account. The attempt has already
+// failed, so not being able to count it is only logged.
+func (a *API) failAttempt(r *http.Request, reqID, action, account string, user *model.User) *attempts.Failure {
+	failure, err := a.attempts.Fail(r.Context(), action, account, clientIP(r))
+	if err != nil {
+		a.logger.Error().Str(l.KeyReqID, reqID).Err(err).Msg("")
+		return nil
+	}
+
+	if failure.Locked {
+		a.logger.Warn().Str(l.KeyReqID, reqID).Str("action", action).Str("account", account).Msg("account locked after failed attempts")
+
+		if user != nil {
+			lockedUntil := time.Now().Add(a.attempts.Policy().LockoutDuration)
+			go func() {
+				if err := a.mailer.LockoutMail(user.Email, lockedActions[action], lockedUntil); err != nil {
+					a.logger.Error().Str(l.KeyReqID, reqID).Err(err).Msg("")
+				}
+			}()
+		}
+	}
+
+	return failure
+}
+
+func (a *API) resetAttempts(ctx context.Context, reqID, action, account string) {
+	if err := a.attempts.Reset(ctx, action, account); err != nil {
+		a.logger.Error().Str(l.KeyReqID, reqID).Err(err).Msg("")
+	}
+}
+
+// clientIP is the address the request comes from; the API is not behind a proxy to trust forwarded addresses of.
+func clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+
+	return host
+}
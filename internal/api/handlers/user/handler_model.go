@@ -11,7 +11,26 @@ type FormSignIn struct {
 	Password string `json:"password" validate:"required,min=8"`
 }
 
+type FormForgotPassword struct {
+	Email string `json:"email" validate:"required,email"`
+}
+
+type FormResetPassword struct {
+	Email           string `json:"email" validate:"required,email"`
+	Token           string `json:"token" validate:"required"`
+	Password        string `json:"password" validate:"required,min=8"`
+	ConfirmPassword string `json:"confirmPassword" validate:"required,eqfield=Password"`
+}
+
+type FormRefresh struct {
+	Refresh string `json:"refresh" validate:"required"`
+}
+
 type RespTokens struct {
 	Access  string `json:"access"`
 	Refresh string `json:"refresh"`
 }
+
+type FormPreferences struct {
+	UploadSummaryEmail *bool `json:"uploadSummaryEmail" validate:"required"`
+}
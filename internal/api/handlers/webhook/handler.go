@@ -0,0 +1,447 @@
+package webhook
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"slices"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	v "github.com/go-playground/validator/v10"
+	"github.com/hibiken/asynq"
+	"gorm.io/gorm"
+
+	e "web-scraper.dev/internal/api/errors"
+	"web-scraper.dev/internal/model"
+	"web-scraper.dev/internal/repository"
+	"web-scraper.dev/internal/utils/ctxutil"
+	l "web-scraper.dev/internal/utils/logger"
+	"web-scraper.dev/internal/utils/pageutil"
+	"web-scraper.dev/internal/webhooks"
+)
+
+type API struct {
+	db        *repository.Db
+	logger    *l.Logger
+	validator *v.Validate
+	webhooks  *webhooks.Dispatcher
+}
+
+func New(db *gorm.DB, logger *l.Logger, validator *v.Validate, asyq *asynq.Client) *API {
+	repo := repository.New(db)
+
+	return &API{
+		db:        repo,
+		logger:    logger,
+		validator: validator,
+		webhooks:  webhooks.NewDispatcher(repo, asyq),
+	}
+}
+
+// GetWebhooks godoc
+// @summary Get the list of webhooks
+// @description Get a page of webhooks of current user, latest first
+// @tags webhooks
+//
+// @router /webhooks [GET]
+// @accept json
+// @produce json
+// @security BearerToken
+// @param page query int false "Page number, starts from 1"
+// @param per_page query int false "Number of webhooks per page, max 100"
+//
+// @success 200 {array} model.WebhookDTO
+// @header 200 {integer} X-Total-Count "Total number of webhooks"
+// @failure 401 {object} e.Error
+// @failure 500 {object} e.Error
+func (a *API) GetWebhooks(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	reqID, ctxUser := ctxutil.RequestID(ctx), ctxutil.UserFromCtx(ctx)
+
+	page := pageutil.FromRequest(r)
+	webhooks, total, err := a.db.ListWebhooksByUserId(*ctxUser.ID, page.Offset(), page.Limit())
+	if err != nil {
+		a.logger.Error().Str(l.KeyReqID, reqID).Err(err).Msg("")
+		e.ServerError(w, e.RespDBDataAccessFailure)
+		return
+	}
+
+	pageutil.SetTotalCount(w, total)
+
+	dto := webhooks.ToDTOs()
+	if err := json.NewEncoder(w).Encode(&dto); err != nil {
+		a.logger.Error().Str(l.KeyReqID, reqID).Err(err).Msg("")
+		e.ServerError(w, e.RespJSONEncodeFailure)
+		return
+	}
+}
+
+// CreateWebhook godoc
+// @summary Create a webhook
+// @description Create a webhook receiving the events of current user as signed JSON POST requests; all the events when none are given.
+// @description The response has the secret of the webhook, which is not shown again. Each request has an X-Webhook-Signature header;
+// @description "t=<unix time>,v1=<hex HMAC-SHA256 of "<unix time>.<body>" with the secret>".
+// @tags webhooks
+//
+// @router /webhooks [POST]
+// @accept json
+// @produce json
+// @security BearerToken
+// @param body body FormWebhook true "Webhook form"
+//
+// @success 201 {object} model.WebhookDTO
+// @failure 400 {object} e.Error
+// @failure 400 {object} e.ValidationErrors
+// @failure 401 {object} e.Error
+// @failure 500 {object} e.Error
+func (a *API) CreateWebhook(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	reqID, ctxUser := ctxutil.RequestID(ctx), ctxutil.UserFromCtx(ctx)
+
+	form := &FormWebhook{}
+	if e.JSONBindAndValidateErrorHandled(w, r, a.logger, a.validator, form, reqID) {
+		return
+	}
+
+	if err := webhooks.ValidateURL(ctx, form.URL); err != nil {
+		e.BadRequest(w, e.RespInvalidWebhookURL)
+		return
+	}
+
+	secret, err := webhooks.NewSecret()
+	if err != nil {
+		a.logger.Error().Str(l.KeyReqID, reqID).Err(err).Msg("")
+		e.ServerError(w, e.RespSecretGenerationFailure)
+		return
+	}
+
+	webhook := &model.Webhook{
+		UserID: *ctxUser.ID,
+		URL:    form.URL,
+		Secret: secret,
+		Events: webhookEvents(form.Events),
+		Active: form.Active == nil || *form.Active,
+	}
+
+	if err := a.db.CreateWebhook(webhook); err != nil {
+		a.logger.Error().Str(l.KeyReqID, reqID).Err(err).Msg("")
+		e.ServerError(w, e.RespDBDataInsertFailure)
+		return
+	}
+
+	dto := webhook.ToDTO()
+	dto.Secret = webhook.Secret
+
+	w.WriteHeader(http.StatusCreated)
+	if err := json.NewEncoder(w).Encode(dto); err != nil {
+		a.logger.Error().Str(l.KeyReqID, reqID).Err(err).Msg("")
+		return
+	}
+}
+
+// GetWebhook godoc
+// @summary Get a webhook
+// @description Get a webhook of current user, without its secret
+// @tags webhooks
+//
+// @router /webhooks/{id} [GET]
+// @accept json
+// @produce json
+// @security BearerToken
+// @param id path string true "Webhook ID"
+//
+// @success 200 {object} model.WebhookDTO
+// @failure 400 {object} e.Error
+// @failure 401 {object} e.Error
+// @failure 404
+// @failure 500 {object} e.Error
+func (a *API) GetWebhook(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	reqID := ctxutil.RequestID(ctx)
+
+	webhook, ok := a.readWebhook(w, r)
+	if !ok {
+		return
+	}
+
+	if err := json.NewEncoder(w).Encode(webhook.ToDTO()); err != nil {
+		a.logger.Error().Str(l.KeyReqID, reqID).Err(err).Msg("")
+		e.ServerError(w, e.RespJSONEncodeFailure)
+		return
+	}
+}
+
+// UpdateWebhook godoc
+// @summary Update a webhook
+// @description Update the URL, the events and the state of a webhook of current user; all the events when none are given. The secret is kept.
+// @tags webhooks
+//
+// @router /webhooks/{id} [PUT]
+// @accept json
+// @produce json
+// @security BearerToken
+// @param id path string true "Webhook ID"
+// @param body body FormWebhook true "Webhook form"
+//
+// @success 200 {object} model.WebhookDTO
+// @failure 400 {object} e.Error
+// @failure 400 {object} e.ValidationErrors
+// @failure 401 {object} e.Error
+// @failure 404
+// @failure 500 {object} e.Error
+func (a *API) UpdateWebhook(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	reqID, ctxUser := ctxutil.RequestID(ctx), ctxutil.UserFromCtx(ctx)
+
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil || id < 1 {
+		e.BadRequest(w, e.RespInvalidID)
+		return
+	}
+
+	form := &FormWebhook{}
+	if e.JSONBindAndValidateErrorHandled(w, r, a.logger, a.validator, form, reqID) {
+		return
+	}
+
+	if err := webhooks.ValidateURL(ctx, form.URL); err != nil {
+		e.BadRequest(w, e.RespInvalidWebhookURL)
+		return
+	}
+
+	updates := map[string]any{
+		"url":    form.URL,
+		"events": webhookEvents(form.Events),
+		"active": form.Active == nil || *form.Active,
+	}
+
+	rowsAffected, err := a.db.UpdateWebhookByIdAndUserId(int64(id), *ctxUser.ID, updates)
+	if err != nil {
+		a.logger.Error().Str(l.KeyReqID, reqID).Err(err).Msg("")
+		e.ServerError(w, e.RespDBDataUpdateFailure)
+		return
+	}
+
+	if rowsAffected == 0 {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	webhook, err := a.db.ReadWebhookByIdAndUserId(int64(id), *ctxUser.ID)
+	if err != nil {
+		a.logger.Error().Str(l.KeyReqID, reqID).Err(err).Msg("")
+		e.ServerError(w, e.RespDBDataAccessFailure)
+		return
+	}
+
+	if err := json.NewEncoder(w).Encode(webhook.ToDTO()); err != nil {
+		a.logger.Error().Str(l.KeyReqID, reqID).Err(err).Msg("")
+		e.ServerError(w, e.RespJSONEncodeFailure)
+		return
+	}
+}
+
+// DeleteWebhook godoc
+// @summary Delete a webhook
+// @description Delete a webhook of current user with its delivery log; its pending deliveries are dropped
+// @tags webhooks
+//
+// @router /webhooks/{id} [DELETE]
+// @accept json
+// @produce json
+// @security BearerToken
+// @param id path string true "Webhook ID"
+//
+// @success 204
+// @failure 400 {object} e.Error
+// @failure 401 {object} e.Error
+// @failure 404
+// @failure 500 {object} e.Error
+func (a *API) DeleteWebhook(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	reqID, ctxUser := ctxutil.RequestID(ctx), ctxutil.UserFromCtx(ctx)
+
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil || id < 1 {
+		e.BadRequest(w, e.RespInvalidID)
+		return
+	}
+
+	tx := a.db.TxBegin()
+	rowsAffected, err := tx.DeleteWebhookByIdAndUserId(int64(id), *ctxUser.ID)
+	if err != nil {
+		tx.Rollback()
+		a.logger.Error().Str(l.KeyReqID, reqID).Err(err).Msg("")
+		e.ServerError(w, e.RespDBDataDeleteFailure)
+		return
+	}
+
+	if rowsAffected == 0 {
+		tx.Rollback()
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	if err := tx.DeleteWebhookDeliveriesByWebhookId(int64(id)); err != nil {
+		tx.Rollback()
+		a.logger.Error().Str(l.KeyReqID, reqID).Err(err).Msg("")
+		e.ServerError(w, e.RespDBDataDeleteFailure)
+		return
+	}
+	tx.Commit()
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GetWebhookDeliveries godoc
+// @summary Get the delivery log of a webhook
+// @description Get a page of the deliveries of a webhook of current user with their payloads and last responses, latest first
+// @tags webhooks
+//
+// @router /webhooks/{id}/deliveries [GET]
+// @accept json
+// @produce json
+// @security BearerToken
+// @param id path string true "Webhook ID"
+// @param page query int false "Page number, starts from 1"
+// @param per_page query int false "Number of deliveries per page, max 100"
+//
+// @success 200 {array} model.WebhookDeliveryDTO
+// @header 200 {integer} X-Total-Count "Total number of deliveries"
+// @failure 400 {object} e.Error
+// @failure 401 {object} e.Error
+// @failure 404
+// @failure 500 {object} e.Error
+func (a *API) GetWebhookDeliveries(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	reqID := ctxutil.RequestID(ctx)
+
+	webhook, ok := a.readWebhook(w, r)
+	if !ok {
+		return
+	}
+
+	page := pageutil.FromRequest(r)
+	deliveries, total, err := a.db.ListWebhookDeliveriesByWebhookId(webhook.ID, page.Offset(), page.Limit())
+	if err != nil {
+		a.logger.Error().Str(l.KeyReqID, reqID).Err(err).Msg("")
+		e.ServerError(w, e.RespDBDataAccessFailure)
+		return
+	}
+
+	pageutil.SetTotalCount(w, total)
+
+	dto := deliveries.ToDTOs()
+	if err := json.NewEncoder(w).Encode(&dto); err != nil {
+		a.logger.Error().Str(l.KeyReqID, reqID).Err(err).Msg("")
+		e.ServerError(w, e.RespJSONEncodeFailure)
+		return
+	}
+}
+
+// RedeliverWebhookDelivery godoc
+// @summary Redeliver a webhook delivery
+// @description Send the event of a delivery of a webhook of current user again, as a new delivery with the same event ID
+// @tags webhooks
+//
+// @router /webhooks/{id}/deliveries/{deliveryId}/redeliver [POST]
+// @accept json
+// @produce json
+// @security BearerToken
+// @param id path string true "Webhook ID"
+// @param deliveryId path string true "Delivery ID"
+//
+// @success 202 {object} model.WebhookDeliveryDTO
+// @failure 400 {object} e.Error
+// @failure 401 {object} e.Error
+// @failure 404
+// @failure 409 {object} e.Error
+// @failure 500 {object} e.Error
+func (a *API) RedeliverWebhookDelivery(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	reqID := ctxutil.RequestID(ctx)
+
+	deliveryID, err := strconv.Atoi(chi.URLParam(r, "deliveryId"))
+	if err != nil || deliveryID < 1 {
+		e.BadRequest(w, e.RespInvalidID)
+		return
+	}
+
+	webhook, ok := a.readWebhook(w, r)
+	if !ok {
+		return
+	}
+
+	if !webhook.Active {
+		e.Conflict(w, e.RespWebhookInactive)
+		return
+	}
+
+	delivery, err := a.db.ReadWebhookDeliveryByIdAndWebhookId(int64(deliveryID), webhook.ID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		a.logger.Error().Str(l.KeyReqID, reqID).Err(err).Msg("")
+		e.ServerError(w, e.RespDBDataAccessFailure)
+		return
+	}
+
+	redelivery, err := a.webhooks.Redeliver(delivery)
+	if err != nil {
+		a.logger.Error().Str(l.KeyReqID, reqID).Err(err).Msg("")
+		e.ServerError(w, e.RespTaskEnqueueFailure)
+		return
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+	if err := json.NewEncoder(w).Encode(redelivery.ToDTO()); err != nil {
+		a.logger.Error().Str(l.KeyReqID, reqID).Err(err).Msg("")
+		return
+	}
+}
+
+// readWebhook reads the webhook of the id URL param owned by current user, or writes the error response.
+func (a *API) readWebhook(w http.ResponseWriter, r *http.Request) (*model.Webhook, bool) {
+	ctx := r.Context()
+	reqID, ctxUser := ctxutil.RequestID(ctx), ctxutil.UserFromCtx(ctx)
+
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil || id < 1 {
+		e.BadRequest(w, e.RespInvalidID)
+		return nil, false
+	}
+
+	webhook, err := a.db.ReadWebhookByIdAndUserId(int64(id), *ctxUser.ID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			w.WriteHeader(http.StatusNotFound)
+			return nil, false
+		}
+
+		a.logger.Error().Str(l.KeyReqID, reqID).Err(err).Msg("")
+		e.ServerError(w, e.RespDBDataAccessFailure)
+		return nil, false
+	}
+
+	return webhook, true
+}
+
+// webhookEvents returns the given event types without duplicates, in the order of model.WebhookEvents; all of them when none are given.
+func webhookEvents(events []string) []string {
+	if len(events) == 0 {
+		return model.WebhookEvents
+	}
+
+	result := make([]string, 0, len(events))
+	for _, v := range model.WebhookEvents {
+		if slices.Contains(events, v) {
+			result = append(result, v)
+		}
+	}
+
+	return result
+}
@@ -0,0 +1,7 @@
+package webhook
+
+type FormWebhook struct {
+	URL    string   `json:"url" validate:"required,http_url,max=2048"`
+	Events []string `json:"events" validate:"max=3,dive,oneof=keyword.completed keyword.failed upload.completed"`
+	Active *bool    `json:"active"`
+}
@@ -0,0 +1,681 @@
+package workspace
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	v "github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	e "web-scraper.dev/internal/api/errors"
+	"web-scraper.dev/internal/mailer"
+	"web-scraper.dev/internal/model"
+	"web-scraper.dev/internal/repository"
+	"web-scraper.dev/internal/utils/ctxutil"
+	l "web-scraper.dev/internal/utils/logger"
+)
+
+type API struct {
+	db        *repository.Db
+	mailer    *mailer.Mailer
+	logger    *l.Logger
+	validator *v.Validate
+}
+
+func New(db *gorm.DB, mailer *mailer.Mailer, logger *l.Logger, validator *v.Validate) *API {
+	return &API{
+		db:        repository.New(db),
+		mailer:    mailer,
+		logger:    logger,
+		validator: validator,
+	}
+}
+
+// GetWorkspaces godoc
+// @summary Get the list of workspaces
+// @description Get the workspaces current user is a member of, with the role in each; the personal workspace first.
+// @description The keyword and upload endpoints use the workspace in the "X-Workspace-ID" header, or the personal workspace without it.
+// @tags workspaces
+//
+// @router /workspaces [GET]
+// @accept json
+// @produce json
+// @security BearerToken
+//
+// @success 200 {array} model.WorkspaceDTO
+// @failure 401 {object} e.Error
+// @failure 403 {object} e.Error
+// @failure 500 {object} e.Error
+func (a *API) GetWorkspaces(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	reqID, ctxUser := ctxutil.RequestID(ctx), ctxutil.UserFromCtx(ctx)
+
+	members, err := a.db.ListWorkspaceMembersByUserId(*ctxUser.ID)
+	if err != nil {
+		a.logger.Error().Str(l.KeyReqID, reqID).Err(err).Msg("")
+		e.ServerError(w, e.RespDBDataAccessFailure)
+		return
+	}
+
+	dto := members.ToWorkspaceDTOs()
+	if err := json.NewEncoder(w).Encode(&dto); err != nil {
+		a.logger.Error().Str(l.KeyReqID, reqID).Err(err).Msg("")
+		e.ServerError(w, e.RespJSONEncodeFailure)
+		return
+	}
+}
+
+// CreateWorkspace godoc
+// @summary Create a workspace
+// @description Create a shared workspace with current user as its owner
+// @tags workspaces
+//
+// @router /workspaces [POST]
+// @accept json
+// @produce json
+// @security BearerToken
+// @param body body FormWorkspace true "Workspace form"
+//
+// @success 201 {object} model.WorkspaceDTO
+// @failure 400 {object} e.Error
+// @failure 400 {object} e.ValidationErrors
+// @failure 401 {object} e.Error
+// @failure 403 {object} e.Error
+// @failure 500 {object} e.Error
+func (a *API) CreateWorkspace(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	reqID, ctxUser := ctxutil.RequestID(ctx), ctxutil.UserFromCtx(ctx)
+
+	form := &FormWorkspace{}
+	if e.JSONBindAndValidateErrorHandled(w, r, a.logger, a.validator, form, reqID) {
+		return
+	}
+
+	workspace := model.NewWorkspace(form.Name)
+	member := model.NewWorkspaceMember(workspace.ID, *ctxUser.ID, model.WorkspaceRoleOwner)
+
+	tx := a.db.TxBegin()
+	if err := tx.CreateWorkspace(workspace); err != nil {
+		tx.Rollback()
+		a.logger.Error().Str(l.KeyReqID, reqID).Err(err).Msg("")
+		e.ServerError(w, e.RespDBDataInsertFailure)
+		return
+	}
+
+	if err := tx.CreateWorkspaceMember(member); err != nil {
+		tx.Rollback()
+		a.logger.Error().Str(l.KeyReqID, reqID).Err(err).Msg("")
+		e.ServerError(w, e.RespDBDataInsertFailure)
+		return
+	}
+	tx.Commit()
+
+	member.Workspace = workspace
+
+	w.WriteHeader(http.StatusCreated)
+	if err := json.NewEncoder(w).Encode(member.ToWorkspaceDTO()); err != nil {
+		a.logger.Error().Str(l.KeyReqID, reqID).Err(err).Msg("")
+		return
+	}
+}
+
+// GetWorkspace godoc
+// @summary Get a workspace
+// @description Get a workspace of current user, with the role in it
+// @tags workspaces
+//
+// @router /workspaces/{id} [GET]
+// @accept json
+// @produce json
+// @security BearerToken
+// @param id path string true "Workspace ID"
+//
+// @success 200 {object} model.WorkspaceDTO
+// @failure 400 {object} e.Error
+// @failure 401 {object} e.Error
+// @failure 403 {object} e.Error
+// @failure 404
+// @failure 500 {object} e.Error
+func (a *API) GetWorkspace(w http.ResponseWriter, r *http.Request) {
+	reqID := ctxutil.RequestID(r.Context())
+
+	member, handled := a.readMemberErrorHandled(w, r, reqID, model.WorkspaceRoleViewer)
+	if handled {
+		return
+	}
+
+	if err := json.NewEncoder(w).Encode(member.ToWorkspaceDTO()); err != nil {
+		a.logger.Error().Str(l.KeyReqID, reqID).Err(err).Msg("")
+		e.ServerError(w, e.RespJSONEncodeFailure)
+		return
+	}
+}
+
+// UpdateWorkspace godoc
+// @summary Update a workspace
+// @description Rename a workspace; owners only
+// @tags workspaces
+//
+// @router /workspaces/{id} [PUT]
+// @accept json
+// @produce json
+// @security BearerToken
+// @param id path string true "Workspace ID"
+// @param body body FormWorkspace true "Workspace form"
+//
+// @success 200 {object} model.WorkspaceDTO
+// @failure 400 {object} e.Error
+// @failure 400 {object} e.ValidationErrors
+// @failure 401 {object} e.Error
+// @failure 403 {object} e.Error
+// @failure 404
+// @failure 500 {object} e.Error
+func (a *API) UpdateWorkspace(w http.ResponseWriter, r *http.Request) {
+	reqID := ctxutil.RequestID(r.Context())
+
+	member, handled := a.readMemberErrorHandled(w, r, reqID, model.WorkspaceRoleOwner)
+	if handled {
+		return
+	}
+
+	form := &FormWorkspace{}
+	if e.JSONBindAndValidateErrorHandled(w, r, a.logger, a.validator, form, reqID) {
+		return
+	}
+
+	if err := a.db.UpdateWorkspaceNameById(member.WorkspaceID, form.Name); err != nil {
+		a.logger.Error().Str(l.KeyReqID, reqID).Err(err).Msg("")
+		e.ServerError(w, e.RespDBDataUpdateFailure)
+		return
+	}
+
+	member.Workspace.Name = form.Name
+	if err := json.NewEncoder(w).Encode(member.ToWorkspaceDTO()); err != nil {
+		a.logger.Error().Str(l.KeyReqID, reqID).Err(err).Msg("")
+		e.ServerError(w, e.RespJSONEncodeFailure)
+		return
+	}
+}
+
+// GetMembers godoc
+// @summary Get the members of a workspace
+// @description Get the members of a workspace with their emails and roles, earliest first
+// @tags workspaces
+//
+// @router /workspaces/{id}/members [GET]
+// @accept json
+// @produce json
+// @security BearerToken
+// @param id path string true "Workspace ID"
+//
+// @success 200 {array} model.WorkspaceMemberDTO
+// @failure 400 {object} e.Error
+// @failure 401 {object} e.Error
+// @failure 403 {object} e.Error
+// @failure 404
+// @failure 500 {object} e.Error
+func (a *API) GetMembers(w http.ResponseWriter, r *http.Request) {
+	reqID := ctxutil.RequestID(r.Context())
+
+	member, handled := a.readMemberErrorHandled(w, r, reqID, model.WorkspaceRoleViewer)
+	if handled {
+		return
+	}
+
+	members, err := a.db.ListWorkspaceMembersByWorkspaceId(member.WorkspaceID)
+	if err != nil {
+		a.logger.Error().Str(l.KeyReqID, reqID).Err(err).Msg("")
+		e.ServerError(w, e.RespDBDataAccessFailure)
+		return
+	}
+
+	dto := members.ToDTOs()
+	if err := json.NewEncoder(w).Encode(&dto); err != nil {
+		a.logger.Error().Str(l.KeyReqID, reqID).Err(err).Msg("")
+		e.ServerError(w, e.RespJSONEncodeFailure)
+		return
+	}
+}
+
+// UpdateMember godoc
+// @summary Update a member of a workspace
+// @description Change the role of a member; owners only. A workspace always keeps an owner,
+// @description and the owner of a personal workspace can't be changed.
+// @tags workspaces
+//
+// @router /workspaces/{id}/members/{userId} [PUT]
+// @accept json
+// @produce json
+// @security BearerToken
+// @param id path string true "Workspace ID"
+// @param userId path string true "User ID of the member"
+// @param body body FormMember true "Member form"
+//
+// @success 200 {object} model.WorkspaceMemberDTO
+// @failure 400 {object} e.Error
+// @failure 400 {object} e.ValidationErrors
+// @failure 401 {object} e.Error
+// @failure 403 {object} e.Error
+// @failure 404
+// @failure 409 {object} e.Error
+// @failure 500 {object} e.Error
+func (a *API) UpdateMember(w http.ResponseWriter, r *http.Request) {
+	reqID := ctxutil.RequestID(r.Context())
+
+	member, handled := a.readMemberErrorHandled(w, r, reqID, model.WorkspaceRoleOwner)
+	if handled {
+		return
+	}
+
+	userID, err := uuid.Parse(chi.URLParam(r, "userId"))
+	if err != nil {
+		e.BadRequest(w, e.RespInvalidID)
+		return
+	}
+
+	form := &FormMember{}
+	if e.JSONBindAndValidateErrorHandled(w, r, a.logger, a.validator, form, reqID) {
+		return
+	}
+
+	tx := a.db.TxBegin()
+	target, handled := a.readTargetMemberErrorHandled(w, tx, reqID, member.WorkspaceID, userID, form.Role)
+	if handled {
+		tx.Rollback()
+		return
+	}
+
+	if _, err := tx.UpdateWorkspaceMemberRoleByWorkspaceIdAndUserId(member.WorkspaceID, userID, form.Role); err != nil {
+		tx.Rollback()
+		a.logger.Error().Str(l.KeyReqID, reqID).Err(err).Msg("")
+		e.ServerError(w, e.RespDBDataUpdateFailure)
+		return
+	}
+	tx.Commit()
+
+	target.Role = form.Role
+	if target.User, err = a.db.ReadUserById(userID); err != nil {
+		a.logger.Error().Str(l.KeyReqID, reqID).Err(err).Msg("")
+		e.ServerError(w, e.RespDBDataAccessFailure)
+		return
+	}
+
+	if err := json.NewEncoder(w).Encode(target.ToDTO()); err != nil {
+		a.logger.Error().Str(l.KeyReqID, reqID).Err(err).Msg("")
+		e.ServerError(w, e.RespJSONEncodeFailure)
+		return
+	}
+}
+
+// DeleteMember godoc
+// @summary Remove a member from a workspace
+// @description Remove a member from a workspace; owners only, while any member can leave by removing themselves.
+// @description A workspace always keeps an owner, and the owner of a personal workspace can't leave it.
+// @tags workspaces
+//
+// @router /workspaces/{id}/members/{userId} [DELETE]
+// @accept json
+// @produce json
+// @security BearerToken
+// @param id path string true "Workspace ID"
+// @param userId path string true "User ID of the member"
+//
+// @success 204
+// @failure 400 {object} e.Error
+// @failure 401 {object} e.Error
+// @failure 403 {object} e.Error
+// @failure 404
+// @failure 409 {object} e.Error
+// @failure 500 {object} e.Error
+func (a *API) DeleteMember(w http.ResponseWriter, r *http.Request) {
+	reqID := ctxutil.RequestID(r.Context())
+
+	member, handled := a.readMemberErrorHandled(w, r, reqID, model.WorkspaceRoleViewer)
+	if handled {
+		return
+	}
+
+	userID, err := uuid.Parse(chi.URLParam(r, "userId"))
+	if err != nil {
+		e.BadRequest(w, e.RespInvalidID)
+		return
+	}
+
+	if userID != member.UserID && member.Role != model.WorkspaceRoleOwner {
+		e.Forbidden(w, e.RespInsufficientRole)
+		return
+	}
+
+	tx := a.db.TxBegin()
+	if _, handled := a.readTargetMemberErrorHandled(w, tx, reqID, member.WorkspaceID, userID, ""); handled {
+		tx.Rollback()
+		return
+	}
+
+	if _, err := tx.DeleteWorkspaceMemberByWorkspaceIdAndUserId(member.WorkspaceID, userID); err != nil {
+		tx.Rollback()
+		a.logger.Error().Str(l.KeyReqID, reqID).Err(err).Msg("")
+		e.ServerError(w, e.RespDBDataDeleteFailure)
+		return
+	}
+	tx.Commit()
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GetInvitations godoc
+// @summary Get the invitations of a workspace
+// @description Get the invitations of a workspace neither accepted nor expired, latest first; owners only
+// @tags workspaces
+//
+// @router /workspaces/{id}/invitations [GET]
+// @accept json
+// @produce json
+// @security BearerToken
+// @param id path string true "Workspace ID"
+//
+// @success 200 {array} model.WorkspaceInvitationDTO
+// @failure 400 {object} e.Error
+// @failure 401 {object} e.Error
+// @failure 403 {object} e.Error
+// @failure 404
+// @failure 500 {object} e.Error
+func (a *API) GetInvitations(w http.ResponseWriter, r *http.Request) {
+	reqID := ctxutil.RequestID(r.Context())
+
+	member, handled := a.readMemberErrorHandled(w, r, reqID, model.WorkspaceRoleOwner)
+	if handled {
+		return
+	}
+
+	invitations, err := a.db.ListPendingWorkspaceInvitationsByWorkspaceId(member.WorkspaceID)
+	if err != nil {
+		a.logger.Error().Str(l.KeyReqID, reqID).Err(err).Msg("")
+		e.ServerError(w, e.RespDBDataAccessFailure)
+		return
+	}
+
+	dto := invitations.ToDTOs()
+	if err := json.NewEncoder(w).Encode(&dto); err != nil {
+		a.logger.Error().Str(l.KeyReqID, reqID).Err(err).Msg("")
+		e.ServerError(w, e.RespJSONEncodeFailure)
+		return
+	}
+}
+
+// CreateInvitation godoc
+// @summary Invite to a workspace
+// @description Invite an email to a workspace with a role; owners only.
+// @description This will send an invitation email with a link, which expires in 7 days.
+// @tags workspaces
+//
+// @router /workspaces/{id}/invitations [POST]
+// @accept json
+// @produce json
+// @security BearerToken
+// @param id path string true "Workspace ID"
+// @param body body FormInvitation true "Invitation form"
+//
+// @success 201 {object} model.WorkspaceInvitationDTO
+// @failure 400 {object} e.Error
+// @failure 400 {object} e.ValidationErrors
+// @failure 401 {object} e.Error
+// @failure 403 {object} e.Error
+// @failure 404
+// @failure 500 {object} e.Error
+func (a *API) CreateInvitation(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	reqID, ctxUser := ctxutil.RequestID(ctx), ctxutil.UserFromCtx(ctx)
+
+	member, handled := a.readMemberErrorHandled(w, r, reqID, model.WorkspaceRoleOwner)
+	if handled {
+		return
+	}
+
+	form := &FormInvitation{}
+	if e.JSONBindAndValidateErrorHandled(w, r, a.logger, a.validator, form, reqID) {
+		return
+	}
+
+	form.Email = strings.ToLower(form.Email)
+
+	invitation := model.NewWorkspaceInvitation(member.WorkspaceID, form.Email, form.Role, member.UserID)
+	if err := a.db.CreateWorkspaceInvitation(invitation); err != nil {
+		a.logger.Error().Str(l.KeyReqID, reqID).Err(err).Msg("")
+		e.ServerError(w, e.RespDBDataInsertFailure)
+		return
+	}
+
+	if err := a.mailer.WorkspaceInvitationMail(form.Email, member.Workspace.Name, ctxUser.Email, form.Role, invitation.Token); err != nil {
+		a.logger.Error().Str(l.KeyReqID, reqID).Err(err).Msg("")
+		e.ServerError(w, e.RespEmailSendingFailure)
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	if err := json.NewEncoder(w).Encode(invitation.ToDTO()); err != nil {
+		a.logger.Error().Str(l.KeyReqID, reqID).Err(err).Msg("")
+		return
+	}
+}
+
+// DeleteInvitation godoc
+// @summary Delete an invitation to a workspace
+// @description Delete an invitation to a workspace, so it can't be accepted; owners only
+// @tags workspaces
+//
+// @router /workspaces/{id}/invitations/{invitationId} [DELETE]
+// @accept json
+// @produce json
+// @security BearerToken
+// @param id path string true "Workspace ID"
+// @param invitationId path string true "Invitation ID"
+//
+// @success 204
+// @failure 400 {object} e.Error
+// @failure 401 {object} e.Error
+// @failure 403 {object} e.Error
+// @failure 404
+// @failure 500 {object} e.Error
+func (a *API) DeleteInvitation(w http.ResponseWriter, r *http.Request) {
+	reqID := ctxutil.RequestID(r.Context())
+
+	member, handled := a.readMemberErrorHandled(w, r, reqID, model.WorkspaceRoleOwner)
+	if handled {
+		return
+	}
+
+	id, err := strconv.Atoi(chi.URLParam(r, "invitationId"))
+	if err != nil || id < 1 {
+		e.BadRequest(w, e.RespInvalidID)
+		return
+	}
+
+	rowsAffected, err := a.db.DeleteWorkspaceInvitationByIdAndWorkspaceId(int64(id), member.WorkspaceID)
+	if err != nil {
+		a.logger.Error().Str(l.KeyReqID, reqID).Err(err).Msg("")
+		e.ServerError(w, e.RespDBDataDeleteFailure)
+		return
+	}
+
+	if rowsAffected == 0 {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// AcceptInvitation godoc
+// @summary Accept an invitation to a workspace
+// @description Join a workspace with the token in the invitation email; current user must have the invited email.
+// @tags workspaces
+//
+// @router /workspaces/invitations/accept [POST]
+// @accept json
+// @produce json
+// @security BearerToken
+// @param body body FormAcceptInvitation true "Accept invitation form"
+//
+// @success 200 {object} model.WorkspaceDTO
+// @failure 400 {object} e.Error
+// @failure 400 {object} e.ValidationErrors
+// @failure 401 {object} e.Error
+// @failure 403 {object} e.Error
+// @failure 409 {object} e.Error
+// @failure 500 {object} e.Error
+func (a *API) AcceptInvitation(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	reqID, ctxUser := ctxutil.RequestID(ctx), ctxutil.UserFromCtx(ctx)
+
+	form := &FormAcceptInvitation{}
+	if e.JSONBindAndValidateErrorHandled(w, r, a.logger, a.validator, form, reqID) {
+		return
+	}
+
+	invitation, err := a.db.ReadWorkspaceInvitationByToken(form.Token)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			e.BadRequest(w, e.RespTokenInvalid)
+			return
+		}
+
+		a.logger.Error().Str(l.KeyReqID, reqID).Err(err).Msg("")
+		e.ServerError(w, e.RespDBDataAccessFailure)
+		return
+	}
+
+	if invitation.Email != strings.ToLower(ctxUser.Email) {
+		e.Forbidden(w, e.RespInvitationEmailMismatch)
+		return
+	}
+
+	if invitation.AcceptedAt != nil || invitation.ExpiresAt.Before(time.Now()) {
+		e.BadRequest(w, e.RespTokenExpired)
+		return
+	}
+
+	_, err = a.db.ReadWorkspaceMemberByWorkspaceIdAndUserId(invitation.WorkspaceID, *ctxUser.ID)
+	if err == nil {
+		e.Conflict(w, e.RespWorkspaceMemberExists)
+		return
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		a.logger.Error().Str(l.KeyReqID, reqID).Err(err).Msg("")
+		e.ServerError(w, e.RespDBDataAccessFailure)
+		return
+	}
+
+	tx := a.db.TxBegin()
+	accepted, err := tx.AcceptWorkspaceInvitationById(invitation.ID)
+	if err != nil {
+		tx.Rollback()
+		a.logger.Error().Str(l.KeyReqID, reqID).Err(err).Msg("")
+		e.ServerError(w, e.RespDBDataUpdateFailure)
+		return
+	}
+
+	// Accepted by a concurrent request, or expired meanwhile
+	if !accepted {
+		tx.Rollback()
+		e.BadRequest(w, e.RespTokenExpired)
+		return
+	}
+
+	if err := tx.CreateWorkspaceMember(model.NewWorkspaceMember(invitation.WorkspaceID, *ctxUser.ID, invitation.Role)); err != nil {
+		tx.Rollback()
+		a.logger.Error().Str(l.KeyReqID, reqID).Err(err).Msg("")
+		e.ServerError(w, e.RespDBDataInsertFailure)
+		return
+	}
+	tx.Commit()
+
+	member, err := a.db.ReadWorkspaceMemberByWorkspaceIdAndUserId(invitation.WorkspaceID, *ctxUser.ID)
+	if err != nil {
+		a.logger.Error().Str(l.KeyReqID, reqID).Err(err).Msg("")
+		e.ServerError(w, e.RespDBDataAccessFailure)
+		return
+	}
+
+	if err := json.NewEncoder(w).Encode(member.ToWorkspaceDTO()); err != nil {
+		a.logger.Error().Str(l.KeyReqID, reqID).Err(err).Msg("")
+		e.ServerError(w, e.RespJSONEncodeFailure)
+		return
+	}
+}
+
+// readMemberErrorHandled reads the membership of current user in the workspace of the path and checks it has the role.
+// The workspaces current user is not a member of are not found.
+func (a *API) readMemberErrorHandled(w http.ResponseWriter, r *http.Request, reqID string, role string) (*model.WorkspaceMember, bool) {
+	ctxUser := ctxutil.UserFromCtx(r.Context())
+
+	workspaceID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		e.BadRequest(w, e.RespInvalidID)
+		return nil, true
+	}
+
+	member, err := a.db.ReadWorkspaceMemberByWorkspaceIdAndUserId(workspaceID, *ctxUser.ID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			w.WriteHeader(http.StatusNotFound)
+			return nil, true
+		}
+
+		a.logger.Error().Str(l.KeyReqID, reqID).Err(err).Msg("")
+		e.ServerError(w, e.RespDBDataAccessFailure)
+		return nil, true
+	}
+
+	if !model.WorkspaceRoleAllows(member.Role, role) {
+		e.Forbidden(w, e.RespInsufficientRole)
+		return nil, true
+	}
+
+	return member, false
+}
+
+// readTargetMemberErrorHandled reads a member about to get the role, or to be removed with an empty role, in a transaction.
+// The owner of a personal workspace and the last owner of a workspace can't lose the owner role.
+func (a *API) readTargetMemberErrorHandled(w http.ResponseWriter, tx *repository.Db, reqID string, workspaceID, userID uuid.UUID, role string) (*model.WorkspaceMember, bool) {
+	target, err := tx.ReadWorkspaceMemberByWorkspaceIdAndUserId(workspaceID, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			w.WriteHeader(http.StatusNotFound)
+			return nil, true
+		}
+
+		a.logger.Error().Str(l.KeyReqID, reqID).Err(err).Msg("")
+		e.ServerError(w, e.RespDBDataAccessFailure)
+		return nil, true
+	}
+
+	if target.Role != model.WorkspaceRoleOwner || role == model.WorkspaceRoleOwner {
+		return target, false
+	}
+
+	if target.UserID == target.WorkspaceID {
+		e.Conflict(w, e.RespPersonalWorkspaceOwner)
+		return nil, true
+	}
+
+	owners, err := tx.CountWorkspaceOwnersByWorkspaceId(workspaceID)
+	if err != nil {
+		a.logger.Error().Str(l.KeyReqID, reqID).Err(err).Msg("")
+		e.ServerError(w, e.RespDBDataAccessFailure)
+		return nil, true
+	}
+
+	if owners <= 1 {
+		e.Conflict(w, e.RespLastWorkspaceOwner)
+		return nil, true
+	}
+
+	return target, false
+}
@@ -0,0 +1,18 @@
+package workspace
+
+type FormWorkspace struct {
+	Name string `json:"name" validate:"required,max=100"`
+}
+
+type FormMember struct {
+	Role string `json:"role" validate:"required,oneof=owner editor viewer"`
+}
+
+type FormInvitation struct {
+	Email string `json:"email" validate:"required,email"`
+	Role  string `json:"role" validate:"required,oneof=owner editor viewer"`
+}
+
+type FormAcceptInvitation struct {
+	Token string `json:"token" validate:"required"`
+}
@@ -0,0 +1,32 @@
+package middleware
+
+import (
+	"net/http"
+	"slices"
+	"strings"
+
+	"web-scraper.dev/internal/utils/ctxutil"
+)
+
+// RequireAdmin allows only the requests of the users with the admin emails.
+func RequireAdmin(emails []string) func(http.Handler) http.Handler {
+	admins := make([]string, 0, len(emails))
+	for _, v := range emails {
+		if v = strings.ToLower(strings.TrimSpace(v)); v != "" {
+			admins = append(admins, v)
+		}
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			email := strings.ToLower(ctxutil.UserFromCtx(r.Context()).Email)
+			if email == "" || !slices.Contains(admins, email) {
+				w.WriteHeader(http.StatusForbidden)
+				w.Write([]byte(`{"error": "admins only"}`))
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,38 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"web-scraper.dev/internal/api/router/middleware"
+	"web-scraper.dev/internal/utils/ctxutil"
+)
+
+func TestRequireAdmin(t *testing.T) {
+	admins := []string{" Admin@Example.com", ""}
+
+	tests := []struct {
+		name   string
+		email  string
+		status int
+	}{
+		{"admin", "admin@example.com", http.StatusOK},
+		{"user", "user@example.com", http.StatusForbidden},
+		{"api key", "", http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, _ := http.NewRequest("GET", "/", nil)
+			r = r.WithContext(ctxutil.SetUser(r.Context(), ctxutil.User{Email: tt.email}))
+			w := httptest.NewRecorder()
+
+			middleware.RequireAdmin(admins)(http.HandlerFunc(testHandlerFunc())).ServeHTTP(w, r)
+
+			if status := w.Result().StatusCode; status != tt.status {
+				t.Errorf("Wrong status code: got %v want %v", status, tt.status)
+			}
+		})
+	}
+}
@@ -0,0 +1,115 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"gorm.io/gorm"
+
+	e "web-scraper.dev/internal/api/errors"
+	"web-scraper.dev/internal/apikeys"
+	"web-scraper.dev/internal/repository"
+	"web-scraper.dev/internal/revocation"
+	"web-scraper.dev/internal/utils/ctxutil"
+	"web-scraper.dev/internal/utils/jwtutil"
+	l "web-scraper.dev/internal/utils/logger"
+)
+
+const HeaderKeyAPIKey = "X-API-Key"
+
+// Authentication authenticates the requests with a Bearer access token, or an API key in
+// an "Authorization: ApiKey <key>" or an "X-API-Key: <key>" header.
+func Authentication(denylist *revocation.Denylist, db *repository.Db, logger *l.Logger) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			tokenString := r.Header.Get("Authorization")
+			if apiKey := r.Header.Get(HeaderKeyAPIKey); apiKey != "" {
+				apiKeyAuthentication(w, r, next, db, logger, apiKey)
+				return
+			}
+
+			if len(tokenString) > 7 && strings.ToUpper(tokenString[0:7]) == "APIKEY " {
+				apiKeyAuthentication(w, r, next, db, logger, tokenString[7:])
+				return
+			}
+
+			if len(tokenString) < 7 || strings.ToUpper(tokenString[0:6]) != "BEARER" {
+				w.WriteHeader(http.StatusUnauthorized)
+				w.Write([]byte(`{"error": "no token found"}`))
+				return
+			}
+
+			tokenString = tokenString[7:]
+
+			claims, err := jwtutil.ClaimsFromAccessToken(tokenString)
+			if err != nil {
+				w.WriteHeader(http.StatusUnauthorized)
+				w.Write([]byte(`{"error": "invalid token"}`))
+				return
+			}
+
+			ctxUser := claims.ToCtxUser()
+			if ctxUser.Email == "" {
+				w.WriteHeader(http.StatusUnauthorized)
+				w.Write([]byte(`{"error": "invalid token"}`))
+				return
+			}
+
+			ctx := r.Context()
+			revoked, err := denylist.IsRevoked(ctx, claims.Subject, claims.ID, claims.IssuedAt.Time, claims.ExpiresAt.Time)
+			if err != nil {
+				logger.Error().Str(l.KeyReqID, ctxutil.RequestID(ctx)).Err(err).Msg("")
+				e.ServerError(w, e.RespTokenRevocationCheckFailure)
+				return
+			}
+
+			if revoked {
+				w.WriteHeader(http.StatusUnauthorized)
+				w.Write([]byte(`{"error": "revoked token"}`))
+				return
+			}
+
+			ctx = ctxutil.SetUser(ctx, ctxUser)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		}
+
+		return http.HandlerFunc(fn)
+	}
+}
+
+func apiKeyAuthentication(w http.ResponseWriter, r *http.Request, next http.Handler, db *repository.Db, logger *l.Logger, key string) {
+	ctx := r.Context()
+	reqID := ctxutil.RequestID(ctx)
+
+	prefix, ok := apikeys.Prefix(key)
+	if !ok {
+		w.WriteHeader(http.StatusUnauthorized)
+		w.Write([]byte(`{"error": "invalid api key"}`))
+		return
+	}
+
+	apiKey, err := db.ReadAPIKeyByPrefix(prefix)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		logger.Error().Str(l.KeyReqID, reqID).Err(err).Msg("")
+		e.ServerError(w, e.RespDBDataAccessFailure)
+		return
+	}
+
+	if apiKey == nil || apiKey.RevokedAt != nil || !apikeys.Verify(key, apiKey.KeyHash) {
+		w.WriteHeader(http.StatusUnauthorized)
+		w.Write([]byte(`{"error": "invalid api key"}`))
+		return
+	}
+
+	// Not knowing the last use is not a reason to fail the request
+	if err := db.UpdateAPIKeyLastUsedAtById(apiKey.ID); err != nil {
+		logger.Error().Str(l.KeyReqID, reqID).Err(err).Msg("")
+	}
+
+	ctx = ctxutil.SetUser(ctx, ctxutil.User{
+		ID:          &apiKey.UserID,
+		APIKeyScope: apiKey.Scope,
+	})
+	next.ServeHTTP(w, r.WithContext(ctx))
+}
@@ -29,8 +29,9 @@ import (
 const timeoutHandlerMsg = "handler timeout exceeded"
 
 type Handler struct {
-	handler http.Handler
-	logger  *l.Logger
+	handler      http.Handler
+	logger       *l.Logger
+	writeTimeout time.Duration
 }
 
 func NewHandler(h http.HandlerFunc, ht time.Duration, l *l.Logger) *Handler {
@@ -40,6 +41,16 @@ func NewHandler(h http.HandlerFunc, ht time.Duration, l *l.Logger) *Handler {
 	}
 }
 
+// NewStreamHandler returns a handler for long-lived responses, which are not buffered and can be flushed.
+// Instead of a deadline for the whole response, each write must complete within the write timeout.
+func NewStreamHandler(h http.HandlerFunc, wt time.Duration, l *l.Logger) *Handler {
+	return &Handler{
+		handler:      h,
+		logger:       l,
+		writeTimeout: wt,
+	}
+}
+
 func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	start := time.Now()
 
@@ -62,7 +73,10 @@ func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	*r2 = *r
 	rcc := &readCounterCloser{r: r.Body}
 	r2.Body = rcc
-	w2 := &responseStats{w: w}
+	w2 := &responseStats{w: w, writeTimeout: h.writeTimeout}
+	if h.writeTimeout > 0 {
+		http.NewResponseController(w).SetWriteDeadline(time.Time{})
+	}
 
 	h.handler.ServeHTTP(w2, r2)
 
@@ -89,10 +89,11 @@ func headerSize(h http.Header) int64 {
 }
 
 type responseStats struct {
-	w     http.ResponseWriter
-	hsize int64
-	wc    writeCounter
-	code  int
+	w            http.ResponseWriter
+	hsize        int64
+	wc           writeCounter
+	code         int
+	writeTimeout time.Duration
 }
 
 func (r *responseStats) Header() http.Header {
@@ -112,11 +113,30 @@ func (r *responseStats) Write(p []byte) (n int, err error) {
 	if r.code == 0 {
 		r.WriteHeader(http.StatusOK)
 	}
+	if r.writeTimeout > 0 {
+		http.NewResponseController(r.w).SetWriteDeadline(time.Now().Add(r.writeTimeout))
+	}
 	n, err = r.w.Write(p)
 	r.wc.Write(p[:n])
 	return
 }
 
+func (r *responseStats) Flush() {
+	if r.code == 0 {
+		r.WriteHeader(http.StatusOK)
+	}
+	rc := http.NewResponseController(r.w)
+	if r.writeTimeout > 0 {
+		rc.SetWriteDeadline(time.Now().Add(r.writeTimeout))
+	}
+	rc.Flush()
+}
+
+// Unwrap allows http.ResponseController to reach the underlying ResponseWriter.
+func (r *responseStats) Unwrap() http.ResponseWriter {
+	return r.w
+}
+
 func (r *responseStats) size() (hdr, body int64) {
 	if r.code == 0 {
 		return headerSize(r.w.Header()), 0
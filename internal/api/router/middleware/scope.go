@@ -0,0 +1,41 @@
+package middleware
+
+import (
+	"net/http"
+
+	"web-scraper.dev/internal/model"
+	"web-scraper.dev/internal/utils/ctxutil"
+)
+
+// RequireScope allows the requests authenticated with an API key only if the key has the scope.
+// The access tokens of the users have all the scopes.
+func RequireScope(scope string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			keyScope := ctxutil.UserFromCtx(r.Context()).APIKeyScope
+
+			// The upload scope includes the read scope
+			if keyScope != "" && keyScope != scope && keyScope != model.APIKeyScopeUpload {
+				w.WriteHeader(http.StatusForbidden)
+				w.Write([]byte(`{"error": "insufficient api key scope"}`))
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// RequireSession allows only the requests authenticated with an access token, to keep
+// the account settings out of reach of the API keys.
+func RequireSession(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if ctxutil.UserFromCtx(r.Context()).APIKeyScope != "" {
+			w.WriteHeader(http.StatusForbidden)
+			w.Write([]byte(`{"error": "not allowed with an api key"}`))
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,58 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"web-scraper.dev/internal/api/router/middleware"
+	"web-scraper.dev/internal/model"
+	"web-scraper.dev/internal/utils/ctxutil"
+)
+
+func TestRequireScope(t *testing.T) {
+	tests := []struct {
+		name     string
+		keyScope string
+		scope    string
+		status   int
+	}{
+		{"access token", "", model.APIKeyScopeUpload, http.StatusOK},
+		{"read key reading", model.APIKeyScopeRead, model.APIKeyScopeRead, http.StatusOK},
+		{"read key uploading", model.APIKeyScopeRead, model.APIKeyScopeUpload, http.StatusForbidden},
+		{"upload key reading", model.APIKeyScopeUpload, model.APIKeyScopeRead, http.StatusOK},
+		{"upload key uploading", model.APIKeyScopeUpload, model.APIKeyScopeUpload, http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, _ := http.NewRequest("GET", "/", nil)
+			r = r.WithContext(ctxutil.SetUser(r.Context(), ctxutil.User{APIKeyScope: tt.keyScope}))
+			w := httptest.NewRecorder()
+
+			middleware.RequireScope(tt.scope)(http.HandlerFunc(testHandlerFunc())).ServeHTTP(w, r)
+
+			if status := w.Result().StatusCode; status != tt.status {
+				t.Errorf("Wrong status code: got %v want %v", status, tt.status)
+			}
+		})
+	}
+}
+
+func TestRequireSession(t *testing.T) {
+	r, _ := http.NewRequest("GET", "/", nil)
+	w := httptest.NewRecorder()
+
+	middleware.RequireSession(http.HandlerFunc(testHandlerFunc())).ServeHTTP(w, r)
+	if status := w.Result().StatusCode; status != http.StatusOK {
+		t.Errorf("Wrong status code: got %v want %v", status, http.StatusOK)
+	}
+
+	r = r.WithContext(ctxutil.SetUser(r.Context(), ctxutil.User{APIKeyScope: model.APIKeyScopeUpload}))
+	w = httptest.NewRecorder()
+
+	middleware.RequireSession(http.HandlerFunc(testHandlerFunc())).ServeHTTP(w, r)
+	if status := w.Result().StatusCode; status != http.StatusForbidden {
+		t.Errorf("Wrong status code: got %v want %v", status, http.StatusForbidden)
+	}
+}
@@ -0,0 +1,72 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	e "web-scraper.dev/internal/api/errors"
+	"web-scraper.dev/internal/model"
+	"web-scraper.dev/internal/repository"
+	"web-scraper.dev/internal/utils/ctxutil"
+	l "web-scraper.dev/internal/utils/logger"
+)
+
+const HeaderKeyWorkspaceID = "X-Workspace-ID"
+
+// Workspace sets the workspace in an "X-Workspace-ID: <id>" header with the role of the user in it.
+// Without the header, the requests are for the personal workspace of the user.
+func Workspace(db *repository.Db, logger *l.Logger) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			ctx := r.Context()
+			ctxUser := ctxutil.UserFromCtx(ctx)
+
+			workspaceID := *ctxUser.ID
+			if v := r.Header.Get(HeaderKeyWorkspaceID); v != "" {
+				id, err := uuid.Parse(v)
+				if err != nil {
+					w.WriteHeader(http.StatusBadRequest)
+					w.Write([]byte(`{"error": "invalid workspace ID"}`))
+					return
+				}
+				workspaceID = id
+			}
+
+			member, err := db.ReadWorkspaceMemberByWorkspaceIdAndUserId(workspaceID, *ctxUser.ID)
+			if err != nil {
+				if errors.Is(err, gorm.ErrRecordNotFound) {
+					w.WriteHeader(http.StatusForbidden)
+					w.Write([]byte(`{"error": "not a workspace member"}`))
+					return
+				}
+
+				logger.Error().Str(l.KeyReqID, ctxutil.RequestID(ctx)).Err(err).Msg("")
+				e.ServerError(w, e.RespDBDataAccessFailure)
+				return
+			}
+
+			ctx = ctxutil.SetWorkspace(ctx, ctxutil.Workspace{ID: member.WorkspaceID, Role: member.Role})
+			next.ServeHTTP(w, r.WithContext(ctx))
+		}
+
+		return http.HandlerFunc(fn)
+	}
+}
+
+// RequireRole allows the requests only if the user has the role, or one above it, in the workspace.
+func RequireRole(role string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !model.WorkspaceRoleAllows(ctxutil.WorkspaceFromCtx(r.Context()).Role, role) {
+				w.WriteHeader(http.StatusForbidden)
+				w.Write([]byte(`{"error": "insufficient workspace role"}`))
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
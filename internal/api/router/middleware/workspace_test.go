@@ -0,0 +1,41 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"web-scraper.dev/internal/api/router/middleware"
+	"web-scraper.dev/internal/model"
+	"web-scraper.dev/internal/utils/ctxutil"
+)
+
+func TestRequireRole(t *testing.T) {
+	tests := []struct {
+		name     string
+		userRole string
+		role     string
+		status   int
+	}{
+		{"no workspace", "", model.WorkspaceRoleViewer, http.StatusForbidden},
+		{"viewer reading", model.WorkspaceRoleViewer, model.WorkspaceRoleViewer, http.StatusOK},
+		{"viewer editing", model.WorkspaceRoleViewer, model.WorkspaceRoleEditor, http.StatusForbidden},
+		{"editor editing", model.WorkspaceRoleEditor, model.WorkspaceRoleEditor, http.StatusOK},
+		{"editor managing", model.WorkspaceRoleEditor, model.WorkspaceRoleOwner, http.StatusForbidden},
+		{"owner editing", model.WorkspaceRoleOwner, model.WorkspaceRoleEditor, http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, _ := http.NewRequest("GET", "/", nil)
+			r = r.WithContext(ctxutil.SetWorkspace(r.Context(), ctxutil.Workspace{Role: tt.userRole}))
+			w := httptest.NewRecorder()
+
+			middleware.RequireRole(tt.role)(http.HandlerFunc(testHandlerFunc())).ServeHTTP(w, r)
+
+			if status := w.Result().StatusCode; status != tt.status {
+				t.Errorf("Wrong status code: got %v want %v", status, tt.status)
+			}
+		})
+	}
+}
@@ -10,16 +10,27 @@ import (
 	"github.com/hibiken/asynq"
 	"gorm.io/gorm"
 
+	"web-scraper.dev/internal/api/handlers/apikey"
 	"web-scraper.dev/internal/api/handlers/health"
 	"web-scraper.dev/internal/api/handlers/keyword"
+	"web-scraper.dev/internal/api/handlers/proxy"
+	"web-scraper.dev/internal/api/handlers/upload"
 	"web-scraper.dev/internal/api/handlers/user"
+	"web-scraper.dev/internal/api/handlers/webhook"
+	"web-scraper.dev/internal/api/handlers/workspace"
 	"web-scraper.dev/internal/api/router/middleware"
 	"web-scraper.dev/internal/api/router/middleware/requestlog"
+	"web-scraper.dev/internal/attempts"
+	"web-scraper.dev/internal/events"
+	"web-scraper.dev/internal/fairqueue"
 	"web-scraper.dev/internal/mailer"
+	"web-scraper.dev/internal/model"
+	"web-scraper.dev/internal/repository"
+	"web-scraper.dev/internal/revocation"
 	"web-scraper.dev/internal/utils/logger"
 )
 
-func New(hd time.Duration, hdw time.Duration, db *gorm.DB, ml *mailer.Mailer, l *logger.Logger, v *validator.Validate, asyq *asynq.Client) *chi.Mux {
+func New(hd time.Duration, hdw time.Duration, db *gorm.DB, ml *mailer.Mailer, l *logger.Logger, v *validator.Validate, asyq *asynq.Client, inspector *asynq.Inspector, broker *events.Broker, fair *fairqueue.Queue, denylist *revocation.Denylist, limiter *attempts.Limiter, adminEmails []string) *chi.Mux {
 	r := chi.NewRouter()
 
 	r.Get("/livez", health.Read)
@@ -27,9 +38,9 @@ func New(hd time.Duration, hdw time.Duration, db *gorm.DB, ml *mailer.Mailer, l
 	cors := cors.New(cors.Options{
 		AllowedOrigins:   []string{"*"},
 		AllowedMethods:   []string{http.MethodGet, http.MethodPost, http.MethodPut, http.MethodDelete, http.MethodOptions},
-		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", "X-CSRF-Token", "pragma"},
+		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", "X-API-Key", "X-CSRF-Token", "X-Workspace-ID", "pragma"},
 		AllowCredentials: true,
-		ExposedHeaders:   []string{"X-Total-Count"},
+		ExposedHeaders:   []string{"X-Total-Count", "Content-Disposition"},
 		MaxAge:           300,
 	})
 
@@ -38,20 +49,102 @@ func New(hd time.Duration, hdw time.Duration, db *gorm.DB, ml *mailer.Mailer, l
 		r.Use(middleware.ContentTypeJSON)
 		r.Use(middleware.RequestID)
 
-		userAPI := user.New(db, ml, l, v)
+		userAPI := user.New(db, ml, l, v, denylist, limiter)
 		r.Method(http.MethodPost, "/users/sign-up", requestlog.NewHandler(userAPI.SignUp, hd, l))
 		r.Method(http.MethodPost, "/users/sign-in", requestlog.NewHandler(userAPI.SignIn, hd, l))
+		r.Method(http.MethodPost, "/users/refresh", requestlog.NewHandler(userAPI.Refresh, hd, l))
+		r.Method(http.MethodPost, "/users/forgot-password", requestlog.NewHandler(userAPI.ForgotPassword, hd, l))
+		r.Method(http.MethodPost, "/users/reset-password", requestlog.NewHandler(userAPI.ResetPassword, hd, l))
 
 		r.Method(http.MethodPost, "/users/activate", requestlog.NewHandler(userAPI.Activate, hd, l))
 
 		r.Route("/", func(r chi.Router) {
-			r.Use(middleware.JwtAuthentication)
+			r.Use(middleware.Authentication(denylist, repository.New(db), l))
 
-			keywordAPI := keyword.New(db, l, v, asyq)
-			r.Method(http.MethodGet, "/keywords", requestlog.NewHandler(keywordAPI.GetKeywords, hd, l))
-			r.Method(http.MethodGet, "/keywords/{id}", requestlog.NewHandler(keywordAPI.GetKeyword, hd, l))
+			// The account settings are out of reach of the API keys
+			r.Group(func(r chi.Router) {
+				r.Use(middleware.RequireSession)
 
-			r.Method(http.MethodPost, "/keywords", requestlog.NewHandler(keywordAPI.UploadKeywords, hd, l))
+				r.Method(http.MethodPost, "/users/sign-out", requestlog.NewHandler(userAPI.SignOut, hd, l))
+				r.Method(http.MethodPost, "/users/sign-out-all", requestlog.NewHandler(userAPI.SignOutAll, hd, l))
+
+				r.Method(http.MethodGet, "/users/me/preferences", requestlog.NewHandler(userAPI.GetPreferences, hd, l))
+				r.Method(http.MethodPut, "/users/me/preferences", requestlog.NewHandler(userAPI.UpdatePreferences, hd, l))
+
+				apiKeyAPI := apikey.New(db, l, v)
+				r.Method(http.MethodGet, "/users/me/api-keys", requestlog.NewHandler(apiKeyAPI.GetAPIKeys, hd, l))
+				r.Method(http.MethodPost, "/users/me/api-keys", requestlog.NewHandler(apiKeyAPI.CreateAPIKey, hd, l))
+				r.Method(http.MethodGet, "/users/me/api-keys/{id}", requestlog.NewHandler(apiKeyAPI.GetAPIKey, hd, l))
+				r.Method(http.MethodPut, "/users/me/api-keys/{id}", requestlog.NewHandler(apiKeyAPI.UpdateAPIKey, hd, l))
+				r.Method(http.MethodDelete, "/users/me/api-keys/{id}", requestlog.NewHandler(apiKeyAPI.RevokeAPIKey, hd, l))
+
+				webhookAPI := webhook.New(db, l, v, asyq)
+				r.Method(http.MethodGet, "/webhooks", requestlog.NewHandler(webhookAPI.GetWebhooks, hd, l))
+				r.Method(http.MethodPost, "/webhooks", requestlog.NewHandler(webhookAPI.CreateWebhook, hd, l))
+				r.Method(http.MethodGet, "/webhooks/{id}", requestlog.NewHandler(webhookAPI.GetWebhook, hd, l))
+				r.Method(http.MethodPut, "/webhooks/{id}", requestlog.NewHandler(webhookAPI.UpdateWebhook, hd, l))
+				r.Method(http.MethodDelete, "/webhooks/{id}", requestlog.NewHandler(webhookAPI.DeleteWebhook, hd, l))
+				r.Method(http.MethodGet, "/webhooks/{id}/deliveries", requestlog.NewHandler(webhookAPI.GetWebhookDeliveries, hd, l))
+				r.Method(http.MethodPost, "/webhooks/{id}/deliveries/{deliveryId}/redeliver", requestlog.NewHandler(webhookAPI.RedeliverWebhookDelivery, hd, l))
+
+				workspaceAPI := workspace.New(db, ml, l, v)
+				r.Method(http.MethodGet, "/workspaces", requestlog.NewHandler(workspaceAPI.GetWorkspaces, hd, l))
+				r.Method(http.MethodPost, "/workspaces", requestlog.NewHandler(workspaceAPI.CreateWorkspace, hd, l))
+				r.Method(http.MethodPost, "/workspaces/invitations/accept", requestlog.NewHandler(workspaceAPI.AcceptInvitation, hd, l))
+				r.Method(http.MethodGet, "/workspaces/{id}", requestlog.NewHandler(workspaceAPI.GetWorkspace, hd, l))
+				r.Method(http.MethodPut, "/workspaces/{id}", requestlog.NewHandler(workspaceAPI.UpdateWorkspace, hd, l))
+				r.Method(http.MethodGet, "/workspaces/{id}/members", requestlog.NewHandler(workspaceAPI.GetMembers, hd, l))
+				r.Method(http.MethodPut, "/workspaces/{id}/members/{userId}", requestlog.NewHandler(workspaceAPI.UpdateMember, hd, l))
+				r.Method(http.MethodDelete, "/workspaces/{id}/members/{userId}", requestlog.NewHandler(workspaceAPI.DeleteMember, hd, l))
+				r.Method(http.MethodGet, "/workspaces/{id}/invitations", requestlog.NewHandler(workspaceAPI.GetInvitations, hd, l))
+				r.Method(http.MethodPost, "/workspaces/{id}/invitations", requestlog.NewHandler(workspaceAPI.CreateInvitation, hd, l))
+				r.Method(http.MethodDelete, "/workspaces/{id}/invitations/{invitationId}", requestlog.NewHandler(workspaceAPI.DeleteInvitation, hd, l))
+
+				r.Group(func(r chi.Router) {
+					r.Use(middleware.RequireAdmin(adminEmails))
+
+					proxyAPI := proxy.New(db, l, v)
+					r.Method(http.MethodGet, "/admin/proxies", requestlog.NewHandler(proxyAPI.GetProxies, hd, l))
+					r.Method(http.MethodPost, "/admin/proxies", requestlog.NewHandler(proxyAPI.CreateProxy, hd, l))
+					r.Method(http.MethodPut, "/admin/proxies/{id}", requestlog.NewHandler(proxyAPI.UpdateProxy, hd, l))
+					r.Method(http.MethodDelete, "/admin/proxies/{id}", requestlog.NewHandler(proxyAPI.DeleteProxy, hd, l))
+				})
+			})
+
+			keywordAPI := keyword.New(db, l, v, asyq, inspector, broker, fair)
+			uploadAPI := upload.New(db, l)
+
+			// The keywords and the uploads belong to the workspace in the X-Workspace-ID header
+			r.Group(func(r chi.Router) {
+				r.Use(middleware.Workspace(repository.New(db), l))
+				r.Use(middleware.RequireScope(model.APIKeyScopeRead))
+				r.Use(middleware.RequireRole(model.WorkspaceRoleViewer))
+
+				r.Method(http.MethodGet, "/keywords", requestlog.NewHandler(keywordAPI.GetKeywords, hd, l))
+				r.Method(http.MethodGet, "/keywords/export", requestlog.NewStreamHandler(keywordAPI.ExportKeywords, hdw, l))
+				r.Method(http.MethodGet, "/keywords/events", requestlog.NewStreamHandler(keywordAPI.GetKeywordEvents, hdw, l))
+				r.Method(http.MethodGet, "/keywords/{id}", requestlog.NewHandler(keywordAPI.GetKeyword, hd, l))
+				r.Method(http.MethodGet, "/keywords/{id}/html", requestlog.NewHandler(keywordAPI.GetKeywordHTML, hd, l))
+				r.Method(http.MethodGet, "/keywords/{id}/results", requestlog.NewHandler(keywordAPI.GetKeywordResults, hd, l))
+				r.Method(http.MethodGet, "/keywords/{id}/runs", requestlog.NewHandler(keywordAPI.GetKeywordRuns, hd, l))
+
+				r.Method(http.MethodGet, "/uploads", requestlog.NewHandler(uploadAPI.GetUploads, hd, l))
+				r.Method(http.MethodGet, "/uploads/{id}", requestlog.NewHandler(uploadAPI.GetUpload, hd, l))
+				r.Method(http.MethodGet, "/uploads/{id}/keywords", requestlog.NewHandler(uploadAPI.GetUploadKeywords, hd, l))
+				r.Method(http.MethodGet, "/uploads/{id}/export", requestlog.NewStreamHandler(uploadAPI.ExportUploadKeywords, hdw, l))
+			})
+
+			r.Group(func(r chi.Router) {
+				r.Use(middleware.Workspace(repository.New(db), l))
+				r.Use(middleware.RequireScope(model.APIKeyScopeUpload))
+				r.Use(middleware.RequireRole(model.WorkspaceRoleEditor))
+
+				r.Method(http.MethodPost, "/keywords", requestlog.NewHandler(keywordAPI.UploadKeywords, hd, l))
+				r.Method(http.MethodPost, "/keywords/rescrape", requestlog.NewHandler(keywordAPI.RescrapeKeywords, hd, l))
+				r.Method(http.MethodPost, "/keywords/{id}/rescrape", requestlog.NewHandler(keywordAPI.RescrapeKeyword, hd, l))
+				r.Method(http.MethodPost, "/keywords/{id}/cancel", requestlog.NewHandler(keywordAPI.CancelKeyword, hd, l))
+				r.Method(http.MethodPut, "/keywords/{id}/schedule", requestlog.NewHandler(keywordAPI.UpdateKeywordSchedule, hd, l))
+			})
 		})
 	})
 
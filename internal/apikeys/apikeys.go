@@ -0,0 +1,52 @@
+package apikeys
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"strings"
+)
+
+const (
+	keyPrefix  = "wsk_"
+	idSize     = 4
+	secretSize = 32
+
+	// prefixLength is the length of the visible part of the keys, which identifies them
+	prefixLength = len(keyPrefix) + 2*idSize
+	keyLength    = prefixLength + 1 + 2*secretSize
+)
+
+// New generates an API key; "wsk_<8 hex id>_<64 hex secret>". Only its prefix, "wsk_<8 hex id>", and its hash are stored.
+func New() (key, prefix string, err error) {
+	b := make([]byte, idSize+secretSize)
+	if _, err := rand.Read(b); err != nil {
+		return "", "", err
+	}
+
+	prefix = keyPrefix + hex.EncodeToString(b[:idSize])
+	key = prefix + "_" + hex.EncodeToString(b[idSize:])
+
+	return key, prefix, nil
+}
+
+// Prefix returns the visible part of a key to look it up, if the key is well-formed.
+func Prefix(key string) (string, bool) {
+	if len(key) != keyLength || !strings.HasPrefix(key, keyPrefix) || key[prefixLength] != '_' {
+		return "", false
+	}
+
+	return key[:prefixLength], true
+}
+
+// Hash returns the hex SHA-256 of a key. Keys are random enough to not need a slow password hash.
+func Hash(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// Verify reports whether a key matches the stored hash.
+func Verify(key, hash string) bool {
+	return subtle.ConstantTimeCompare([]byte(Hash(key)), []byte(hash)) == 1
+}
@@ -0,0 +1,56 @@
+package apikeys_test
+
+import (
+	"strings"
+	"testing"
+
+	"web-scraper.dev/internal/apikeys"
+)
+
+func TestNew(t *testing.T) {
+	t.Parallel()
+
+	key, prefix, err := apikeys.New()
+	if err != nil {
+		t.Fatalf("Key not generated: %v", err)
+	}
+
+	if !strings.HasPrefix(key, prefix+"_") {
+		t.Errorf("Wrong prefix: got %v for %v", prefix, key)
+	}
+
+	if p, ok := apikeys.Prefix(key); !ok || p != prefix {
+		t.Errorf("Wrong parsed prefix: got %v, %v want %v", p, ok, prefix)
+	}
+
+	if !apikeys.Verify(key, apikeys.Hash(key)) {
+		t.Errorf("Key not verified with its hash")
+	}
+
+	other, _, _ := apikeys.New()
+	if apikeys.Verify(other, apikeys.Hash(key)) {
+		t.Errorf("Key verified with the hash of another")
+	}
+}
+
+func TestPrefix(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]string{
+		"empty":        "",
+		"no prefix":    "abc_0123abcd_" + strings.Repeat("a", 64),
+		"short secret": "wsk_0123abcd_" + strings.Repeat("a", 63),
+		"no separator": "wsk_0123abcd" + strings.Repeat("a", 65),
+		"jwt":          "eyJhbGciOiJFZERTQSIsInR5cCI6IkpXVCJ9",
+	}
+
+	for name, key := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			if p, ok := apikeys.Prefix(key); ok {
+				t.Errorf("Malformed key accepted: %v with prefix %v", key, p)
+			}
+		})
+	}
+}
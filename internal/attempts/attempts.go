@@ -0,0 +1,148 @@
+package attempts
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	ActionSignIn         = "sign-in"
+	ActionActivate       = "activate"
+	ActionResetPassword  = "reset-password"
+	ActionForgotPassword = "forgot-password"
+)
+
+const (
+	fmtAccountKey = "attempts:%s:account:%s"
+	fmtIPKey      = "attempts:%s:ip:%s"
+
+	fieldFailures = "failures"
+	fieldNextAt   = "next_at"
+)
+
+// Policy is how the failed attempts of an account and an IP address are limited.
+type Policy struct {
+	// FreeFailures is the number of failures of an account allowed without waiting
+	FreeFailures int
+	// MaxDelay caps the wait after each further failure, which doubles from a second
+	MaxDelay time.Duration
+	// LockoutFailures is the number of failures to lock an account at, for LockoutDuration
+	LockoutFailures int
+	LockoutDuration time.Duration
+	// CodeFailures is the number of failures to invalidate the code sent to an account at
+	CodeFailures int
+	// IPFailures is the number of failures to block an IP address at, until it has no failures for Window
+	IPFailures int
+	// Window is how long the failures are counted after the last one
+	Window time.Duration
+}
+
+var DefaultPolicy = Policy{
+	FreeFailures:    3,
+	MaxDelay:        time.Minute,
+	LockoutFailures: 10,
+	LockoutDuration: 15 * time.Minute,
+	CodeFailures:    5,
+	IPFailures:      100,
+	Window:          15 * time.Minute,
+}
+
+// Delay is the wait before the next attempt after the given number of failures of an account.
+func (p Policy) Delay(failures int) time.Duration {
+	if failures >= p.LockoutFailures {
+		return p.LockoutDuration
+	}
+
+	if failures <= p.FreeFailures {
+		return 0
+	}
+
+	delay := time.Second << min(failures-p.FreeFailures-1, 30)
+	return min(delay, p.MaxDelay)
+}
+
+// Failure is the state of an account after a failed attempt.
+type Failure struct {
+	Failures int
+	// Locked is set only by the failure locking the account, so the owner is told once
+	Locked bool
+}
+
+// Limiter counts the failed attempts of the accounts and the IP addresses per action in Redis,
+// so it holds across the API servers.
+type Limiter struct {
+	rdb    redis.UniversalClient
+	policy Policy
+}
+
+func NewLimiter(rdb redis.UniversalClient, policy Policy) *Limiter {
+	return &Limiter{
+		rdb:    rdb,
+		policy: policy,
+	}
+}
+
+func (l *Limiter) Policy() Policy {
+	return l.policy
+}
+
+// Wait returns how long the account or the IP address has to wait before the next attempt; zero if it's allowed now.
+func (l *Limiter) Wait(ctx context.Context, action, account, ip string) (time.Duration, error) {
+	pipe := l.rdb.Pipeline()
+	nextAt := pipe.HGet(ctx, fmt.Sprintf(fmtAccountKey, action, account), fieldNextAt)
+	ipFailures := pipe.Get(ctx, fmt.Sprintf(fmtIPKey, action, ip))
+	ipTTL := pipe.TTL(ctx, fmt.Sprintf(fmtIPKey, action, ip))
+	if _, err := pipe.Exec(ctx); err != nil && !errors.Is(err, redis.Nil) {
+		return 0, err
+	}
+
+	var wait time.Duration
+	if ms, err := nextAt.Int64(); err == nil {
+		wait = time.Until(time.UnixMilli(ms))
+	}
+
+	if n, err := ipFailures.Int(); err == nil && n >= l.policy.IPFailures {
+		wait = max(wait, ipTTL.Val())
+	}
+
+	return max(wait, 0), nil
+}
+
+// Fail counts a failed attempt of the account from the IP address.
+func (l *Limiter) Fail(ctx context.Context, action, account, ip string) (*Failure, error) {
+	accountKey := fmt.Sprintf(fmtAccountKey, action, account)
+	ipKey := fmt.Sprintf(fmtIPKey, action, ip)
+
+	pipe := l.rdb.TxPipeline()
+	failures := pipe.HIncrBy(ctx, accountKey, fieldFailures, 1)
+	pipe.Incr(ctx, ipKey)
+	pipe.Expire(ctx, ipKey, l.policy.Window)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return nil, err
+	}
+
+	n := int(failures.Val())
+	delay := l.policy.Delay(n)
+
+	pipe = l.rdb.TxPipeline()
+	pipe.HSet(ctx, accountKey, fieldNextAt, strconv.FormatInt(time.Now().Add(delay).UnixMilli(), 10))
+	pipe.Expire(ctx, accountKey, max(l.policy.Window, delay))
+	if _, err := pipe.Exec(ctx); err != nil {
+		return nil, err
+	}
+
+	return &Failure{
+		Failures: n,
+		Locked:   n == l.policy.LockoutFailures,
+	}, nil
+}
+
+// Reset forgets the failures of the account after a successful attempt. The failures of the IP address are kept.
+func (l *Limiter) Reset(ctx context.Context, action, account string) error {
+	return l.rdb.Del(ctx, fmt.Sprintf(fmtAccountKey, action, account)).Err()
+}
@@ -0,0 +1,36 @@
+package attempts_test
+
+import (
+	"testing"
+	"time"
+
+	"web-scraper.dev/internal/attempts"
+)
+
+func TestPolicyDelay(t *testing.T) {
+	t.Parallel()
+
+	p := attempts.DefaultPolicy
+
+	tests := map[int]time.Duration{
+		1:  0,
+		3:  0,
+		4:  time.Second,
+		5:  2 * time.Second,
+		6:  4 * time.Second,
+		9:  32 * time.Second,
+		10: 15 * time.Minute,
+		50: 15 * time.Minute,
+	}
+
+	for failures, expected := range tests {
+		if delay := p.Delay(failures); delay != expected {
+			t.Errorf("Wrong delay after %d failures: got %v want %v", failures, delay, expected)
+		}
+	}
+
+	p.LockoutFailures = 100
+	if delay := p.Delay(40); delay != p.MaxDelay {
+		t.Errorf("Wrong capped delay: got %v want %v", delay, p.MaxDelay)
+	}
+}
@@ -0,0 +1,135 @@
+package blocks
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	fmtBucketKey = "blocks:%s:%d"
+	fmtPausedKey = "blocks:%s:paused"
+
+	fieldTotal   = "total"
+	fieldBlocked = "blocked"
+
+	bucketSize = time.Minute
+)
+
+// Policy is when the scrapes of a search engine are paused for blocking too many of them.
+type Policy struct {
+	// Window is how long the scrapes are counted for the block rate, by the minute
+	Window time.Duration
+	// MinSamples is the number of scrapes in the window below which the block rate is not trusted
+	MinSamples int
+	// Threshold is the block rate pausing the search engine at, between 0 and 1
+	Threshold float64
+	// PauseDuration is how long the search engine is paused; longer than Window so it restarts on a fresh count
+	PauseDuration time.Duration
+}
+
+var DefaultPolicy = Policy{
+	Window:        5 * time.Minute,
+	MinSamples:    20,
+	Threshold:     0.5,
+	PauseDuration: 10 * time.Minute,
+}
+
+// ShouldPause reports whether the given number of blocked scrapes out of the total pauses the search engine.
+func (p Policy) ShouldPause(total, blocked int64) bool {
+	if total == 0 || total < int64(p.MinSamples) {
+		return false
+	}
+
+	return float64(blocked)/float64(total) >= p.Threshold
+}
+
+// Monitor counts the scrapes of the search engines blocked by them in Redis, so the block rate and the pauses hold across the workers.
+type Monitor struct {
+	rdb    redis.UniversalClient
+	policy Policy
+}
+
+func NewMonitor(rdb redis.UniversalClient, policy Policy) *Monitor {
+	return &Monitor{
+		rdb:    rdb,
+		policy: policy,
+	}
+}
+
+// Record counts a scrape of the search engine and pauses it once the block rate crosses the threshold.
+// It returns the end of the pause only to the scrape starting it, so the pause is reported once.
+func (m *Monitor) Record(ctx context.Context, engine string, blocked bool) (*time.Time, error) {
+	now := time.Now()
+	buckets := max(int(m.policy.Window/bucketSize), 1)
+
+	current := now.Unix() / int64(bucketSize.Seconds())
+	currentKey := fmt.Sprintf(fmtBucketKey, engine, current)
+
+	pipe := m.rdb.TxPipeline()
+	pipe.HIncrBy(ctx, currentKey, fieldTotal, 1)
+	if blocked {
+		pipe.HIncrBy(ctx, currentKey, fieldBlocked, 1)
+	}
+	pipe.Expire(ctx, currentKey, m.policy.Window+bucketSize)
+
+	counts := make([]*redis.SliceCmd, buckets)
+	for i := range buckets {
+		counts[i] = pipe.HMGet(ctx, fmt.Sprintf(fmtBucketKey, engine, current-int64(i)), fieldTotal, fieldBlocked)
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return nil, err
+	}
+
+	var total, blockedTotal int64
+	for _, v := range counts {
+		vals := v.Val()
+		total += toInt64(vals[0])
+		blockedTotal += toInt64(vals[1])
+	}
+
+	if !m.policy.ShouldPause(total, blockedTotal) {
+		return nil, nil
+	}
+
+	until := now.Add(m.policy.PauseDuration)
+	value := strconv.FormatInt(until.UnixMilli(), 10)
+	ok, err := m.rdb.SetNX(ctx, fmt.Sprintf(fmtPausedKey, engine), value, m.policy.PauseDuration).Result()
+	if err != nil || !ok {
+		return nil, err
+	}
+
+	return &until, nil
+}
+
+// PausedUntil returns the end of the pause of the search engine; nil if it's not paused.
+func (m *Monitor) PausedUntil(ctx context.Context, engine string) (*time.Time, error) {
+	ms, err := m.rdb.Get(ctx, fmt.Sprintf(fmtPausedKey, engine)).Int64()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	until := time.UnixMilli(ms)
+	if !until.After(time.Now()) {
+		return nil, nil
+	}
+
+	return &until, nil
+}
+
+func toInt64(v any) int64 {
+	s, ok := v.(string)
+	if !ok {
+		return 0
+	}
+
+	n, _ := strconv.ParseInt(s, 10, 64)
+	return n
+}
@@ -0,0 +1,32 @@
+package blocks_test
+
+import (
+	"testing"
+
+	"web-scraper.dev/internal/blocks"
+)
+
+func TestPolicyShouldPause(t *testing.T) {
+	t.Parallel()
+
+	p := blocks.DefaultPolicy
+
+	tests := []struct {
+		total    int64
+		blocked  int64
+		expected bool
+	}{
+		{0, 0, false},
+		{10, 10, false},
+		{20, 9, false},
+		{20, 10, true},
+		{100, 49, false},
+		{100, 80, true},
+	}
+
+	for _, tc := range tests {
+		if got := p.ShouldPause(tc.total, tc.blocked); got != tc.expected {
+			t.Errorf("Wrong pause for %d blocked out of %d: got %v want %v", tc.blocked, tc.total, got, tc.expected)
+		}
+	}
+}
@@ -21,6 +21,7 @@ type ConfServer struct {
 	TimeoutWrite time.Duration `env:"SERVER_TIMEOUT_WRITE,required"`
 	TimeoutIdle  time.Duration `env:"SERVER_TIMEOUT_IDLE,required"`
 	Debug        bool          `env:"SERVER_DEBUG,required"`
+	AdminEmails  []string      `env:"SERVER_ADMIN_EMAILS" envSeparator:","`
 }
 
 type ConfDB struct {
@@ -71,8 +72,51 @@ func NewDB() *ConfDB {
 // --- worker ---
 
 type WorkerConf struct {
-	DB         ConfDB
-	RedisHosts string `env:"REDIS_HOSTS,required"`
+	Queues      ConfQueues
+	Fairness    ConfFairness
+	DB          ConfDB
+	Mailer      MailerConf
+	Proxies     ConfProxies
+	Blocks      ConfBlocks
+	RateLimits  ConfRateLimits
+	RedisHosts  string `env:"REDIS_HOSTS,required"`
+	MetricsPort int    `env:"WORKER_METRICS_PORT" envDefault:"0"` // Serves the expvar metrics at /debug/vars, disabled with 0
+}
+
+// ConfQueues are the queues a worker processes with their weights. A priority queue includes the queues of all
+// the search engines, ex: "critical=6,default=3,low=1", and the queue of a search engine only it, ex: "critical:google=6".
+type ConfQueues struct {
+	Concurrency    int            `env:"WORKER_CONCURRENCY" envDefault:"10"`
+	Weights        map[string]int `env:"WORKER_QUEUES" envSeparator:"," envKeyValSeparator:"=" envDefault:"critical=6,default=3,low=1"`
+	StrictPriority bool           `env:"WORKER_STRICT_PRIORITY" envDefault:"false"`
+}
+
+type ConfFairness struct {
+	FeedInterval    time.Duration `env:"FAIR_FEED_INTERVAL" envDefault:"500ms"`
+	Backlog         int           `env:"FAIR_BACKLOG" envDefault:"10"` // Unfinished tasks in each asynq queue, taken from the fair queues
+	UserMaxInFlight int           `env:"USER_MAX_IN_FLIGHT" envDefault:"3"`
+}
+
+type ConfProxies struct {
+	URLs         []string      `env:"PROXY_URLS" envSeparator:","`
+	SyncInterval time.Duration `env:"PROXY_SYNC_INTERVAL" envDefault:"1m"`
+	MinInterval  time.Duration `env:"PROXY_MIN_INTERVAL" envDefault:"2s"`
+}
+
+type ConfBlocks struct {
+	Window        time.Duration `env:"BLOCK_WINDOW" envDefault:"5m"`
+	MinSamples    int           `env:"BLOCK_MIN_SAMPLES" envDefault:"20"`
+	Threshold     float64       `env:"BLOCK_THRESHOLD" envDefault:"0.5"`
+	PauseDuration time.Duration `env:"BLOCK_PAUSE_DURATION" envDefault:"10m"`
+}
+
+// ConfRateLimits are in requests per second, across all the workers.
+type ConfRateLimits struct {
+	Engine      float64            `env:"RATE_LIMIT_ENGINE" envDefault:"1"`
+	EngineBurst int                `env:"RATE_LIMIT_ENGINE_BURST" envDefault:"1"`
+	Engines     map[string]float64 `env:"RATE_LIMIT_ENGINES" envSeparator:"," envKeyValSeparator:":"` // Overrides Engine by search engine, ex: "google:0.5,bing:2"
+	Proxy       float64            `env:"RATE_LIMIT_PROXY" envDefault:"0.5"`
+	ProxyBurst  int                `env:"RATE_LIMIT_PROXY_BURST" envDefault:"1"`
 }
 
 func NewWorkerConf() *WorkerConf {
@@ -83,3 +127,20 @@ func NewWorkerConf() *WorkerConf {
 
 	return &c
 }
+
+// --- scheduler ---
+
+type SchedulerConf struct {
+	DB           ConfDB
+	RedisHosts   string        `env:"REDIS_HOSTS,required"`
+	SyncInterval time.Duration `env:"SCHEDULER_SYNC_INTERVAL" envDefault:"1m"`
+}
+
+func NewSchedulerConf() *SchedulerConf {
+	var c SchedulerConf
+	if err := env.Parse(&c); err != nil {
+		log.Fatalf("Failed to decode: %s", err)
+	}
+
+	return &c
+}
@@ -0,0 +1,138 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+
+	"web-scraper.dev/internal/model"
+)
+
+const TypeKeyword = "keyword"
+
+const fmtWorkspaceChannel = "events:workspace:%s"
+
+// Event is a change pushed to the clients of the members of a workspace.
+type Event struct {
+	Type string          `json:"type"`
+	Data json.RawMessage `json:"data"`
+}
+
+// Keyword is the data of a keyword status or result change; the fields without a value are unchanged.
+type Keyword struct {
+	ID             int64      `json:"id"`
+	Status         string     `json:"status"`
+	AdCount        *int64     `json:"adCount,omitempty"`
+	LinkCount      *int64     `json:"linkCount,omitempty"`
+	ErrorMessage   *string    `json:"errorMessage,omitempty"`
+	Classification *string    `json:"classification,omitempty"`
+	LastRunID      *int64     `json:"lastRunId,omitempty"`
+	LastRunAt      *time.Time `json:"lastRunAt,omitempty"`
+}
+
+func NewKeyword(k *model.Keyword) *Keyword {
+	return &Keyword{
+		ID:             k.ID,
+		Status:         k.Status,
+		AdCount:        k.AdCount,
+		LinkCount:      k.LinkCount,
+		ErrorMessage:   k.ErrorMessage,
+		Classification: k.Classification,
+		LastRunID:      k.LastScrapeID,
+		LastRunAt:      k.LastScrapedAt,
+	}
+}
+
+// Broker publishes the events of workspaces over Redis pub/sub, so the API servers can push the changes made by the workers.
+type Broker struct {
+	rdb redis.UniversalClient
+
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+func NewBroker(rdb redis.UniversalClient) *Broker {
+	return &Broker{
+		rdb:  rdb,
+		done: make(chan struct{}),
+	}
+}
+
+func (b *Broker) Publish(ctx context.Context, workspaceID uuid.UUID, eventType string, data any) error {
+	d, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+
+	payload, err := json.Marshal(&Event{Type: eventType, Data: d})
+	if err != nil {
+		return err
+	}
+
+	return b.rdb.Publish(ctx, fmt.Sprintf(fmtWorkspaceChannel, workspaceID), payload).Err()
+}
+
+func (b *Broker) PublishKeyword(ctx context.Context, k *model.Keyword) error {
+	return b.Publish(ctx, k.WorkspaceID, TypeKeyword, NewKeyword(k))
+}
+
+type Subscription struct {
+	// C receives the events until the context is done or the broker is closed
+	C <-chan *Event
+}
+
+// Subscribe starts receiving the events of the workspace; it returns once the subscription is active.
+func (b *Broker) Subscribe(ctx context.Context, workspaceID uuid.UUID) (*Subscription, error) {
+	ps := b.rdb.Subscribe(ctx, fmt.Sprintf(fmtWorkspaceChannel, workspaceID))
+	if _, err := ps.Receive(ctx); err != nil {
+		ps.Close()
+		return nil, err
+	}
+
+	c := make(chan *Event)
+	go func() {
+		defer close(c)
+		defer ps.Close()
+
+		msgs := ps.Channel()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-b.done:
+				return
+			case msg, ok := <-msgs:
+				if !ok {
+					return
+				}
+
+				var e Event
+				if err := json.Unmarshal([]byte(msg.Payload), &e); err != nil {
+					continue
+				}
+
+				select {
+				case c <- &e:
+				case <-ctx.Done():
+					return
+				case <-b.done:
+					return
+				}
+			}
+		}
+	}()
+
+	return &Subscription{C: c}, nil
+}
+
+// Close ends the subscriptions, ex: to let the long-lived responses finish on shutdown. It doesn't close the Redis client.
+func (b *Broker) Close() {
+	b.closeOnce.Do(func() {
+		close(b.done)
+	})
+}
@@ -0,0 +1,359 @@
+package export
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/xuri/excelize/v2"
+
+	"web-scraper.dev/internal/model"
+)
+
+const (
+	FormatCSV    = "csv"
+	FormatXLSX   = "xlsx"
+	FormatJSON   = "json"
+	FormatNDJSON = "ndjson"
+)
+
+const (
+	ColumnID           = "id"
+	ColumnKeyword      = "keyword"
+	ColumnSearchEngine = "search_engine"
+	ColumnMarket       = "market"
+	ColumnDevice       = "device"
+	ColumnTags         = "tags"
+	ColumnStatus       = "status"
+	ColumnAdCount      = "ad_count"
+	ColumnLinkCount    = "link_count"
+	ColumnError        = "error"
+	ColumnScrapedAt    = "scraped_at"
+	ColumnResults      = "results"
+)
+
+const (
+	queryKeyFormat  = "format"
+	queryKeyColumns = "columns"
+
+	xlsxSheet = "Keywords"
+)
+
+var (
+	ErrInvalidFormat  = errors.New("invalid export format")
+	ErrInvalidColumns = errors.New("invalid export columns")
+)
+
+var DefaultColumns = []string{ColumnKeyword, ColumnSearchEngine, ColumnStatus, ColumnAdCount, ColumnLinkCount, ColumnError, ColumnScrapedAt}
+
+var contentTypes = map[string]string{
+	FormatCSV:    "text/csv; charset=utf-8",
+	FormatXLSX:   "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+	FormatJSON:   "application/json; charset=utf-8",
+	FormatNDJSON: "application/x-ndjson",
+}
+
+type column struct {
+	jsonKey string
+	value   func(r *Row) any
+}
+
+var columns = map[string]column{
+	ColumnID:           {"id", func(r *Row) any { return r.Keyword.ID }},
+	ColumnKeyword:      {"keyword", func(r *Row) any { return r.Keyword.Keyword }},
+	ColumnSearchEngine: {"searchEngine", func(r *Row) any { return r.Keyword.SearchEngine }},
+	ColumnMarket:       {"market", func(r *Row) any { return r.Keyword.Market }},
+	ColumnDevice:       {"device", func(r *Row) any { return r.Keyword.Device }},
+	ColumnTags:         {"tags", func(r *Row) any { return r.Keyword.Tags }},
+	ColumnStatus:       {"status", func(r *Row) any { return r.Keyword.Status }},
+	ColumnAdCount:      {"adCount", func(r *Row) any { return r.Keyword.AdCount }},
+	ColumnLinkCount:    {"linkCount", func(r *Row) any { return r.Keyword.LinkCount }},
+	ColumnError:        {"errorMessage", func(r *Row) any { return r.Keyword.ErrorMessage }},
+	ColumnScrapedAt:    {"scrapedAt", func(r *Row) any { return r.Keyword.LastScrapedAt }},
+	ColumnResults:      {"results", func(r *Row) any { return r.Results }},
+}
+
+type Options struct {
+	Format  string
+	Columns []string
+}
+
+// OptionsFromQuery reads the format and the comma separated columns, which default to CSV and DefaultColumns.
+func OptionsFromQuery(q url.Values) (*Options, error) {
+	opts := &Options{Format: FormatCSV, Columns: DefaultColumns}
+
+	if v := q.Get(queryKeyFormat); v != "" {
+		if _, ok := contentTypes[v]; !ok {
+			return nil, ErrInvalidFormat
+		}
+		opts.Format = v
+	}
+
+	if v := q.Get(queryKeyColumns); v != "" {
+		opts.Columns = nil
+		seen := make(map[string]bool)
+		for _, c := range strings.Split(v, ",") {
+			c = strings.TrimSpace(c)
+			if _, ok := columns[c]; !ok {
+				return nil, ErrInvalidColumns
+			}
+
+			if !seen[c] {
+				seen[c] = true
+				opts.Columns = append(opts.Columns, c)
+			}
+		}
+	}
+
+	return opts, nil
+}
+
+// HasColumn reports whether the column is selected.
+func (o *Options) HasColumn(name string) bool {
+	for _, v := range o.Columns {
+		if v == name {
+			return true
+		}
+	}
+	return false
+}
+
+func (o *Options) ContentType() string {
+	return contentTypes[o.Format]
+}
+
+// Filename returns the attachment file name with the extension of the format.
+func (o *Options) Filename(name string) string {
+	return name + "." + o.Format
+}
+
+type Row struct {
+	Keyword *model.Keyword
+	Results *model.KeywordResultsDTO
+}
+
+type Writer interface {
+	Write(r *Row) error
+	// Flush writes the buffered rows to the underlying writer, except for spreadsheets.
+	Flush() error
+	// Close writes the remaining data. It doesn't close the underlying writer.
+	Close() error
+}
+
+// NewWriter returns a writer of the selected columns in the format; CSV and spreadsheet files start with a header row.
+// Rows are written to w as they come, except for spreadsheets which are written on Close.
+func NewWriter(w io.Writer, opts *Options) (Writer, error) {
+	cols := make([]column, len(opts.Columns))
+	for i, v := range opts.Columns {
+		cols[i] = columns[v]
+	}
+
+	switch opts.Format {
+	case FormatCSV:
+		cw := csv.NewWriter(w)
+		return &csvWriter{w: cw, columns: cols}, cw.Write(opts.Columns)
+	case FormatXLSX:
+		return newXLSXWriter(w, opts.Columns, cols)
+	case FormatJSON:
+		bw := bufio.NewWriter(w)
+		_, err := bw.WriteString("[")
+		return &jsonWriter{w: bw, columns: cols, array: true}, err
+	case FormatNDJSON:
+		return &jsonWriter{w: bufio.NewWriter(w), columns: cols}, nil
+	}
+
+	return nil, ErrInvalidFormat
+}
+
+type csvWriter struct {
+	w       *csv.Writer
+	columns []column
+}
+
+func (cw *csvWriter) Write(r *Row) error {
+	record := make([]string, len(cw.columns))
+	for i, c := range cw.columns {
+		v, err := text(c.value(r))
+		if err != nil {
+			return err
+		}
+		record[i] = v
+	}
+
+	return cw.w.Write(record)
+}
+
+func (cw *csvWriter) Flush() error {
+	cw.w.Flush()
+	return cw.w.Error()
+}
+
+func (cw *csvWriter) Close() error {
+	return cw.Flush()
+}
+
+type xlsxWriter struct {
+	w       io.Writer
+	f       *excelize.File
+	sw      *excelize.StreamWriter
+	columns []column
+	row     int
+}
+
+func newXLSXWriter(w io.Writer, names []string, cols []column) (*xlsxWriter, error) {
+	f := excelize.NewFile()
+	if err := f.SetSheetName(f.GetSheetName(0), xlsxSheet); err != nil {
+		return nil, err
+	}
+
+	// The stream writer keeps the rows in a temporary file once they outgrow its memory buffer
+	sw, err := f.NewStreamWriter(xlsxSheet)
+	if err != nil {
+		return nil, err
+	}
+
+	header := make([]any, len(names))
+	for i, v := range names {
+		header[i] = v
+	}
+
+	if err := sw.SetRow("A1", header); err != nil {
+		return nil, err
+	}
+
+	return &xlsxWriter{w: w, f: f, sw: sw, columns: cols, row: 1}, nil
+}
+
+func (xw *xlsxWriter) Write(r *Row) error {
+	values := make([]any, len(xw.columns))
+	for i, c := range xw.columns {
+		switch v := c.value(r).(type) {
+		case int64:
+			values[i] = v
+		case *int64:
+			if v != nil {
+				values[i] = *v
+			}
+		default:
+			s, err := text(v)
+			if err != nil {
+				return err
+			}
+			values[i] = s
+		}
+	}
+
+	xw.row++
+	cell, err := excelize.CoordinatesToCellName(1, xw.row)
+	if err != nil {
+		return err
+	}
+
+	return xw.sw.SetRow(cell, values)
+}
+
+// Flush is a no-op as the file can only be written once complete.
+func (xw *xlsxWriter) Flush() error {
+	return nil
+}
+
+func (xw *xlsxWriter) Close() error {
+	defer xw.f.Close()
+
+	if err := xw.sw.Flush(); err != nil {
+		return err
+	}
+
+	return xw.f.Write(xw.w)
+}
+
+type jsonWriter struct {
+	w       *bufio.Writer
+	columns []column
+	array   bool
+	count   int
+}
+
+func (jw *jsonWriter) Write(r *Row) error {
+	if jw.array && jw.count > 0 {
+		jw.w.WriteString(",")
+	}
+	jw.count++
+
+	// The object is built by hand to keep the keys in the order of the columns
+	jw.w.WriteString("{")
+	for i, c := range jw.columns {
+		if i > 0 {
+			jw.w.WriteString(",")
+		}
+
+		key, _ := json.Marshal(c.jsonKey)
+		value, err := json.Marshal(c.value(r))
+		if err != nil {
+			return err
+		}
+
+		jw.w.Write(key)
+		jw.w.WriteString(":")
+		jw.w.Write(value)
+	}
+
+	// Errors of the buffered writer are sticky, so the last write reports the earlier ones
+	_, err := jw.w.WriteString("}")
+	if !jw.array {
+		_, err = jw.w.WriteString("\n")
+	}
+	return err
+}
+
+func (jw *jsonWriter) Flush() error {
+	return jw.w.Flush()
+}
+
+func (jw *jsonWriter) Close() error {
+	if jw.array {
+		jw.w.WriteString("]")
+	}
+	return jw.w.Flush()
+}
+
+// text formats a column value for a CSV or spreadsheet cell.
+func text(v any) (string, error) {
+	switch v := v.(type) {
+	case string:
+		return v, nil
+	case int64:
+		return strconv.FormatInt(v, 10), nil
+	case *int64:
+		if v == nil {
+			return "", nil
+		}
+		return strconv.FormatInt(*v, 10), nil
+	case *string:
+		if v == nil {
+			return "", nil
+		}
+		return *v, nil
+	case *time.Time:
+		if v == nil {
+			return "", nil
+		}
+		return v.Format(time.RFC3339), nil
+	case []string:
+		return strings.Join(v, ","), nil
+	case *model.KeywordResultsDTO:
+		if v == nil {
+			return "", nil
+		}
+		b, err := json.Marshal(v)
+		return string(b), err
+	}
+
+	return "", nil
+}
@@ -0,0 +1,131 @@
+package export_test
+
+import (
+	"bytes"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/xuri/excelize/v2"
+
+	"web-scraper.dev/internal/export"
+	"web-scraper.dev/internal/model"
+)
+
+func TestOptionsFromQuery(t *testing.T) {
+	t.Parallel()
+
+	opts, err := export.OptionsFromQuery(url.Values{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if opts.Format != export.FormatCSV || len(opts.Columns) != len(export.DefaultColumns) {
+		t.Errorf("Wrong default options: got %+v", opts)
+	}
+
+	opts, err = export.OptionsFromQuery(url.Values{"format": {"ndjson"}, "columns": {"keyword, results,keyword"}})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if opts.Format != export.FormatNDJSON || len(opts.Columns) != 2 || !opts.HasColumn(export.ColumnResults) {
+		t.Errorf("Wrong options: got %+v", opts)
+	}
+
+	if _, err := export.OptionsFromQuery(url.Values{"format": {"pdf"}}); err != export.ErrInvalidFormat {
+		t.Errorf("Wrong error: got %v want %v", err, export.ErrInvalidFormat)
+	}
+
+	if _, err := export.OptionsFromQuery(url.Values{"columns": {"keyword,html"}}); err != export.ErrInvalidColumns {
+		t.Errorf("Wrong error: got %v want %v", err, export.ErrInvalidColumns)
+	}
+}
+
+func TestNewWriter(t *testing.T) {
+	t.Parallel()
+
+	adCount := int64(2)
+	scrapedAt := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	rows := []*export.Row{
+		{Keyword: &model.Keyword{Keyword: "go lang", Status: model.KeywordStatusCompleted, AdCount: &adCount, LastScrapedAt: &scrapedAt}},
+		{Keyword: &model.Keyword{Keyword: `say "hi"`, Status: model.KeywordStatusPending}},
+	}
+	columns := []string{export.ColumnKeyword, export.ColumnStatus, export.ColumnAdCount, export.ColumnScrapedAt}
+
+	tests := []struct {
+		format   string
+		expected string
+	}{
+		{
+			format:   export.FormatCSV,
+			expected: "keyword,status,ad_count,scraped_at\ngo lang,completed,2,2025-01-02T03:04:05Z\n\"say \"\"hi\"\"\",pending,,\n",
+		},
+		{
+			format:   export.FormatJSON,
+			expected: `[{"keyword":"go lang","status":"completed","adCount":2,"scrapedAt":"2025-01-02T03:04:05Z"},{"keyword":"say \"hi\"","status":"pending","adCount":null,"scrapedAt":null}]`,
+		},
+		{
+			format:   export.FormatNDJSON,
+			expected: "{\"keyword\":\"go lang\",\"status\":\"completed\",\"adCount\":2,\"scrapedAt\":\"2025-01-02T03:04:05Z\"}\n{\"keyword\":\"say \\\"hi\\\"\",\"status\":\"pending\",\"adCount\":null,\"scrapedAt\":null}\n",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.format, func(t *testing.T) {
+			t.Parallel()
+
+			var buf bytes.Buffer
+			w, err := export.NewWriter(&buf, &export.Options{Format: tc.format, Columns: columns})
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			for _, v := range rows {
+				if err := w.Write(v); err != nil {
+					t.Fatalf("Unexpected error: %v", err)
+				}
+			}
+
+			if err := w.Close(); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			if buf.String() != tc.expected {
+				t.Errorf("Wrong output: got %v want %v", buf.String(), tc.expected)
+			}
+		})
+	}
+}
+
+func TestNewWriterXLSX(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	w, err := export.NewWriter(&buf, &export.Options{Format: export.FormatXLSX, Columns: []string{export.ColumnKeyword, export.ColumnAdCount}})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	adCount := int64(3)
+	if err := w.Write(&export.Row{Keyword: &model.Keyword{Keyword: "go lang", AdCount: &adCount}}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if err := w.Close(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	f, err := excelize.OpenReader(&buf)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	defer f.Close()
+
+	rows, err := f.GetRows(f.GetSheetName(0))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if len(rows) != 2 || rows[0][0] != "keyword" || rows[1][0] != "go lang" || rows[1][1] != "3" {
+		t.Errorf("Wrong rows: got %v", rows)
+	}
+}
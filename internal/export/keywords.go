@@ -0,0 +1,116 @@
+package export
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/google/uuid"
+
+	"web-scraper.dev/internal/model"
+	"web-scraper.dev/internal/repository"
+)
+
+const batchSize = 500
+
+// Keywords streams the keywords matching the filter as an attachment, with the results of their latest runs if selected.
+// It reports whether the response has been started; if not, the caller can still respond with an error.
+func Keywords(w http.ResponseWriter, db *repository.Db, workspaceID uuid.UUID, filter *repository.KeywordsFilter, opts *Options, filename string) (bool, error) {
+	var ew Writer
+	start := func() error {
+		w.Header().Set("Content-Type", opts.ContentType())
+		w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, opts.Filename(filename)))
+
+		var err error
+		ew, err = NewWriter(w, opts)
+		return err
+	}
+
+	rc := http.NewResponseController(w)
+	withResults := opts.HasColumn(ColumnResults)
+
+	err := db.EachKeywordsBatchByWorkspaceId(workspaceID, filter, batchSize, func(keywords model.Keywords) error {
+		var results map[int64]*model.KeywordResultsDTO
+		if withResults {
+			var err error
+			if results, err = latestResults(db, keywords); err != nil {
+				return err
+			}
+		}
+
+		if ew == nil {
+			if err := start(); err != nil {
+				return err
+			}
+		}
+
+		for _, v := range keywords {
+			if err := ew.Write(&Row{Keyword: v, Results: results[v.ID]}); err != nil {
+				return err
+			}
+		}
+
+		// Send the batch to the client instead of buffering the whole response
+		if err := ew.Flush(); err != nil {
+			return err
+		}
+		rc.Flush()
+		return nil
+	})
+	if err != nil {
+		return ew != nil, err
+	}
+
+	if ew == nil {
+		if err := start(); err != nil {
+			return true, err
+		}
+	}
+
+	return true, ew.Close()
+}
+
+// latestResults returns the results of the latest runs of the keywords by keyword ID.
+func latestResults(db *repository.Db, keywords model.Keywords) (map[int64]*model.KeywordResultsDTO, error) {
+	result := make(map[int64]*model.KeywordResultsDTO, len(keywords))
+	byScrapeID := make(map[int64]*model.KeywordResultsDTO)
+	var scrapeIDs []int64
+
+	for _, v := range keywords {
+		dto := &model.KeywordResultsDTO{
+			KeywordID:      v.ID,
+			RunID:          v.LastScrapeID,
+			OrganicResults: make([]*model.SerpResultDTO, 0),
+			Ads:            make([]*model.SerpAdDTO, 0),
+		}
+		result[v.ID] = dto
+
+		if v.LastScrapeID != nil {
+			byScrapeID[*v.LastScrapeID] = dto
+			scrapeIDs = append(scrapeIDs, *v.LastScrapeID)
+		}
+	}
+
+	if len(scrapeIDs) == 0 {
+		return result, nil
+	}
+
+	serpResults, err := db.ListSerpResultsByKeywordScrapeIds(scrapeIDs)
+	if err != nil {
+		return nil, err
+	}
+	for _, v := range serpResults {
+		dto := byScrapeID[v.KeywordScrapeID]
+		dto.OrganicResults = append(dto.OrganicResults, v.ToDTO())
+	}
+
+	serpAds, err := db.ListSerpAdsByKeywordScrapeIds(scrapeIDs)
+	if err != nil {
+		return nil, err
+	}
+	for _, v := range serpAds {
+		dto := byScrapeID[v.KeywordScrapeID]
+		dto.Ads = append(dto.Ads, v.ToDTO())
+	}
+
+	return result, nil
+}
@@ -0,0 +1,303 @@
+package fairqueue
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+// The keys of a queue share a hash tag, so they are on the same node of a Redis cluster for the scripts, which are given
+// all the keys they touch
+const (
+	fmtRingKey      = "fairqueue:{%s}:ring"
+	fmtSeqKey       = "fairqueue:{%s}:seq"
+	fmtListKey      = "fairqueue:{%s}:ws:%s"
+	fmtListKeyBase  = "fairqueue:{%s}:ws:"
+	fmtInFlightKey  = "inflight:user:%s"
+	inFlightTimeout = 10 * 60 // seconds, longer than a scrape, so the slots of the crashed workers are freed
+	popMaxAttempts  = 10
+)
+
+// pushScript appends the keywords in ARGV[2..] to the list of the workspace ARGV[1], which joins the end of the ring if it's not in it.
+var pushScript = redis.NewScript(`
+local ring, seq, list = KEYS[1], KEYS[2], KEYS[3]
+redis.call("RPUSH", list, unpack(ARGV, 2))
+if not redis.call("ZSCORE", ring, ARGV[1]) then
+	redis.call("ZADD", ring, redis.call("INCR", seq), ARGV[1])
+end
+return 0
+`)
+
+// requeueScript puts the keyword ARGV[2] back to the front of the list of the workspace ARGV[1], which joins the end of the ring
+// if it's not in it.
+var requeueScript = redis.NewScript(`
+local ring, seq, list = KEYS[1], KEYS[2], KEYS[3]
+redis.call("LPUSH", list, ARGV[2])
+if not redis.call("ZSCORE", ring, ARGV[1]) then
+	redis.call("ZADD", ring, redis.call("INCR", seq), ARGV[1])
+end
+return 0
+`)
+
+// popScript takes the next keyword of the workspace ARGV[1] read first in the ring, which moves to the end of the ring,
+// or leaves it once it has no more keywords. Returns -1 if the workspace isn't first anymore, taken by another worker meanwhile.
+var popScript = redis.NewScript(`
+local ring, seq, list, workspace = KEYS[1], KEYS[2], KEYS[3], ARGV[1]
+local first = redis.call("ZRANGE", ring, 0, 0)
+if first[1] ~= workspace then
+	return -1
+end
+
+local id = redis.call("LPOP", list)
+if redis.call("LLEN", list) == 0 then
+	redis.call("ZREM", ring, workspace)
+else
+	redis.call("ZADD", ring, redis.call("INCR", seq), workspace)
+end
+
+if not id then
+	return false
+end
+return id
+`)
+
+// removeScript removes the keyword ARGV[2] from the list of the workspace ARGV[1], which leaves the ring once it has no more keywords.
+var removeScript = redis.NewScript(`
+local ring, list = KEYS[1], KEYS[2]
+local removed = redis.call("LREM", list, 0, ARGV[2])
+if redis.call("LLEN", list) == 0 then
+	redis.call("ZREM", ring, ARGV[1])
+end
+return removed
+`)
+
+// inFlightScript takes a slot of the user in KEYS[1] for the task ARGV[1] unless the user has ARGV[2] slots taken already,
+// freeing the slots held for longer than ARGV[3] seconds first. Returns 1 if the slot is taken.
+var inFlightScript = redis.NewScript(`
+local t = redis.call("TIME")
+local now = tonumber(t[1])
+redis.call("ZREMRANGEBYSCORE", KEYS[1], "-inf", now)
+if redis.call("ZSCORE", KEYS[1], ARGV[1]) or redis.call("ZCARD", KEYS[1]) < tonumber(ARGV[2]) then
+	redis.call("ZADD", KEYS[1], now + tonumber(ARGV[3]), ARGV[1])
+	redis.call("EXPIRE", KEYS[1], tonumber(ARGV[3]))
+	return 1
+end
+return 0
+`)
+
+// Queue holds the keywords to scrape by workspace in Redis before they go to the asynq queues, taken round-robin
+// across the workspaces, so a big upload doesn't hold up the keywords of the other workspaces.
+type Queue struct {
+	rdb redis.UniversalClient
+}
+
+func New(rdb redis.UniversalClient) *Queue {
+	return &Queue{
+		rdb: rdb,
+	}
+}
+
+// Push appends the keywords of the workspace to the queue.
+func (q *Queue) Push(ctx context.Context, queue string, workspaceID uuid.UUID, keywordIDs ...int64) error {
+	if len(keywordIDs) == 0 {
+		return nil
+	}
+
+	keys := []string{fmt.Sprintf(fmtRingKey, queue), fmt.Sprintf(fmtSeqKey, queue), fmt.Sprintf(fmtListKey, queue, workspaceID)}
+	args := make([]any, 0, len(keywordIDs)+1)
+	args = append(args, workspaceID.String())
+	for _, v := range keywordIDs {
+		args = append(args, v)
+	}
+
+	return pushScript.Run(ctx, q.rdb, keys, args...).Err()
+}
+
+// Pop takes the next keyword of the queue; false if the queue is empty. The first workspace of the ring is read before
+// its keyword is taken, so the script declares the key of its list.
+func (q *Queue) Pop(ctx context.Context, queue string) (uuid.UUID, int64, bool, error) {
+	ringKey := fmt.Sprintf(fmtRingKey, queue)
+	for range popMaxAttempts {
+		first, err := q.rdb.ZRange(ctx, ringKey, 0, 0).Result()
+		if err != nil {
+			return uuid.Nil, 0, false, err
+		}
+		if len(first) == 0 {
+			return uuid.Nil, 0, false, nil
+		}
+
+		keys := []string{ringKey, fmt.Sprintf(fmtSeqKey, queue), fmt.Sprintf(fmtListKeyBase, queue) + first[0]}
+		val, err := popScript.Run(ctx, q.rdb, keys, first[0]).Result()
+		if err != nil {
+			// An empty list left in the ring has been removed from it
+			if errors.Is(err, redis.Nil) {
+				continue
+			}
+			return uuid.Nil, 0, false, err
+		}
+
+		id, ok := val.(string)
+		if !ok {
+			continue
+		}
+
+		workspaceID, err := uuid.Parse(first[0])
+		if err != nil {
+			return uuid.Nil, 0, false, err
+		}
+
+		keywordID, err := strconv.ParseInt(id, 10, 64)
+		if err != nil {
+			return uuid.Nil, 0, false, err
+		}
+
+		return workspaceID, keywordID, true, nil
+	}
+
+	// Raced by the other workers every time; the keywords are taken by the next feed
+	return uuid.Nil, 0, false, nil
+}
+
+// Requeue puts a keyword taken from the queue back to the front of the keywords of its workspace, ex: once it fails to be enqueued.
+func (q *Queue) Requeue(ctx context.Context, queue string, workspaceID uuid.UUID, keywordID int64) error {
+	keys := []string{fmt.Sprintf(fmtRingKey, queue), fmt.Sprintf(fmtSeqKey, queue), fmt.Sprintf(fmtListKey, queue, workspaceID)}
+	return requeueScript.Run(ctx, q.rdb, keys, workspaceID.String(), keywordID).Err()
+}
+
+// Remove removes the keyword of the workspace from the queues, ex: once cancelled.
+func (q *Queue) Remove(ctx context.Context, queues []string, workspaceID uuid.UUID, keywordID int64) error {
+	for _, queue := range queues {
+		keys := []string{fmt.Sprintf(fmtRingKey, queue), fmt.Sprintf(fmtListKey, queue, workspaceID)}
+		if err := removeScript.Run(ctx, q.rdb, keys, workspaceID.String(), keywordID).Err(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Positions returns the number of keywords taken before each of the keywords of the workspace in the first of the queues
+// holding it, by keyword ID; the keywords in none of them are left out. The keywords and the ring of a queue are read in
+// a pipeline, then the sizes of the workspaces of the ring.
+func (q *Queue) Positions(ctx context.Context, queues []string, workspaceID uuid.UUID, keywordIDs ...int64) (map[int64]int64, error) {
+	positions := make(map[int64]int64, len(keywordIDs))
+	for _, queue := range queues {
+		left := make([]int64, 0, len(keywordIDs))
+		for _, v := range keywordIDs {
+			if _, ok := positions[v]; !ok {
+				left = append(left, v)
+			}
+		}
+		if len(left) == 0 {
+			break
+		}
+
+		pipe := q.rdb.Pipeline()
+		list := fmt.Sprintf(fmtListKey, queue, workspaceID)
+		indexes := make([]*redis.IntCmd, len(left))
+		for i, v := range left {
+			indexes[i] = pipe.LPos(ctx, list, strconv.FormatInt(v, 10), redis.LPosArgs{})
+		}
+		ring := pipe.ZRange(ctx, fmt.Sprintf(fmtRingKey, queue), 0, -1)
+		if _, err := pipe.Exec(ctx); err != nil && !errors.Is(err, redis.Nil) {
+			return nil, err
+		}
+
+		found := make(map[int64]int64)
+		for i, v := range indexes {
+			n, err := v.Result()
+			if err != nil {
+				if errors.Is(err, redis.Nil) {
+					continue
+				}
+				return nil, err
+			}
+			found[left[i]] = n
+		}
+		if len(found) == 0 {
+			continue
+		}
+
+		workspaces, err := ring.Result()
+		if err != nil {
+			return nil, err
+		}
+
+		pipe = q.rdb.Pipeline()
+		lens := make([]*redis.IntCmd, len(workspaces))
+		for i, v := range workspaces {
+			lens[i] = pipe.LLen(ctx, fmt.Sprintf(fmtListKeyBase, queue)+v)
+		}
+		if _, err := pipe.Exec(ctx); err != nil {
+			return nil, err
+		}
+
+		own := -1
+		sizes := make([]int64, len(workspaces))
+		for i, v := range workspaces {
+			sizes[i] = lens[i].Val()
+			if v == workspaceID.String() {
+				own = i
+			}
+		}
+
+		for id, n := range found {
+			positions[id] = Ahead(n, sizes, own)
+		}
+	}
+
+	return positions, nil
+}
+
+// Ahead returns the number of keywords taken before the one with n keywords of its workspace before it, by the round-robin
+// over the workspaces of the ring with the given sizes, its workspace at index own. Each workspace before its workspace
+// has up to n+1 keywords taken before it and each one after up to n.
+func Ahead(n int64, sizes []int64, own int) int64 {
+	ahead := n
+	for i, size := range sizes {
+		switch {
+		case i < own:
+			ahead += min(size, n+1)
+		case i > own:
+			ahead += min(size, n)
+		}
+	}
+
+	return ahead
+}
+
+// InFlight caps the number of the scrapes of each user being processed at once across the workers.
+type InFlight struct {
+	rdb redis.UniversalClient
+	max int
+}
+
+func NewInFlight(rdb redis.UniversalClient, maxInFlight int) *InFlight {
+	return &InFlight{
+		rdb: rdb,
+		max: maxInFlight,
+	}
+}
+
+// Acquire takes a slot of the user for the task, reporting false if the user has all the slots taken already.
+func (f *InFlight) Acquire(ctx context.Context, userID uuid.UUID, taskID string) (bool, error) {
+	if f.max <= 0 {
+		return true, nil
+	}
+
+	ok, err := inFlightScript.Run(ctx, f.rdb, []string{fmt.Sprintf(fmtInFlightKey, userID)}, taskID, f.max, inFlightTimeout).Int()
+	return ok == 1, err
+}
+
+// Release frees the slot of the user taken for the task.
+func (f *InFlight) Release(ctx context.Context, userID uuid.UUID, taskID string) error {
+	if f.max <= 0 {
+		return nil
+	}
+
+	return f.rdb.ZRem(ctx, fmt.Sprintf(fmtInFlightKey, userID), taskID).Err()
+}
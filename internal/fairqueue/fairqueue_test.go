@@ -0,0 +1,175 @@
+package fairqueue_test
+
+import (
+	"context"
+	"maps"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+
+	"web-scraper.dev/internal/fairqueue"
+)
+
+func TestAhead(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		n        int64
+		sizes    []int64
+		own      int
+		expected int64
+	}{
+		{"alone", 5, []int64{100}, 0, 5},
+		{"first of a small upload behind a big one", 0, []int64{100, 10}, 1, 1},
+		{"last of a small upload behind a big one", 9, []int64{100, 10}, 1, 19},
+		{"big upload before a small one", 50, []int64{100, 10}, 0, 60},
+		{"middle of the ring", 2, []int64{1, 5, 3, 2}, 1, 7},
+	}
+
+	for _, tc := range tests {
+		if ahead := fairqueue.Ahead(tc.n, tc.sizes, tc.own); ahead != tc.expected {
+			t.Errorf("Wrong keywords ahead for %s: got %d want %d", tc.name, ahead, tc.expected)
+		}
+	}
+}
+
+func newQueue(t *testing.T) (*miniredis.Miniredis, redis.UniversalClient) {
+	t.Helper()
+
+	mr := miniredis.RunT(t)
+	rdb := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { rdb.Close() })
+
+	return mr, rdb
+}
+
+func TestQueue(t *testing.T) {
+	t.Parallel()
+
+	_, rdb := newQueue(t)
+	q := fairqueue.New(rdb)
+	ctx := context.Background()
+	big, small := uuid.New(), uuid.New()
+
+	if err := q.Push(ctx, "default:bing", big, 1, 2, 3, 4); err != nil {
+		t.Fatal(err)
+	}
+	if err := q.Push(ctx, "default:bing", small, 10, 11); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := q.Push(ctx, "critical:bing", small, 12); err != nil {
+		t.Fatal(err)
+	}
+
+	queues := []string{"critical:bing", "default:bing"}
+	positions, err := q.Positions(ctx, queues, small, 10, 11, 12, 13)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := map[int64]int64{10: 1, 11: 3, 12: 0}; !maps.Equal(positions, expected) {
+		t.Errorf("Wrong positions: got %v want %v", positions, expected)
+	}
+
+	positions, err = q.Positions(ctx, queues, big, 1, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := map[int64]int64{1: 0, 4: 5}; !maps.Equal(positions, expected) {
+		t.Errorf("Wrong positions: got %v want %v", positions, expected)
+	}
+
+	if err := q.Remove(ctx, queues, small, 12); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := q.Remove(ctx, queues, big, 3); err != nil {
+		t.Fatal(err)
+	}
+
+	// The keywords of the workspaces are taken in turn, the first one failing to be enqueued being put back first of its workspace
+	expected := []struct {
+		workspaceID uuid.UUID
+		keywordID   int64
+		requeue     bool
+	}{
+		{big, 1, true},
+		{small, 10, false},
+		{big, 1, false},
+		{small, 11, false},
+		{big, 2, false},
+		{big, 4, false},
+	}
+
+	for i, v := range expected {
+		workspaceID, keywordID, ok, err := q.Pop(ctx, "default:bing")
+		if err != nil || !ok {
+			t.Fatalf("Failed to pop %d: ok %v err %v", i, ok, err)
+		}
+		if workspaceID != v.workspaceID || keywordID != v.keywordID {
+			t.Errorf("Wrong keyword %d: got %v %d want %v %d", i, workspaceID, keywordID, v.workspaceID, v.keywordID)
+		}
+
+		if v.requeue {
+			if err := q.Requeue(ctx, "default:bing", workspaceID, keywordID); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	if _, _, ok, err := q.Pop(ctx, "default:bing"); ok || err != nil {
+		t.Errorf("Queue not empty: ok %v err %v", ok, err)
+	}
+
+	positions, err = q.Positions(ctx, queues, small, 11)
+	if err != nil || len(positions) != 0 {
+		t.Errorf("Wrong position of a popped keyword: got %v err %v", positions, err)
+	}
+}
+
+func TestInFlight(t *testing.T) {
+	t.Parallel()
+
+	mr, rdb := newQueue(t)
+	f := fairqueue.NewInFlight(rdb, 2)
+	ctx := context.Background()
+	user := uuid.New()
+	now := time.Now()
+	mr.SetTime(now)
+
+	for i, taskID := range []string{"a", "b", "c"} {
+		acquired, err := f.Acquire(ctx, user, taskID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if acquired != (i < 2) {
+			t.Errorf("Wrong acquire of %s: got %v want %v", taskID, acquired, i < 2)
+		}
+	}
+
+	// A retried task keeps its slot
+	if acquired, _ := f.Acquire(ctx, user, "a"); !acquired {
+		t.Error("Slot of a task not kept")
+	}
+
+	if acquired, _ := f.Acquire(ctx, uuid.New(), "d"); !acquired {
+		t.Error("Slot of another user not acquired")
+	}
+
+	if err := f.Release(ctx, user, "b"); err != nil {
+		t.Fatal(err)
+	}
+	if acquired, _ := f.Acquire(ctx, user, "c"); !acquired {
+		t.Error("Released slot not acquired")
+	}
+
+	// The slots of the crashed workers are freed once timed out
+	mr.SetTime(now.Add(11 * time.Minute))
+	if acquired, _ := f.Acquire(ctx, user, "e"); !acquired {
+		t.Error("Timed out slot not freed")
+	}
+}
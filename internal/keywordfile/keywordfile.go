@@ -0,0 +1,359 @@
+package keywordfile
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"path/filepath"
+	"strings"
+	"unicode"
+
+	"github.com/xuri/excelize/v2"
+)
+
+const (
+	FormatCSV  = "csv"
+	FormatTSV  = "tsv"
+	FormatJSON = "json"
+	FormatXLSX = "xlsx"
+)
+
+const (
+	ColumnKeyword      = "keyword"
+	ColumnSearchEngine = "search_engine"
+	ColumnMarket       = "market"
+	ColumnDevice       = "device"
+	ColumnTags         = "tags"
+	ColumnSchedule     = "schedule"
+)
+
+var (
+	ErrUnsupportedFormat = errors.New("unsupported file format")
+	ErrEmpty             = errors.New("no keywords found")
+)
+
+var utf8BOM = []byte("\xef\xbb\xbf")
+
+// Header names are matched after removing everything but letters, ex: "Search Engine" and "search_engine".
+var columnNames = map[string]string{
+	"keyword":      ColumnKeyword,
+	"keywords":     ColumnKeyword,
+	"searchengine": ColumnSearchEngine,
+	"engine":       ColumnSearchEngine,
+	"market":       ColumnMarket,
+	"locale":       ColumnMarket,
+	"device":       ColumnDevice,
+	"tags":         ColumnTags,
+	"tag":          ColumnTags,
+	"schedule":     ColumnSchedule,
+}
+
+var contentTypeFormats = map[string]string{
+	"text/csv":                  FormatCSV,
+	"text/plain":                FormatCSV,
+	"application/csv":           FormatCSV,
+	"text/tab-separated-values": FormatTSV,
+	"application/json":          FormatJSON,
+	"application/vnd.openxmlformats-officedocument.spreadsheetml.sheet": FormatXLSX,
+}
+
+var extensionFormats = map[string]string{
+	".csv":  FormatCSV,
+	".txt":  FormatCSV,
+	".tsv":  FormatTSV,
+	".tab":  FormatTSV,
+	".json": FormatJSON,
+	".xlsx": FormatXLSX,
+}
+
+// Record is a keyword row of an uploaded file. Values are trimmed but not validated.
+type Record struct {
+	// Line is the line number in CSV and TSV files, the row number in spreadsheets and the item number in JSON arrays.
+	Line         int
+	Keyword      string
+	SearchEngine string
+	Market       string
+	Device       string
+	Tags         []string
+	Schedule     string
+}
+
+type LineError struct {
+	Line int
+	Err  error
+}
+
+func (e *LineError) Error() string {
+	return fmt.Sprintf("line %d: %v", e.Line, e.Err)
+}
+
+func (e *LineError) Unwrap() error {
+	return e.Err
+}
+
+// FormatFromContentType returns the format of a request body with the given content type.
+func FormatFromContentType(contentType string) (string, bool) {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return "", false
+	}
+
+	format, ok := contentTypeFormats[mediaType]
+	return format, ok
+}
+
+// FormatFromFilename returns the format of an uploaded file by its extension; files without a known extension are read as CSV.
+func FormatFromFilename(filename string) string {
+	if format, ok := extensionFormats[strings.ToLower(filepath.Ext(filename))]; ok {
+		return format
+	}
+	return FormatCSV
+}
+
+// Read reads the keyword records of a file in the given format.
+//
+// CSV, TSV and spreadsheet files may start with a header row naming the columns; it is detected by a keyword column.
+// Without a header, only the first column is read as the keyword.
+// JSON files are arrays of keywords or of objects with keyword, searchEngine, market, device, tags and schedule fields.
+func Read(r io.Reader, format string) ([]*Record, error) {
+	var records []*Record
+	var err error
+
+	switch format {
+	case FormatCSV, FormatTSV:
+		records, err = readCSV(r, format == FormatTSV)
+	case FormatJSON:
+		records, err = readJSON(r)
+	case FormatXLSX:
+		records, err = readXLSX(r)
+	default:
+		return nil, ErrUnsupportedFormat
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if len(records) == 0 {
+		return nil, ErrEmpty
+	}
+
+	return records, nil
+}
+
+func readCSV(r io.Reader, tsv bool) ([]*Record, error) {
+	br := bufio.NewReader(r)
+
+	// Excel prepends a byte order mark to UTF-8 CSV exports
+	if b, err := br.Peek(len(utf8BOM)); err == nil && bytes.Equal(b, utf8BOM) {
+		br.Discard(len(utf8BOM))
+	}
+
+	reader := csv.NewReader(br)
+	reader.FieldsPerRecord = -1
+	reader.Comma = ','
+	if tsv {
+		reader.Comma = '\t'
+	} else if b, _ := br.Peek(br.Size()); len(b) > 0 {
+		reader.Comma = sniffDelimiter(b)
+	}
+
+	var rows [][]string
+	var lines []int
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			var parseErr *csv.ParseError
+			if errors.As(err, &parseErr) {
+				return nil, &LineError{Line: parseErr.Line, Err: parseErr.Err}
+			}
+			return nil, err
+		}
+
+		line, _ := reader.FieldPos(0)
+		rows = append(rows, record)
+		lines = append(lines, line)
+	}
+
+	return fromRows(rows, lines), nil
+}
+
+// sniffDelimiter picks the most frequent of comma, semicolon and tab in the first line.
+// Excel uses semicolons in locales where the comma is the decimal separator.
+func sniffDelimiter(b []byte) rune {
+	if i := bytes.IndexByte(b, '\n'); i >= 0 {
+		b = b[:i]
+	}
+
+	delimiter, count := ',', bytes.Count(b, []byte{','})
+	for _, v := range []rune{';', '\t'} {
+		if n := bytes.Count(b, []byte(string(v))); n > count {
+			delimiter, count = v, n
+		}
+	}
+
+	return delimiter
+}
+
+func readXLSX(r io.Reader) ([]*Record, error) {
+	f, err := excelize.OpenReader(r)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	sheets := f.GetSheetList()
+	if len(sheets) == 0 {
+		return nil, nil
+	}
+
+	xlsxRows, err := f.Rows(sheets[0])
+	if err != nil {
+		return nil, err
+	}
+	defer xlsxRows.Close()
+
+	var rows [][]string
+	var lines []int
+	for line := 1; xlsxRows.Next(); line++ {
+		row, err := xlsxRows.Columns()
+		if err != nil {
+			return nil, &LineError{Line: line, Err: err}
+		}
+
+		if isBlank(row) {
+			continue
+		}
+
+		rows = append(rows, row)
+		lines = append(lines, line)
+	}
+
+	return fromRows(rows, lines), xlsxRows.Error()
+}
+
+type jsonRecord struct {
+	Keyword      string   `json:"keyword"`
+	SearchEngine string   `json:"searchEngine"`
+	Market       string   `json:"market"`
+	Device       string   `json:"device"`
+	Tags         []string `json:"tags"`
+	Schedule     string   `json:"schedule"`
+}
+
+func readJSON(r io.Reader) ([]*Record, error) {
+	var items []json.RawMessage
+	if err := json.NewDecoder(r).Decode(&items); err != nil {
+		return nil, err
+	}
+
+	records := make([]*Record, len(items))
+	for i, item := range items {
+		var v jsonRecord
+		if err := json.Unmarshal(item, &v.Keyword); err != nil {
+			if err := json.Unmarshal(item, &v); err != nil {
+				return nil, &LineError{Line: i + 1, Err: errors.New("must be a keyword or an object")}
+			}
+		}
+
+		records[i] = &Record{
+			Line:         i + 1,
+			Keyword:      strings.TrimSpace(v.Keyword),
+			SearchEngine: strings.TrimSpace(v.SearchEngine),
+			Market:       strings.TrimSpace(v.Market),
+			Device:       strings.TrimSpace(v.Device),
+			Tags:         trimTags(v.Tags),
+			Schedule:     strings.TrimSpace(v.Schedule),
+		}
+	}
+
+	return records, nil
+}
+
+// fromRows converts the rows of a CSV, TSV or spreadsheet file to records, using the header row if any.
+func fromRows(rows [][]string, lines []int) []*Record {
+	columns := map[string]int{ColumnKeyword: 0}
+	if len(rows) > 0 {
+		if header, ok := headerColumns(rows[0]); ok {
+			columns, rows, lines = header, rows[1:], lines[1:]
+		}
+	}
+
+	cell := func(row []string, column string) string {
+		i, ok := columns[column]
+		if !ok || i >= len(row) {
+			return ""
+		}
+		return strings.TrimSpace(row[i])
+	}
+
+	records := make([]*Record, len(rows))
+	for i, row := range rows {
+		records[i] = &Record{
+			Line:         lines[i],
+			Keyword:      cell(row, ColumnKeyword),
+			SearchEngine: cell(row, ColumnSearchEngine),
+			Market:       cell(row, ColumnMarket),
+			Device:       cell(row, ColumnDevice),
+			Tags:         splitTags(cell(row, ColumnTags)),
+			Schedule:     cell(row, ColumnSchedule),
+		}
+	}
+
+	return records
+}
+
+// headerColumns returns the column indexes by name if the row is a header, which must name a keyword column.
+func headerColumns(row []string) (map[string]int, bool) {
+	columns := make(map[string]int)
+	for i, v := range row {
+		name := strings.Map(func(r rune) rune {
+			if unicode.IsLetter(r) {
+				return unicode.ToLower(r)
+			}
+			return -1
+		}, v)
+
+		if column, ok := columnNames[name]; ok {
+			if _, exists := columns[column]; !exists {
+				columns[column] = i
+			}
+		}
+	}
+
+	_, ok := columns[ColumnKeyword]
+	return columns, ok
+}
+
+func splitTags(v string) []string {
+	return trimTags(strings.FieldsFunc(v, func(r rune) bool {
+		return r == ',' || r == ';' || r == '|'
+	}))
+}
+
+func trimTags(tags []string) []string {
+	var result []string
+	for _, v := range tags {
+		if v = strings.TrimSpace(v); v != "" {
+			result = append(result, v)
+		}
+	}
+	return result
+}
+
+func isBlank(row []string) bool {
+	for _, v := range row {
+		if strings.TrimSpace(v) != "" {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,157 @@
+package keywordfile_test
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/xuri/excelize/v2"
+
+	"web-scraper.dev/internal/keywordfile"
+)
+
+func TestRead(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		format   string
+		input    string
+		expected []keywordfile.Record
+	}{
+		{
+			name:   "csv without header",
+			format: keywordfile.FormatCSV,
+			input:  "go lang,ignored\nweb scraper\n",
+			expected: []keywordfile.Record{
+				{Line: 1, Keyword: "go lang"},
+				{Line: 2, Keyword: "web scraper"},
+			},
+		},
+		{
+			name:   "csv with header",
+			format: keywordfile.FormatCSV,
+			input:  "Tags,Keyword,Search Engine,market,device,schedule\n\"a, b\",go lang,google,en-US,mobile,daily\n,web scraper\n",
+			expected: []keywordfile.Record{
+				{Line: 2, Keyword: "go lang", SearchEngine: "google", Market: "en-US", Device: "mobile", Tags: []string{"a", "b"}, Schedule: "daily"},
+				{Line: 3, Keyword: "web scraper"},
+			},
+		},
+		{
+			name:   "excel export",
+			format: keywordfile.FormatCSV,
+			input:  "\xef\xbb\xbfkeyword;tags\r\ngo, lang;a|b\r\n",
+			expected: []keywordfile.Record{
+				{Line: 2, Keyword: "go, lang", Tags: []string{"a", "b"}},
+			},
+		},
+		{
+			name:   "tsv",
+			format: keywordfile.FormatTSV,
+			input:  "keyword\tsearch_engine\ngo, lang\tbing\n",
+			expected: []keywordfile.Record{
+				{Line: 2, Keyword: "go, lang", SearchEngine: "bing"},
+			},
+		},
+		{
+			name:   "json",
+			format: keywordfile.FormatJSON,
+			input:  `[" go lang ", {"keyword": "web scraper", "searchEngine": "duckduckgo", "tags": ["a"]}]`,
+			expected: []keywordfile.Record{
+				{Line: 1, Keyword: "go lang"},
+				{Line: 2, Keyword: "web scraper", SearchEngine: "duckduckgo", Tags: []string{"a"}},
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			records, err := keywordfile.Read(strings.NewReader(tc.input), tc.format)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			assertRecords(t, records, tc.expected)
+		})
+	}
+}
+
+func TestReadXLSX(t *testing.T) {
+	t.Parallel()
+
+	f := excelize.NewFile()
+	defer f.Close()
+
+	sheet := f.GetSheetName(0)
+	f.SetSheetRow(sheet, "A1", &[]any{"keyword", "device"})
+	f.SetSheetRow(sheet, "A2", &[]any{"go lang", "mobile"})
+	f.SetSheetRow(sheet, "A4", &[]any{"web scraper"})
+
+	var buf bytes.Buffer
+	if err := f.Write(&buf); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	records, err := keywordfile.Read(&buf, keywordfile.FormatXLSX)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	assertRecords(t, records, []keywordfile.Record{
+		{Line: 2, Keyword: "go lang", Device: "mobile"},
+		{Line: 4, Keyword: "web scraper"},
+	})
+}
+
+func TestReadErrors(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		format string
+		input  string
+		line   int
+	}{
+		{"csv", keywordfile.FormatCSV, "go lang\nweb \"scraper\n", 2},
+		{"json", keywordfile.FormatJSON, `["go lang", 1]`, 2},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := keywordfile.Read(strings.NewReader(tc.input), tc.format)
+
+			var lineErr *keywordfile.LineError
+			if !errors.As(err, &lineErr) {
+				t.Fatalf("Wrong error: got %v want a line error", err)
+			}
+
+			if lineErr.Line != tc.line {
+				t.Errorf("Wrong line: got %v want %v", lineErr.Line, tc.line)
+			}
+		})
+	}
+
+	if _, err := keywordfile.Read(strings.NewReader("keyword\n"), keywordfile.FormatCSV); err != keywordfile.ErrEmpty {
+		t.Errorf("Wrong error: got %v want %v", err, keywordfile.ErrEmpty)
+	}
+}
+
+func assertRecords(t *testing.T, records []*keywordfile.Record, expected []keywordfile.Record) {
+	t.Helper()
+
+	if len(records) != len(expected) {
+		t.Fatalf("Wrong record count: got %v want %v", len(records), len(expected))
+	}
+
+	for i, v := range records {
+		if !reflect.DeepEqual(*v, expected[i]) {
+			t.Errorf("Wrong record: got %+v want %+v", *v, expected[i])
+		}
+	}
+}
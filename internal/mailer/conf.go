@@ -11,7 +11,11 @@ var (
 	flags = flag.NewFlagSet("mail", flag.ExitOnError)
 	dir   = flags.String("dir", "internal/mailer/tmpl", "directory with mail templates")
 
-	tmplActivationEmail = fmt.Sprintf("%s/%s", *dir, "activation-email.html")
+	tmplActivationEmail          = fmt.Sprintf("%s/%s", *dir, "activation-email.html")
+	tmplResetPasswordEmail       = fmt.Sprintf("%s/%s", *dir, "reset-password-email.html")
+	tmplLockoutEmail             = fmt.Sprintf("%s/%s", *dir, "lockout-email.html")
+	tmplUploadSummaryEmail       = fmt.Sprintf("%s/%s", *dir, "upload-summary-email.html")
+	tmplWorkspaceInvitationEmail = fmt.Sprintf("%s/%s", *dir, "workspace-invitation-email.html")
 )
 
 type Conf struct {
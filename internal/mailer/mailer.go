@@ -4,7 +4,9 @@ import (
 	"bytes"
 	"errors"
 	"fmt"
+	"mime"
 	"net/smtp"
+	"strings"
 )
 
 var ErrNoTmpl = errors.New("no tmpl")
@@ -52,3 +54,21 @@ func newMail(from string, to string, subject string, body *bytes.Buffer) *mail {
 func (m *mail) ToRfc822Msg() string {
 	return fmt.Sprintf(fmtRfc822Msg, m.from, m.to, m.subject, m.body)
 }
+
+func (ml *Mailer) send(to string, subject string, body *bytes.Buffer) error {
+	from := ml.Senders.NoReply
+
+	// Subjects may have user data, ex: file names
+	subject = strings.NewReplacer("\r", " ", "\n", " ").Replace(subject)
+	subject = mime.QEncoding.Encode("UTF-8", subject)
+
+	mail := newMail(from, to, subject, body)
+	rfc822Msg := mail.ToRfc822Msg()
+
+	auth := ml.Auth
+	if strings.Contains(ml.Addr, "mailhog") {
+		auth = nil
+	}
+
+	return smtp.SendMail(ml.Addr, auth, from, []string{to}, []byte(rfc822Msg))
+}
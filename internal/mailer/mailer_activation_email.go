@@ -3,8 +3,6 @@ package mailer
 import (
 	"bytes"
 	"fmt"
-	"net/smtp"
-	"strings"
 	"text/template"
 )
 
@@ -19,7 +17,6 @@ type ActivationEmail struct {
 }
 
 func (ml *Mailer) ActivationMail(userEmail string, token string) error {
-	from := ml.Senders.NoReply
 	to := userEmail
 	subject := titleActivationEmail
 	data := &ActivationEmail{
@@ -37,13 +34,5 @@ func (ml *Mailer) ActivationMail(userEmail string, token string) error {
 		return err
 	}
 
-	mail := newMail(from, to, subject, wr)
-	rfc822Msg := mail.ToRfc822Msg()
-
-	auth := ml.Auth
-	if strings.Contains(ml.Addr, "mailhog") {
-		auth = nil
-	}
-
-	return smtp.SendMail(ml.Addr, auth, from, []string{to}, []byte(rfc822Msg))
+	return ml.send(to, subject, wr)
 }
@@ -0,0 +1,36 @@
+package mailer
+
+import (
+	"bytes"
+	"html/template"
+	"time"
+)
+
+const titleLockoutEmail = "Account Locked Email"
+
+type LockoutEmail struct {
+	Action      string
+	LockedUntil string
+}
+
+// LockoutMail tells the owner of an account it's locked after too many failed attempts to do the action, e.g. "sign in to".
+func (ml *Mailer) LockoutMail(userEmail string, action string, lockedUntil time.Time) error {
+	to := userEmail
+	subject := titleLockoutEmail
+	data := &LockoutEmail{
+		Action:      action,
+		LockedUntil: lockedUntil.UTC().Format("2006-01-02 15:04 MST"),
+	}
+
+	wr := new(bytes.Buffer)
+	t, err := template.ParseFiles(tmplLockoutEmail)
+	if err != nil {
+		return ErrNoTmpl
+	}
+
+	if err := t.Execute(wr, data); err != nil {
+		return err
+	}
+
+	return ml.send(to, subject, wr)
+}
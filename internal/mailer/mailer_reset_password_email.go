@@ -0,0 +1,39 @@
+package mailer
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"net/url"
+)
+
+const (
+	titleResetPasswordEmail = "Reset Password Email"
+	fmtResetPasswordLink    = "%s/users/reset-password?email=%s&token=%s"
+)
+
+type ResetPasswordEmail struct {
+	ResetPasswordCode string
+	ResetPasswordLink string
+}
+
+func (ml *Mailer) ResetPasswordMail(userEmail string, token string) error {
+	to := userEmail
+	subject := titleResetPasswordEmail
+	data := &ResetPasswordEmail{
+		ResetPasswordCode: token,
+		ResetPasswordLink: fmt.Sprintf(fmtResetPasswordLink, ml.Links.WebsiteHost, url.QueryEscape(to), url.QueryEscape(token)),
+	}
+
+	wr := new(bytes.Buffer)
+	t, err := template.ParseFiles(tmplResetPasswordEmail)
+	if err != nil {
+		return ErrNoTmpl
+	}
+
+	if err := t.Execute(wr, data); err != nil {
+		return err
+	}
+
+	return ml.send(to, subject, wr)
+}
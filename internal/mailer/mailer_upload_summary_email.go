@@ -0,0 +1,47 @@
+package mailer
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+)
+
+const (
+	fmtTitleUploadSummaryEmail = "Your upload %s has finished"
+	fmtUploadKeywordsLink      = "%s/keywords?upload_id=%d"
+)
+
+type UploadSummaryEmail struct {
+	Filename      string
+	Total         int64
+	Completed     int64
+	Failed        int64
+	Cancelled     int64
+	TopKeywords   []*UploadSummaryKeyword
+	DashboardLink string
+}
+
+type UploadSummaryKeyword struct {
+	Keyword      string
+	SearchEngine string
+	AdCount      int64
+	LinkCount    int64
+}
+
+// UploadSummaryMail sends the summary of a finished upload. The template is rendered with html/template, as it has the keywords of the user.
+func (ml *Mailer) UploadSummaryMail(userEmail string, uploadID int64, data *UploadSummaryEmail) error {
+	subject := fmt.Sprintf(fmtTitleUploadSummaryEmail, data.Filename)
+	data.DashboardLink = fmt.Sprintf(fmtUploadKeywordsLink, ml.Links.WebsiteHost, uploadID)
+
+	wr := new(bytes.Buffer)
+	t, err := template.ParseFiles(tmplUploadSummaryEmail)
+	if err != nil {
+		return ErrNoTmpl
+	}
+
+	if err := t.Execute(wr, data); err != nil {
+		return err
+	}
+
+	return ml.send(userEmail, subject, wr)
+}
@@ -0,0 +1,43 @@
+package mailer
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"net/url"
+)
+
+const (
+	titleWorkspaceInvitationEmail = "Workspace Invitation Email"
+	fmtWorkspaceInvitationLink    = "%s/workspaces/invitations/accept?token=%s"
+)
+
+type WorkspaceInvitationEmail struct {
+	WorkspaceName  string
+	InvitedBy      string
+	Role           string
+	InvitationLink string
+}
+
+func (ml *Mailer) WorkspaceInvitationMail(email, workspaceName, invitedBy, role, token string) error {
+	to := email
+	subject := titleWorkspaceInvitationEmail
+	data := &WorkspaceInvitationEmail{
+		WorkspaceName:  workspaceName,
+		InvitedBy:      invitedBy,
+		Role:           role,
+		InvitationLink: fmt.Sprintf(fmtWorkspaceInvitationLink, ml.Links.WebsiteHost, url.QueryEscape(token)),
+	}
+
+	wr := new(bytes.Buffer)
+	t, err := template.ParseFiles(tmplWorkspaceInvitationEmail)
+	if err != nil {
+		return ErrNoTmpl
+	}
+
+	if err := t.Execute(wr, data); err != nil {
+		return err
+	}
+
+	return ml.send(to, subject, wr)
+}
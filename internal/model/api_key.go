@@ -0,0 +1,60 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// APIKeyScopeRead allows only reading the keywords and the uploads
+	APIKeyScopeRead = "read"
+	// APIKeyScopeUpload allows uploading, rescraping, cancelling and scheduling the keywords too
+	APIKeyScopeUpload = "upload"
+)
+
+type APIKeys []*APIKey
+
+type APIKey struct {
+	Model2
+	UserID     uuid.UUID
+	Name       string
+	Prefix     string
+	KeyHash    string
+	Scope      string
+	LastUsedAt *time.Time
+	RevokedAt  *time.Time
+}
+
+type APIKeyDTO struct {
+	ID         int64      `json:"id"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`
+	Key        string     `json:"key,omitempty"`
+	Scope      string     `json:"scope"`
+	LastUsedAt *time.Time `json:"lastUsedAt"`
+	RevokedAt  *time.Time `json:"revokedAt"`
+	CreatedAt  *time.Time `json:"createdAt"`
+}
+
+func (ks APIKeys) ToDTOs() []*APIKeyDTO {
+	result := make([]*APIKeyDTO, len(ks))
+	for i, v := range ks {
+		result[i] = v.ToDTO()
+	}
+
+	return result
+}
+
+// ToDTO returns the API key with its prefix only; the key itself is shown once it's created and never stored.
+func (k *APIKey) ToDTO() *APIKeyDTO {
+	return &APIKeyDTO{
+		ID:         k.ID,
+		Name:       k.Name,
+		Prefix:     k.Prefix,
+		Scope:      k.Scope,
+		LastUsedAt: k.LastUsedAt,
+		RevokedAt:  k.RevokedAt,
+		CreatedAt:  k.CreatedAt,
+	}
+}
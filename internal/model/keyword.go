@@ -1,31 +1,67 @@
 package model
 
 import (
+	"time"
+
 	"github.com/google/uuid"
 )
 
+const (
+	KeywordStatusPending    = "pending"
+	KeywordStatusProcessing = "processing"
+	KeywordStatusCompleted  = "completed"
+	KeywordStatusFailed     = "failed"
+	KeywordStatusCancelled  = "cancelled"
+)
+
+const (
+	KeywordDeviceDesktop = "desktop"
+	KeywordDeviceMobile  = "mobile"
+)
+
 type Keywords []*Keyword
 
 type Keyword struct {
 	Model2
-	UserID       uuid.UUID
-	Keyword      string
-	Status       string
-	SearchEngine string
-	AdCount      *int64
-	LinkCount    *int64
-	HTMLContent  *string
-	ErrorMessage *string
+	UserID         uuid.UUID
+	WorkspaceID    uuid.UUID
+	Keyword        string
+	Status         string
+	SearchEngine   string
+	Market         string
+	Device         string   `gorm:"default:desktop"`
+	Tags           []string `gorm:"serializer:json"`
+	AdCount        *int64
+	LinkCount      *int64
+	HTMLContent    *string
+	ErrorMessage   *string
+	Classification *string
+	LastScrapeID   *int64
+	Schedule       *string
+	NextScrapeAt   *time.Time
+	LastScrapedAt  *time.Time
+	UploadID       *int64
 }
 
 type KeywordDTO struct {
-	ID           int64   `json:"id"`
-	Keyword      string  `json:"keyword"`
-	Status       string  `json:"status"`
-	AdCount      *int64  `json:"adCount"`
-	LinkCount    *int64  `json:"linkCount"`
-	HTMLContent  *string `json:"htmlContent"`
-	ErrorMessage *string `json:"errorMessage"`
+	ID             int64      `json:"id"`
+	Keyword        string     `json:"keyword"`
+	Status         string     `json:"status"`
+	SearchEngine   string     `json:"searchEngine"`
+	Market         string     `json:"market"`
+	Device         string     `json:"device"`
+	Tags           []string   `json:"tags"`
+	AdCount        *int64     `json:"adCount"`
+	LinkCount      *int64     `json:"linkCount"`
+	HTMLContent    *string    `json:"htmlContent,omitempty"`
+	ErrorMessage   *string    `json:"errorMessage"`
+	Classification *string    `json:"classification"`
+	LastRunID      *int64     `json:"lastRunId"`
+	Schedule       *string    `json:"schedule"`
+	NextRunAt      *time.Time `json:"nextRunAt"`
+	LastRunAt      *time.Time `json:"lastRunAt"`
+	UploadID       *int64     `json:"uploadId"`
+	QueuePosition  *int64     `json:"queuePosition,omitempty"`
 }
 
 func (ks Keywords) ToDTOs() []*KeywordDTO {
@@ -37,14 +73,24 @@ func (ks Keywords) ToDTOs() []*KeywordDTO {
 	return result
 }
 
+// ToDTO converts the keyword without its HTML content, which is served by a dedicated endpoint.
 func (k *Keyword) ToDTO() *KeywordDTO {
 	return &KeywordDTO{
-		ID:           k.ID,
-		Keyword:      k.Keyword,
-		Status:       k.Status,
-		AdCount:      k.AdCount,
-		LinkCount:    k.LinkCount,
-		HTMLContent:  k.HTMLContent,
-		ErrorMessage: k.ErrorMessage,
+		ID:             k.ID,
+		Keyword:        k.Keyword,
+		Status:         k.Status,
+		SearchEngine:   k.SearchEngine,
+		Market:         k.Market,
+		Device:         k.Device,
+		Tags:           k.Tags,
+		AdCount:        k.AdCount,
+		LinkCount:      k.LinkCount,
+		ErrorMessage:   k.ErrorMessage,
+		Classification: k.Classification,
+		LastRunID:      k.LastScrapeID,
+		Schedule:       k.Schedule,
+		NextRunAt:      k.NextScrapeAt,
+		LastRunAt:      k.LastScrapedAt,
+		UploadID:       k.UploadID,
 	}
 }
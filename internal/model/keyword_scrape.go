@@ -0,0 +1,73 @@
+package model
+
+import (
+	"time"
+)
+
+type KeywordScrapes []*KeywordScrape
+
+type KeywordScrape struct {
+	Model2
+	KeywordID      int64
+	Status         string
+	SearchEngine   string
+	WorkerHost     string `gorm:"default:null"`
+	Proxy          *string
+	AdCount        *int64
+	LinkCount      *int64
+	ErrorMessage   *string
+	Classification *string
+	StartedAt      *time.Time
+	FinishedAt     *time.Time
+}
+
+type KeywordScrapeDTO struct {
+	ID             int64      `json:"id"`
+	Status         string     `json:"status"`
+	SearchEngine   string     `json:"searchEngine"`
+	WorkerHost     string     `json:"workerHost"`
+	Proxy          *string    `json:"proxy"`
+	AdCount        *int64     `json:"adCount"`
+	LinkCount      *int64     `json:"linkCount"`
+	ErrorMessage   *string    `json:"errorMessage"`
+	Classification *string    `json:"classification"`
+	StartedAt      *time.Time `json:"startedAt"`
+	FinishedAt     *time.Time `json:"finishedAt"`
+}
+
+func NewKeywordScrape(keyword *Keyword, workerHost string) *KeywordScrape {
+	now := time.Now()
+
+	return &KeywordScrape{
+		KeywordID:    keyword.ID,
+		Status:       KeywordStatusProcessing,
+		SearchEngine: keyword.SearchEngine,
+		WorkerHost:   workerHost,
+		StartedAt:    &now,
+	}
+}
+
+func (ks KeywordScrapes) ToDTOs() []*KeywordScrapeDTO {
+	result := make([]*KeywordScrapeDTO, len(ks))
+	for i, v := range ks {
+		result[i] = v.ToDTO()
+	}
+
+	return result
+}
+
+func (k *KeywordScrape) ToDTO() *KeywordScrapeDTO {
+	return &KeywordScrapeDTO{
+		ID:             k.ID,
+		Status:         k.Status,
+		SearchEngine:   k.SearchEngine,
+		WorkerHost:     k.WorkerHost,
+		Proxy:          k.Proxy,
+		AdCount:        k.AdCount,
+		LinkCount:      k.LinkCount,
+		ErrorMessage:   k.ErrorMessage,
+		Classification: k.Classification,
+		StartedAt:      k.StartedAt,
+		FinishedAt:     k.FinishedAt,
+	}
+}
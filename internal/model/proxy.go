@@ -0,0 +1,60 @@
+package model
+
+import (
+	"net/url"
+	"time"
+)
+
+type Proxies []*Proxy
+
+type Proxy struct {
+	Model2
+	URL              string
+	Active           bool
+	SuccessCount     int64
+	BlockCount       int64
+	FailureCount     int64
+	LastUsedAt       *time.Time
+	QuarantinedUntil *time.Time
+}
+
+type ProxyDTO struct {
+	ID               int64      `json:"id"`
+	URL              string     `json:"url"`
+	Active           bool       `json:"active"`
+	SuccessCount     int64      `json:"successCount"`
+	BlockCount       int64      `json:"blockCount"`
+	FailureCount     int64      `json:"failureCount"`
+	LastUsedAt       *time.Time `json:"lastUsedAt"`
+	QuarantinedUntil *time.Time `json:"quarantinedUntil"`
+	CreatedAt        *time.Time `json:"createdAt"`
+}
+
+func (ps Proxies) ToDTOs() []*ProxyDTO {
+	result := make([]*ProxyDTO, len(ps))
+	for i, v := range ps {
+		result[i] = v.ToDTO()
+	}
+
+	return result
+}
+
+// ToDTO returns the proxy with the password in its URL masked.
+func (p *Proxy) ToDTO() *ProxyDTO {
+	proxyURL := p.URL
+	if u, err := url.Parse(p.URL); err == nil {
+		proxyURL = u.Redacted()
+	}
+
+	return &ProxyDTO{
+		ID:               p.ID,
+		URL:              proxyURL,
+		Active:           p.Active,
+		SuccessCount:     p.SuccessCount,
+		BlockCount:       p.BlockCount,
+		FailureCount:     p.FailureCount,
+		LastUsedAt:       p.LastUsedAt,
+		QuarantinedUntil: p.QuarantinedUntil,
+		CreatedAt:        p.CreatedAt,
+	}
+}
@@ -0,0 +1,29 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// RefreshToken is an issued refresh token, by its JWT ID. The tokens rotated from the same sign-in share a family,
+// which is revoked as a whole once one of its used tokens is presented again.
+type RefreshToken struct {
+	ID        string `gorm:"primaryKey"`
+	FamilyID  uuid.UUID
+	UserID    uuid.UUID
+	ExpiresAt time.Time
+	UsedAt    *time.Time
+	RevokedAt *time.Time
+	CreatedAt *time.Time
+	UpdatedAt *time.Time
+}
+
+func NewRefreshToken(id string, familyID, userID uuid.UUID, expiresAt time.Time) *RefreshToken {
+	return &RefreshToken{
+		ID:        id,
+		FamilyID:  familyID,
+		UserID:    userID,
+		ExpiresAt: expiresAt,
+	}
+}
@@ -0,0 +1,38 @@
+package model
+
+type SerpAds []*SerpAd
+
+type SerpAd struct {
+	Model2
+	KeywordID        int64
+	KeywordScrapeID  int64
+	Position         int
+	Block            string
+	AdvertiserDomain string
+	Headline         string
+}
+
+type SerpAdDTO struct {
+	Position         int    `json:"position"`
+	Block            string `json:"block"`
+	AdvertiserDomain string `json:"advertiserDomain"`
+	Headline         string `json:"headline"`
+}
+
+func (as SerpAds) ToDTOs() []*SerpAdDTO {
+	result := make([]*SerpAdDTO, len(as))
+	for i, v := range as {
+		result[i] = v.ToDTO()
+	}
+
+	return result
+}
+
+func (a *SerpAd) ToDTO() *SerpAdDTO {
+	return &SerpAdDTO{
+		Position:         a.Position,
+		Block:            a.Block,
+		AdvertiserDomain: a.AdvertiserDomain,
+		Headline:         a.Headline,
+	}
+}
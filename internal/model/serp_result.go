@@ -0,0 +1,48 @@
+package model
+
+type SerpResults []*SerpResult
+
+type SerpResult struct {
+	Model2
+	KeywordID       int64
+	KeywordScrapeID int64
+	Position        int
+	Title           string
+	URL             string
+	DisplayURL      string
+	Snippet         string
+}
+
+type SerpResultDTO struct {
+	Position   int    `json:"position"`
+	Title      string `json:"title"`
+	URL        string `json:"url"`
+	DisplayURL string `json:"displayUrl"`
+	Snippet    string `json:"snippet"`
+}
+
+func (rs SerpResults) ToDTOs() []*SerpResultDTO {
+	result := make([]*SerpResultDTO, len(rs))
+	for i, v := range rs {
+		result[i] = v.ToDTO()
+	}
+
+	return result
+}
+
+func (r *SerpResult) ToDTO() *SerpResultDTO {
+	return &SerpResultDTO{
+		Position:   r.Position,
+		Title:      r.Title,
+		URL:        r.URL,
+		DisplayURL: r.DisplayURL,
+		Snippet:    r.Snippet,
+	}
+}
+
+type KeywordResultsDTO struct {
+	KeywordID      int64            `json:"keywordId"`
+	RunID          *int64           `json:"runId"`
+	OrganicResults []*SerpResultDTO `json:"organicResults"`
+	Ads            []*SerpAdDTO     `json:"ads"`
+}
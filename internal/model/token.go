@@ -1,7 +1,8 @@
 package model
 
 import (
-	"math/rand"
+	"crypto/rand"
+	"math/big"
 	"time"
 )
 
@@ -14,8 +15,11 @@ var alphaNum = []rune("abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ01234
 
 func NewToken() (string, *time.Time) {
 	b := make([]rune, tokenLength)
+	size := big.NewInt(int64(len(alphaNum)))
 	for i := range b {
-		b[i] = alphaNum[rand.Intn(len(alphaNum))]
+		// crypto/rand doesn't fail since Go 1.24
+		n, _ := rand.Int(rand.Reader, size)
+		b[i] = alphaNum[n.Int64()]
 	}
 
 	token := string(b)
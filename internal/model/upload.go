@@ -0,0 +1,87 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type Uploads []*Upload
+
+type Upload struct {
+	Model2
+	UserID       uuid.UUID
+	WorkspaceID  uuid.UUID
+	Filename     string
+	RowCount     int
+	CreatedCount int
+	SkippedCount int
+	CompletedAt  *time.Time
+}
+
+// UploadStatusCounts are the numbers of keywords created by an upload, by status.
+type UploadStatusCounts map[string]int64
+
+type UploadDTO struct {
+	ID           int64              `json:"id"`
+	Filename     string             `json:"filename"`
+	RowCount     int                `json:"rowCount"`
+	CreatedCount int                `json:"createdCount"`
+	SkippedCount int                `json:"skippedCount"`
+	CreatedAt    *time.Time         `json:"createdAt"`
+	CompletedAt  *time.Time         `json:"completedAt"`
+	Progress     *UploadProgressDTO `json:"progress"`
+}
+
+type UploadProgressDTO struct {
+	Total                int64      `json:"total"`
+	Pending              int64      `json:"pending"`
+	Processing           int64      `json:"processing"`
+	Completed            int64      `json:"completed"`
+	Failed               int64      `json:"failed"`
+	Cancelled            int64      `json:"cancelled"`
+	EstimatedCompletedAt *time.Time `json:"estimatedCompletedAt"`
+}
+
+func (us Uploads) ToDTOs(counts map[int64]UploadStatusCounts) []*UploadDTO {
+	result := make([]*UploadDTO, len(us))
+	for i, v := range us {
+		result[i] = v.ToDTO(counts[v.ID])
+	}
+
+	return result
+}
+
+func (u *Upload) ToDTO(counts UploadStatusCounts) *UploadDTO {
+	return &UploadDTO{
+		ID:           u.ID,
+		Filename:     u.Filename,
+		RowCount:     u.RowCount,
+		CreatedCount: u.CreatedCount,
+		SkippedCount: u.SkippedCount,
+		CreatedAt:    u.CreatedAt,
+		CompletedAt:  u.CompletedAt,
+		Progress:     u.progress(counts, time.Now()),
+	}
+}
+
+// progress estimates the completion time from the rate the keywords have been finished since the upload.
+func (u *Upload) progress(counts UploadStatusCounts, now time.Time) *UploadProgressDTO {
+	p := &UploadProgressDTO{
+		Pending:    counts[KeywordStatusPending],
+		Processing: counts[KeywordStatusProcessing],
+		Completed:  counts[KeywordStatusCompleted],
+		Failed:     counts[KeywordStatusFailed],
+		Cancelled:  counts[KeywordStatusCancelled],
+	}
+	p.Total = p.Pending + p.Processing + p.Completed + p.Failed + p.Cancelled
+
+	remaining, finished := p.Pending+p.Processing, p.Completed+p.Failed+p.Cancelled
+	if remaining > 0 && finished > 0 && u.CreatedAt != nil {
+		elapsed := now.Sub(*u.CreatedAt)
+		eta := now.Add(time.Duration(float64(elapsed) * float64(remaining) / float64(finished)))
+		p.EstimatedCompletedAt = &eta
+	}
+
+	return p
+}
@@ -4,9 +4,10 @@ import "github.com/google/uuid"
 
 type User struct {
 	Model
-	Email           string `gorm:"default:null"`
-	Auth            *UserAuth
-	ActivationToken *UserActivationToken
+	Email              string `gorm:"default:null"`
+	Auth               *UserAuth
+	ActivationToken    *UserActivationToken
+	ResetPasswordToken *UserResetPasswordToken
 }
 
 func NewUser(email string, hashedPassword string) *User {
@@ -0,0 +1,32 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// UserPreference holds the notification settings of a user; users without one have the defaults of NewUserPreference.
+type UserPreference struct {
+	UserID             uuid.UUID `gorm:"primaryKey"`
+	UploadSummaryEmail bool
+	CreatedAt          *time.Time
+	UpdatedAt          *time.Time
+}
+
+type UserPreferenceDTO struct {
+	UploadSummaryEmail bool `json:"uploadSummaryEmail"`
+}
+
+func NewUserPreference(userID uuid.UUID) *UserPreference {
+	return &UserPreference{
+		UserID:             userID,
+		UploadSummaryEmail: true,
+	}
+}
+
+func (p *UserPreference) ToDTO() *UserPreferenceDTO {
+	return &UserPreferenceDTO{
+		UploadSummaryEmail: p.UploadSummaryEmail,
+	}
+}
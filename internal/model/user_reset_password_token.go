@@ -0,0 +1,23 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type UserResetPasswordToken struct {
+	UserID         uuid.UUID  `gorm:"primaryKey"`
+	Token          string     `json:"-"`
+	TokenExpiredAt *time.Time `json:"-"`
+}
+
+func NewUserResetPasswordToken(userID uuid.UUID) *UserResetPasswordToken {
+	token, expiry := NewToken()
+
+	return &UserResetPasswordToken{
+		UserID:         userID,
+		Token:          token,
+		TokenExpiredAt: expiry,
+	}
+}
@@ -0,0 +1,118 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	WebhookEventKeywordCompleted = "keyword.completed"
+	WebhookEventKeywordFailed    = "keyword.failed"
+	WebhookEventUploadCompleted  = "upload.completed"
+)
+
+const (
+	WebhookDeliveryStatusPending   = "pending"
+	WebhookDeliveryStatusSucceeded = "succeeded"
+	WebhookDeliveryStatusFailed    = "failed"
+)
+
+// WebhookEvents are the event types a webhook can subscribe to.
+var WebhookEvents = []string{WebhookEventKeywordCompleted, WebhookEventKeywordFailed, WebhookEventUploadCompleted}
+
+type Webhooks []*Webhook
+
+type Webhook struct {
+	Model2
+	UserID uuid.UUID
+	URL    string
+	Secret string
+	Events []string `gorm:"serializer:json"`
+	Active bool
+}
+
+type WebhookDTO struct {
+	ID        int64      `json:"id"`
+	URL       string     `json:"url"`
+	Secret    string     `json:"secret,omitempty"`
+	Events    []string   `json:"events"`
+	Active    bool       `json:"active"`
+	CreatedAt *time.Time `json:"createdAt"`
+}
+
+func (ws Webhooks) ToDTOs() []*WebhookDTO {
+	result := make([]*WebhookDTO, len(ws))
+	for i, v := range ws {
+		result[i] = v.ToDTO()
+	}
+
+	return result
+}
+
+// ToDTO returns the webhook without its secret, which is only shown once it's created.
+func (w *Webhook) ToDTO() *WebhookDTO {
+	return &WebhookDTO{
+		ID:        w.ID,
+		URL:       w.URL,
+		Events:    w.Events,
+		Active:    w.Active,
+		CreatedAt: w.CreatedAt,
+	}
+}
+
+type WebhookDeliveries []*WebhookDelivery
+
+type WebhookDelivery struct {
+	Model2
+	WebhookID    int64
+	EventID      uuid.UUID
+	EventType    string
+	Payload      string
+	Status       string
+	Attempts     int
+	ResponseCode *int
+	ResponseBody *string
+	ErrorMessage *string
+	DeliveredAt  *time.Time
+}
+
+type WebhookDeliveryDTO struct {
+	ID           int64           `json:"id"`
+	EventID      uuid.UUID       `json:"eventId"`
+	EventType    string          `json:"eventType"`
+	Payload      json.RawMessage `json:"payload"`
+	Status       string          `json:"status"`
+	Attempts     int             `json:"attempts"`
+	ResponseCode *int            `json:"responseCode"`
+	ResponseBody *string         `json:"responseBody"`
+	ErrorMessage *string         `json:"errorMessage"`
+	CreatedAt    *time.Time      `json:"createdAt"`
+	DeliveredAt  *time.Time      `json:"deliveredAt"`
+}
+
+func (ds WebhookDeliveries) ToDTOs() []*WebhookDeliveryDTO {
+	result := make([]*WebhookDeliveryDTO, len(ds))
+	for i, v := range ds {
+		result[i] = v.ToDTO()
+	}
+
+	return result
+}
+
+func (d *WebhookDelivery) ToDTO() *WebhookDeliveryDTO {
+	return &WebhookDeliveryDTO{
+		ID:           d.ID,
+		EventID:      d.EventID,
+		EventType:    d.EventType,
+		Payload:      json.RawMessage(d.Payload),
+		Status:       d.Status,
+		Attempts:     d.Attempts,
+		ResponseCode: d.ResponseCode,
+		ResponseBody: d.ResponseBody,
+		ErrorMessage: d.ErrorMessage,
+		CreatedAt:    d.CreatedAt,
+		DeliveredAt:  d.DeliveredAt,
+	}
+}
@@ -0,0 +1,198 @@
+package model
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// WorkspaceRoleOwner manages the workspace, its members and its invitations
+	WorkspaceRoleOwner = "owner"
+	// WorkspaceRoleEditor uploads, rescrapes, cancels and schedules the keywords
+	WorkspaceRoleEditor = "editor"
+	// WorkspaceRoleViewer only reads the keywords and the uploads
+	WorkspaceRoleViewer = "viewer"
+)
+
+const (
+	personalWorkspaceName = "Personal"
+	invitationLifetime    = 7 * 24 * time.Hour
+	invitationTokenSize   = 32
+)
+
+var workspaceRoleRanks = map[string]int{
+	WorkspaceRoleViewer: 1,
+	WorkspaceRoleEditor: 2,
+	WorkspaceRoleOwner:  3,
+}
+
+// WorkspaceRoleAllows reports whether a role has the rights of another; each role has the rights of the ones below.
+func WorkspaceRoleAllows(role, required string) bool {
+	return workspaceRoleRanks[role] >= workspaceRoleRanks[required] && workspaceRoleRanks[role] > 0
+}
+
+type Workspace struct {
+	Model
+	Name string
+}
+
+// NewPersonalWorkspace returns the workspace every user has, with the same ID as the user.
+func NewPersonalWorkspace(userID uuid.UUID) *Workspace {
+	return &Workspace{
+		Model: Model{
+			ID: userID,
+		},
+		Name: personalWorkspaceName,
+	}
+}
+
+func NewWorkspace(name string) *Workspace {
+	return &Workspace{
+		Model: Model{
+			ID: uuid.New(),
+		},
+		Name: name,
+	}
+}
+
+type WorkspaceDTO struct {
+	ID        uuid.UUID  `json:"id"`
+	Name      string     `json:"name"`
+	Role      string     `json:"role"`
+	CreatedAt *time.Time `json:"createdAt"`
+}
+
+type WorkspaceMembers []*WorkspaceMember
+
+type WorkspaceMember struct {
+	WorkspaceID uuid.UUID `gorm:"primaryKey;type:uuid"`
+	UserID      uuid.UUID `gorm:"primaryKey;type:uuid"`
+	Role        string
+	CreatedAt   *time.Time
+	UpdatedAt   *time.Time
+	Workspace   *Workspace
+	User        *User
+}
+
+func NewWorkspaceMember(workspaceID, userID uuid.UUID, role string) *WorkspaceMember {
+	return &WorkspaceMember{
+		WorkspaceID: workspaceID,
+		UserID:      userID,
+		Role:        role,
+	}
+}
+
+type WorkspaceMemberDTO struct {
+	UserID    uuid.UUID  `json:"userId"`
+	Email     string     `json:"email"`
+	Role      string     `json:"role"`
+	CreatedAt *time.Time `json:"createdAt"`
+}
+
+// ToWorkspaceDTOs returns the workspaces of the memberships, with the role of the member in each.
+func (ms WorkspaceMembers) ToWorkspaceDTOs() []*WorkspaceDTO {
+	result := make([]*WorkspaceDTO, len(ms))
+	for i, v := range ms {
+		result[i] = v.ToWorkspaceDTO()
+	}
+
+	return result
+}
+
+func (m *WorkspaceMember) ToWorkspaceDTO() *WorkspaceDTO {
+	dto := &WorkspaceDTO{
+		ID:   m.WorkspaceID,
+		Role: m.Role,
+	}
+	if m.Workspace != nil {
+		dto.Name = m.Workspace.Name
+		dto.CreatedAt = m.Workspace.CreatedAt
+	}
+
+	return dto
+}
+
+func (ms WorkspaceMembers) ToDTOs() []*WorkspaceMemberDTO {
+	result := make([]*WorkspaceMemberDTO, len(ms))
+	for i, v := range ms {
+		result[i] = v.ToDTO()
+	}
+
+	return result
+}
+
+func (m *WorkspaceMember) ToDTO() *WorkspaceMemberDTO {
+	dto := &WorkspaceMemberDTO{
+		UserID:    m.UserID,
+		Role:      m.Role,
+		CreatedAt: m.CreatedAt,
+	}
+	if m.User != nil {
+		dto.Email = m.User.Email
+	}
+
+	return dto
+}
+
+type WorkspaceInvitations []*WorkspaceInvitation
+
+type WorkspaceInvitation struct {
+	Model2
+	WorkspaceID uuid.UUID
+	Email       string
+	Role        string
+	Token       string
+	InvitedBy   uuid.UUID
+	ExpiresAt   *time.Time
+	AcceptedAt  *time.Time
+}
+
+func NewWorkspaceInvitation(workspaceID uuid.UUID, email, role string, invitedBy uuid.UUID) *WorkspaceInvitation {
+	// crypto/rand doesn't fail since Go 1.24
+	b := make([]byte, invitationTokenSize)
+	rand.Read(b)
+
+	expiresAt := time.Now().Add(invitationLifetime)
+
+	return &WorkspaceInvitation{
+		WorkspaceID: workspaceID,
+		Email:       email,
+		Role:        role,
+		Token:       hex.EncodeToString(b),
+		InvitedBy:   invitedBy,
+		ExpiresAt:   &expiresAt,
+	}
+}
+
+type WorkspaceInvitationDTO struct {
+	ID         int64      `json:"id"`
+	Email      string     `json:"email"`
+	Role       string     `json:"role"`
+	ExpiresAt  *time.Time `json:"expiresAt"`
+	AcceptedAt *time.Time `json:"acceptedAt"`
+	CreatedAt  *time.Time `json:"createdAt"`
+}
+
+func (is WorkspaceInvitations) ToDTOs() []*WorkspaceInvitationDTO {
+	result := make([]*WorkspaceInvitationDTO, len(is))
+	for i, v := range is {
+		result[i] = v.ToDTO()
+	}
+
+	return result
+}
+
+// ToDTO returns the invitation without its token, which is only sent to the invited email.
+func (i *WorkspaceInvitation) ToDTO() *WorkspaceInvitationDTO {
+	return &WorkspaceInvitationDTO{
+		ID:         i.ID,
+		Email:      i.Email,
+		Role:       i.Role,
+		ExpiresAt:  i.ExpiresAt,
+		AcceptedAt: i.AcceptedAt,
+		CreatedAt:  i.CreatedAt,
+	}
+}
@@ -0,0 +1,137 @@
+package ratelimit
+
+import (
+	"context"
+	"expvar"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	fmtBucketKey = "ratelimit:%s"
+
+	fmtEngineBucket = "engine:%s"
+	fmtProxyBucket  = "proxy:%s:%s"
+)
+
+// Time spent waiting for the buckets, by bucket, served by the workers at /debug/vars
+var (
+	waits       = expvar.NewMap("ratelimit_waits")
+	waitSeconds = expvar.NewMap("ratelimit_wait_seconds")
+)
+
+// reserveScript takes a token of the bucket in KEYS[1] refilled at ARGV[1] tokens per second up to ARGV[2] tokens,
+// and returns how long to wait in milliseconds before using it. The tokens go negative for the waiting reservations,
+// so the requests are served in order. Redis time is used so the clocks of the workers don't matter.
+var reserveScript = redis.NewScript(`
+local rate = tonumber(ARGV[1])
+local burst = tonumber(ARGV[2])
+local t = redis.call("TIME")
+local now = tonumber(t[1]) * 1000 + math.floor(tonumber(t[2]) / 1000)
+
+local bucket = redis.call("HMGET", KEYS[1], "tokens", "ts")
+local tokens = tonumber(bucket[1])
+local ts = tonumber(bucket[2])
+if tokens == nil or ts == nil then
+	tokens, ts = burst, now
+end
+
+tokens = math.min(burst, tokens + math.max(0, now - ts) * rate / 1000) - 1
+
+local wait = 0
+if tokens < 0 then
+	wait = math.ceil(-tokens * 1000 / rate)
+end
+
+redis.call("HSET", KEYS[1], "tokens", tostring(tokens), "ts", now)
+redis.call("PEXPIRE", KEYS[1], wait + math.ceil(burst * 1000 / rate) + 1000)
+return wait
+`)
+
+// Limit is a rate of requests allowed in bursts; a zero rate is unlimited.
+type Limit struct {
+	// Rate is the number of requests per second
+	Rate  float64
+	Burst int
+}
+
+// Policy is how fast the search engines are scraped by all the workers, with and through each proxy.
+type Policy struct {
+	// Engine is the limit of every search engine not in Engines
+	Engine  Limit
+	Engines map[string]Limit
+	// Proxy is the limit of the scrapes of a search engine through a proxy
+	Proxy Limit
+}
+
+// EngineLimit returns the limit of the search engine.
+func (p Policy) EngineLimit(engine string) Limit {
+	if limit, ok := p.Engines[engine]; ok {
+		return limit
+	}
+
+	return p.Engine
+}
+
+// Bucket is a rate limited key.
+type Bucket struct {
+	Key   string
+	Limit Limit
+}
+
+// EngineBucket is the bucket of the scrapes of the search engine.
+func (p Policy) EngineBucket(engine string) Bucket {
+	return Bucket{Key: fmt.Sprintf(fmtEngineBucket, engine), Limit: p.EngineLimit(engine)}
+}
+
+// ProxyBucket is the bucket of the scrapes of the search engine through the proxy, by its name without credentials.
+func (p Policy) ProxyBucket(engine, proxy string) Bucket {
+	return Bucket{Key: fmt.Sprintf(fmtProxyBucket, engine, proxy), Limit: p.Proxy}
+}
+
+// Limiter rate limits the requests of the buckets in Redis, so the limits hold across the workers.
+type Limiter struct {
+	rdb redis.UniversalClient
+}
+
+func NewLimiter(rdb redis.UniversalClient) *Limiter {
+	return &Limiter{
+		rdb: rdb,
+	}
+}
+
+// Wait reserves a request of each bucket and waits until all of them allow it, returning the time waited.
+func (l *Limiter) Wait(ctx context.Context, buckets ...Bucket) (time.Duration, error) {
+	var wait time.Duration
+	for _, v := range buckets {
+		if v.Limit.Rate <= 0 {
+			continue
+		}
+
+		ms, err := reserveScript.Run(ctx, l.rdb, []string{fmt.Sprintf(fmtBucketKey, v.Key)}, v.Limit.Rate, max(v.Limit.Burst, 1)).Int64()
+		if err != nil {
+			return 0, err
+		}
+
+		d := time.Duration(ms) * time.Millisecond
+		waits.Add(v.Key, 1)
+		waitSeconds.AddFloat(v.Key, d.Seconds())
+		wait = max(wait, d)
+	}
+
+	if wait == 0 {
+		return 0, nil
+	}
+
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return wait, ctx.Err()
+	case <-timer.C:
+		return wait, nil
+	}
+}
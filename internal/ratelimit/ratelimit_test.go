@@ -0,0 +1,32 @@
+package ratelimit_test
+
+import (
+	"testing"
+
+	"web-scraper.dev/internal/ratelimit"
+)
+
+func TestPolicyBuckets(t *testing.T) {
+	t.Parallel()
+
+	p := ratelimit.Policy{
+		Engine:  ratelimit.Limit{Rate: 1, Burst: 1},
+		Engines: map[string]ratelimit.Limit{"google": {Rate: 0.5, Burst: 2}},
+		Proxy:   ratelimit.Limit{Rate: 0.2, Burst: 1},
+	}
+
+	tests := []struct {
+		bucket   ratelimit.Bucket
+		expected ratelimit.Bucket
+	}{
+		{p.EngineBucket("bing"), ratelimit.Bucket{Key: "engine:bing", Limit: ratelimit.Limit{Rate: 1, Burst: 1}}},
+		{p.EngineBucket("google"), ratelimit.Bucket{Key: "engine:google", Limit: ratelimit.Limit{Rate: 0.5, Burst: 2}}},
+		{p.ProxyBucket("bing", "http://10.0.0.1:8080"), ratelimit.Bucket{Key: "proxy:bing:http://10.0.0.1:8080", Limit: ratelimit.Limit{Rate: 0.2, Burst: 1}}},
+	}
+
+	for _, tc := range tests {
+		if tc.bucket != tc.expected {
+			t.Errorf("Wrong bucket: got %+v want %+v", tc.bucket, tc.expected)
+		}
+	}
+}
@@ -0,0 +1,71 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+
+	"web-scraper.dev/internal/model"
+)
+
+// apiKeyLastUsedPrecision is how often the last use of an API key is written, at most
+const apiKeyLastUsedPrecision = time.Minute
+
+func (db *Db) CreateAPIKey(k *model.APIKey) error {
+	return db.Create(k).Error
+}
+
+func (db *Db) ListAPIKeysByUserId(userID uuid.UUID, offset, limit int) (model.APIKeys, int64, error) {
+	var total int64
+	if err := db.Model(&model.APIKey{}).Where("user_id = ?", userID).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	keys := make([]*model.APIKey, 0)
+	if err := db.Where("user_id = ?", userID).
+		Order("created_at desc, id desc").
+		Offset(offset).
+		Limit(limit).
+		Find(&keys).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return keys, total, nil
+}
+
+func (db *Db) ReadAPIKeyByPrefix(prefix string) (*model.APIKey, error) {
+	key := &model.APIKey{}
+	if err := db.Where("prefix = ?", prefix).First(key).Error; err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+func (db *Db) ReadAPIKeyByIdAndUserId(id int64, userId uuid.UUID) (*model.APIKey, error) {
+	key := &model.APIKey{}
+	if err := db.Where("id = ? AND user_id = ?", id, userId).First(key).Error; err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+func (db *Db) UpdateAPIKeyByIdAndUserId(id int64, userId uuid.UUID, updates map[string]any) (int64, error) {
+	res := db.Model(&model.APIKey{}).Where("id = ? AND user_id = ?", id, userId).Updates(updates)
+	return res.RowsAffected, res.Error
+}
+
+// RevokeAPIKeyByIdAndUserId revokes an API key, keeping it in the list with its last use.
+func (db *Db) RevokeAPIKeyByIdAndUserId(id int64, userId uuid.UUID) (int64, error) {
+	res := db.Model(&model.APIKey{}).
+		Where("id = ? AND user_id = ? AND revoked_at IS NULL", id, userId).
+		Update("revoked_at", time.Now())
+	return res.RowsAffected, res.Error
+}
+
+// UpdateAPIKeyLastUsedAtById keeps track of the last use of an API key, without writing on every request.
+func (db *Db) UpdateAPIKeyLastUsedAtById(id int64) error {
+	now := time.Now()
+	return db.Model(&model.APIKey{}).
+		Where("id = ? AND (last_used_at IS NULL OR last_used_at < ?)", id, now.Add(-apiKeyLastUsedPrecision)).
+		UpdateColumn("last_used_at", now).Error
+}
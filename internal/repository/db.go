@@ -1,6 +1,8 @@
 package repository
 
 import (
+	"time"
+
 	"github.com/google/uuid"
 	"gorm.io/gorm"
 
@@ -37,10 +39,102 @@ type DB interface {
 	ReadUserByEmail(email string) (*model.User, error)
 	ReadUserWithActivationTokenByEmail(email string) (*model.User, error)
 	ReadUserWithActivationTokenAndUserAuthByEmail(email string) (*model.User, error)
+	ReadUserWithResetPasswordTokenByEmail(email string) (*model.User, error)
+	ReadUserById(id uuid.UUID) (*model.User, error)
+
+	UpdateUserAuthPasswordByUserId(userId uuid.UUID, hashedPassword string) error
+
+	CreateRefreshToken(rt *model.RefreshToken) error
+	ReadRefreshTokenById(id string) (*model.RefreshToken, error)
+	UseRefreshTokenById(id string) (bool, error)
+	RevokeRefreshTokensByFamilyId(familyID uuid.UUID) error
+	RevokeRefreshTokensByUserId(userID uuid.UUID) error
+
+	CreateAPIKey(k *model.APIKey) error
+	ListAPIKeysByUserId(userID uuid.UUID, offset, limit int) (model.APIKeys, int64, error)
+	ReadAPIKeyByPrefix(prefix string) (*model.APIKey, error)
+	ReadAPIKeyByIdAndUserId(id int64, userId uuid.UUID) (*model.APIKey, error)
+	UpdateAPIKeyByIdAndUserId(id int64, userId uuid.UUID, updates map[string]any) (int64, error)
+	RevokeAPIKeyByIdAndUserId(id int64, userId uuid.UUID) (int64, error)
+	UpdateAPIKeyLastUsedAtById(id int64) error
+
+	CreateWorkspace(ws *model.Workspace) error
+	UpdateWorkspaceNameById(id uuid.UUID, name string) error
+	CreateWorkspaceMember(m *model.WorkspaceMember) error
+	ListWorkspaceMembersByUserId(userID uuid.UUID) (model.WorkspaceMembers, error)
+	ListWorkspaceMembersByWorkspaceId(workspaceID uuid.UUID) (model.WorkspaceMembers, error)
+	ReadWorkspaceMemberByWorkspaceIdAndUserId(workspaceID, userID uuid.UUID) (*model.WorkspaceMember, error)
+	UpdateWorkspaceMemberRoleByWorkspaceIdAndUserId(workspaceID, userID uuid.UUID, role string) (int64, error)
+	DeleteWorkspaceMemberByWorkspaceIdAndUserId(workspaceID, userID uuid.UUID) (int64, error)
+	CountWorkspaceOwnersByWorkspaceId(workspaceID uuid.UUID) (int64, error)
+	CreateWorkspaceInvitation(i *model.WorkspaceInvitation) error
+	ListPendingWorkspaceInvitationsByWorkspaceId(workspaceID uuid.UUID) (model.WorkspaceInvitations, error)
+	ReadWorkspaceInvitationByToken(token string) (*model.WorkspaceInvitation, error)
+	AcceptWorkspaceInvitationById(id int64) (bool, error)
+	DeleteWorkspaceInvitationByIdAndWorkspaceId(id int64, workspaceID uuid.UUID) (int64, error)
+
+	ReadUserPreferenceByUserId(userID uuid.UUID) (*model.UserPreference, error)
+	CreateOrUpdateUserPreference(pref *model.UserPreference) error
 
 	CreateOrUpdateUserActivationTokenByUserId(uat *model.UserActivationToken) error
+	ExpireUserActivationTokenByUserId(userId uuid.UUID) error
 	DeleteUserActivationTokenByUserId(userId uuid.UUID) error
 
-	ListKeywordsByUserId(userID uuid.UUID) (model.Keywords, error)
-	ReadKeywordByIdAndUserId(id uuid.UUID, userId uuid.UUID) (*model.Keyword, error)
+	CreateOrUpdateUserResetPasswordTokenByUserId(urpt *model.UserResetPasswordToken) error
+	DeleteUserResetPasswordTokenByUserId(userId uuid.UUID) error
+
+	ListKeywordsByWorkspaceId(workspaceID uuid.UUID, filter *KeywordsFilter, offset, limit int) (model.Keywords, int64, error)
+	EachKeywordsBatchByWorkspaceId(workspaceID uuid.UUID, filter *KeywordsFilter, batchSize int, fn func(model.Keywords) error) error
+	CreateKeywordIfNotExists(keyword *model.Keyword) (bool, error)
+	ReadKeywordByIdAndWorkspaceId(id int64, workspaceId uuid.UUID) (*model.Keyword, error)
+	ReadKeywordHTMLContentByIdAndWorkspaceId(id int64, workspaceId uuid.UUID) (*string, error)
+	ListScheduledKeywords() (model.Keywords, error)
+	UpdateKeywordScheduleByIdAndWorkspaceId(id int64, workspaceId uuid.UUID, schedule *string, nextScrapeAt *time.Time) (int64, error)
+	ListKeywordEnginesByIdsAndWorkspaceId(ids []int64, workspaceId uuid.UUID) (model.Keywords, error)
+	ListKeywordEnginesByStatusAndWorkspaceId(status string, workspaceId uuid.UUID, limit int) (model.Keywords, error)
+	UpdateKeywordsStatusByIds(ids []int64, status string) error
+	CancelKeywordByIdAndStatuses(id int64, statuses []string) (int64, error)
+
+	CreateKeywordScrape(ks *model.KeywordScrape) error
+	UpdateKeywordScrapeById(id int64, updates map[string]any) error
+	ListKeywordScrapesByKeywordId(keywordID int64, offset, limit int) (model.KeywordScrapes, int64, error)
+	ReadKeywordScrapeByIdAndKeywordId(id int64, keywordID int64) (*model.KeywordScrape, error)
+
+	CreateSerpResultsAndAds(results model.SerpResults, ads model.SerpAds) error
+	ListSerpResultsByKeywordScrapeId(keywordScrapeID int64) (model.SerpResults, error)
+	ListSerpAdsByKeywordScrapeId(keywordScrapeID int64) (model.SerpAds, error)
+	ListSerpResultsByKeywordScrapeIds(keywordScrapeIDs []int64) (model.SerpResults, error)
+	ListSerpAdsByKeywordScrapeIds(keywordScrapeIDs []int64) (model.SerpAds, error)
+
+	CreateUpload(u *model.Upload) error
+	UpdateUploadById(id int64, updates map[string]any) error
+	ListUploadsByWorkspaceId(workspaceID uuid.UUID, offset, limit int) (model.Uploads, int64, error)
+	ReadUploadByIdAndWorkspaceId(id int64, workspaceId uuid.UUID) (*model.Upload, error)
+	CountKeywordsByUploadIds(ids []int64) (map[int64]model.UploadStatusCounts, error)
+	ReadUploadById(id int64) (*model.Upload, error)
+	CompleteUploadById(id int64, retrying []int64) (bool, error)
+	ListFailedKeywordEnginesByUploadId(uploadID int64) (model.Keywords, error)
+	ListTopKeywordsByUploadId(uploadID int64, limit int) (model.Keywords, error)
+
+	CreateProxyIfNotExists(p *model.Proxy) (bool, error)
+	ListProxies(offset, limit int) (model.Proxies, int64, error)
+	ListActiveProxies() (model.Proxies, error)
+	ReadProxyById(id int64) (*model.Proxy, error)
+	UpdateProxyById(id int64, updates map[string]any) (int64, error)
+	DeleteProxyById(id int64) (int64, error)
+	UpdateProxyUsageById(id int64, outcome string, quarantinedUntil *time.Time) error
+
+	CreateWebhook(wh *model.Webhook) error
+	ListWebhooksByUserId(userID uuid.UUID, offset, limit int) (model.Webhooks, int64, error)
+	ListActiveWebhooksByUserIdAndEvent(userID uuid.UUID, event string) (model.Webhooks, error)
+	ReadWebhookById(id int64) (*model.Webhook, error)
+	ReadWebhookByIdAndUserId(id int64, userId uuid.UUID) (*model.Webhook, error)
+	UpdateWebhookByIdAndUserId(id int64, userId uuid.UUID, updates map[string]any) (int64, error)
+	DeleteWebhookByIdAndUserId(id int64, userId uuid.UUID) (int64, error)
+	DeleteWebhookDeliveriesByWebhookId(webhookID int64) error
+	CreateWebhookDelivery(d *model.WebhookDelivery) error
+	UpdateWebhookDeliveryById(id int64, updates map[string]any) error
+	ReadWebhookDeliveryById(id int64) (*model.WebhookDelivery, error)
+	ReadWebhookDeliveryByIdAndWebhookId(id int64, webhookID int64) (*model.WebhookDelivery, error)
+	ListWebhookDeliveriesByWebhookId(webhookID int64, offset, limit int) (model.WebhookDeliveries, int64, error)
 }
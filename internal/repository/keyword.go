@@ -1,23 +1,188 @@
 package repository
 
 import (
+	"strings"
+	"time"
+
 	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 
 	"web-scraper.dev/internal/model"
 )
 
-func (db *Db) ListKeywordsByUserId(userID uuid.UUID) (model.Keywords, error) {
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+
+type KeywordsFilter struct {
+	Status       string
+	SearchEngine string
+	UploadID     *int64
+	Query        string
+	CreatedFrom  *time.Time
+	CreatedTo    *time.Time
+	SortBy       string
+	SortDesc     bool
+
+	WithHTMLContent bool
+}
+
+// ListKeywordsByWorkspaceId lists a page of keywords matching the filter, along with the total count.
+// The HTML content is only loaded when the filter asks for it.
+func (db *Db) ListKeywordsByWorkspaceId(workspaceID uuid.UUID, filter *KeywordsFilter, offset, limit int) (model.Keywords, int64, error) {
+	q := db.keywordsByWorkspaceIdQuery(workspaceID, filter)
+
+	var total int64
+	if err := q.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
 	keywords := make([]*model.Keyword, 0)
-	if err := db.Where("user_id = ?", userID).Order("created_at desc, updated_at desc").Find(&keywords).Error; err != nil {
-		return nil, err
+	if err := q.Offset(offset).Limit(limit).Find(&keywords).Error; err != nil {
+		return nil, 0, err
 	}
-	return keywords, nil
+
+	return keywords, total, nil
+}
+
+// EachKeywordsBatchByWorkspaceId streams the keywords matching the filter from a cursor and calls fn with each batch,
+// without loading all of them into memory.
+func (db *Db) EachKeywordsBatchByWorkspaceId(workspaceID uuid.UUID, filter *KeywordsFilter, batchSize int, fn func(model.Keywords) error) error {
+	q := db.keywordsByWorkspaceIdQuery(workspaceID, filter)
+
+	rows, err := q.Rows()
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	batch := make(model.Keywords, 0, batchSize)
+	for rows.Next() {
+		keyword := &model.Keyword{}
+		if err := q.ScanRows(rows, keyword); err != nil {
+			return err
+		}
+
+		batch = append(batch, keyword)
+		if len(batch) == batchSize {
+			if err := fn(batch); err != nil {
+				return err
+			}
+			batch = make(model.Keywords, 0, batchSize)
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	if len(batch) > 0 {
+		return fn(batch)
+	}
+
+	return nil
+}
+
+func (db *Db) keywordsByWorkspaceIdQuery(workspaceID uuid.UUID, filter *KeywordsFilter) *gorm.DB {
+	q := db.Model(&model.Keyword{}).Where("workspace_id = ?", workspaceID)
+	if filter.Status != "" {
+		q = q.Where("status = ?", filter.Status)
+	}
+	if filter.SearchEngine != "" {
+		q = q.Where("search_engine = ?", filter.SearchEngine)
+	}
+	if filter.UploadID != nil {
+		q = q.Where("upload_id = ?", *filter.UploadID)
+	}
+	if filter.Query != "" {
+		q = q.Where("keyword ILIKE ?", "%"+likeEscaper.Replace(filter.Query)+"%")
+	}
+	if filter.CreatedFrom != nil {
+		q = q.Where("created_at >= ?", filter.CreatedFrom)
+	}
+	if filter.CreatedTo != nil {
+		q = q.Where("created_at < ?", filter.CreatedTo)
+	}
+
+	if !filter.WithHTMLContent {
+		q = q.Omit("html_content")
+	}
+
+	return q.
+		Order(clause.OrderByColumn{Column: clause.Column{Name: filter.SortBy}, Desc: filter.SortDesc}).
+		Order(clause.OrderByColumn{Column: clause.Column{Name: "id"}, Desc: filter.SortDesc})
 }
 
-func (db *Db) ReadKeywordByIdAndUserId(id int64, userId uuid.UUID) (*model.Keyword, error) {
+// CreateKeywordIfNotExists inserts the keyword unless the workspace already has it for the search engine,
+// and reports whether it has been created.
+func (db *Db) CreateKeywordIfNotExists(keyword *model.Keyword) (bool, error) {
+	res := db.Clauses(clause.OnConflict{DoNothing: true}).Create(keyword)
+	if res.Error != nil {
+		return false, res.Error
+	}
+	return res.RowsAffected > 0, nil
+}
+
+func (db *Db) ReadKeywordByIdAndWorkspaceId(id int64, workspaceId uuid.UUID) (*model.Keyword, error) {
 	keyword := &model.Keyword{}
-	if err := db.Where("id = ? AND user_id = ?", id, userId).First(keyword).Error; err != nil {
+	if err := db.Where("id = ? AND workspace_id = ?", id, workspaceId).First(keyword).Error; err != nil {
 		return nil, err
 	}
 	return keyword, nil
 }
+
+func (db *Db) ReadKeywordHTMLContentByIdAndWorkspaceId(id int64, workspaceId uuid.UUID) (*string, error) {
+	keyword := &model.Keyword{}
+	if err := db.Select("html_content").Where("id = ? AND workspace_id = ?", id, workspaceId).First(keyword).Error; err != nil {
+		return nil, err
+	}
+	return keyword.HTMLContent, nil
+}
+
+func (db *Db) ListScheduledKeywords() (model.Keywords, error) {
+	keywords := make([]*model.Keyword, 0)
+	if err := db.Select("id", "search_engine", "schedule").Where("schedule IS NOT NULL").Order("id").Find(&keywords).Error; err != nil {
+		return nil, err
+	}
+	return keywords, nil
+}
+
+func (db *Db) UpdateKeywordScheduleByIdAndWorkspaceId(id int64, workspaceId uuid.UUID, schedule *string, nextScrapeAt *time.Time) (int64, error) {
+	result := db.Model(&model.Keyword{}).
+		Where("id = ? AND workspace_id = ?", id, workspaceId).
+		Updates(map[string]any{"schedule": schedule, "next_scrape_at": nextScrapeAt})
+	return result.RowsAffected, result.Error
+}
+
+// ListKeywordEnginesByIdsAndWorkspaceId lists the keywords with their IDs and search engines only, to find their scrape tasks.
+func (db *Db) ListKeywordEnginesByIdsAndWorkspaceId(ids []int64, workspaceId uuid.UUID) (model.Keywords, error) {
+	keywords := make(model.Keywords, 0)
+	if err := db.Select("id", "search_engine").Where("id IN ? AND workspace_id = ?", ids, workspaceId).Order("id").Find(&keywords).Error; err != nil {
+		return nil, err
+	}
+	return keywords, nil
+}
+
+// ListKeywordEnginesByStatusAndWorkspaceId lists up to limit keywords with their IDs and search engines only, to find their scrape tasks.
+func (db *Db) ListKeywordEnginesByStatusAndWorkspaceId(status string, workspaceId uuid.UUID, limit int) (model.Keywords, error) {
+	keywords := make(model.Keywords, 0)
+	if err := db.Select("id", "search_engine").Where("status = ? AND workspace_id = ?", status, workspaceId).Order("id").Limit(limit).Find(&keywords).Error; err != nil {
+		return nil, err
+	}
+	return keywords, nil
+}
+
+func (db *Db) UpdateKeywordsStatusByIds(ids []int64, status string) error {
+	return db.Model(&model.Keyword{}).Where("id IN ?", ids).Update("status", status).Error
+}
+
+// CancelKeywordByIdAndStatuses cancels the keyword if it's in one of the statuses, stopping its recurring scrapes too.
+func (db *Db) CancelKeywordByIdAndStatuses(id int64, statuses []string) (int64, error) {
+	updates := map[string]any{
+		"status":         model.KeywordStatusCancelled,
+		"schedule":       nil,
+		"next_scrape_at": nil,
+	}
+
+	result := db.Model(&model.Keyword{}).Where("id = ? AND status IN ?", id, statuses).Updates(updates)
+	return result.RowsAffected, result.Error
+}
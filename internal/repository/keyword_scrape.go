@@ -0,0 +1,39 @@
+package repository
+
+import (
+	"web-scraper.dev/internal/model"
+)
+
+func (db *Db) CreateKeywordScrape(ks *model.KeywordScrape) error {
+	return db.Create(ks).Error
+}
+
+func (db *Db) UpdateKeywordScrapeById(id int64, updates map[string]any) error {
+	return db.Model(&model.KeywordScrape{}).Where("id = ?", id).Updates(updates).Error
+}
+
+func (db *Db) ListKeywordScrapesByKeywordId(keywordID int64, offset, limit int) (model.KeywordScrapes, int64, error) {
+	var total int64
+	if err := db.Model(&model.KeywordScrape{}).Where("keyword_id = ?", keywordID).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	keywordScrapes := make([]*model.KeywordScrape, 0)
+	if err := db.Where("keyword_id = ?", keywordID).
+		Order("started_at desc, id desc").
+		Offset(offset).
+		Limit(limit).
+		Find(&keywordScrapes).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return keywordScrapes, total, nil
+}
+
+func (db *Db) ReadKeywordScrapeByIdAndKeywordId(id int64, keywordID int64) (*model.KeywordScrape, error) {
+	keywordScrape := &model.KeywordScrape{}
+	if err := db.Where("id = ? AND keyword_id = ?", id, keywordID).First(keywordScrape).Error; err != nil {
+		return nil, err
+	}
+	return keywordScrape, nil
+}
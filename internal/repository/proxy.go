@@ -0,0 +1,85 @@
+package repository
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"web-scraper.dev/internal/model"
+	"web-scraper.dev/internal/proxies"
+)
+
+// proxyCountColumns are the columns counting the requests through a proxy by their outcome
+var proxyCountColumns = map[string]string{
+	proxies.OutcomeSuccess: "success_count",
+	proxies.OutcomeBlocked: "block_count",
+	proxies.OutcomeFailed:  "failure_count",
+}
+
+// CreateProxyIfNotExists inserts the proxy unless its URL is already in the table, and reports whether it has been created.
+func (db *Db) CreateProxyIfNotExists(p *model.Proxy) (bool, error) {
+	res := db.Clauses(clause.OnConflict{DoNothing: true}).Create(p)
+	if res.Error != nil {
+		return false, res.Error
+	}
+	return res.RowsAffected > 0, nil
+}
+
+func (db *Db) ListProxies(offset, limit int) (model.Proxies, int64, error) {
+	var total int64
+	if err := db.Model(&model.Proxy{}).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	ps := make([]*model.Proxy, 0)
+	if err := db.Order("created_at desc, id desc").
+		Offset(offset).
+		Limit(limit).
+		Find(&ps).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return ps, total, nil
+}
+
+func (db *Db) ListActiveProxies() (model.Proxies, error) {
+	ps := make([]*model.Proxy, 0)
+	if err := db.Where("active = ?", true).Order("id").Find(&ps).Error; err != nil {
+		return nil, err
+	}
+
+	return ps, nil
+}
+
+func (db *Db) ReadProxyById(id int64) (*model.Proxy, error) {
+	p := &model.Proxy{}
+	if err := db.Where("id = ?", id).First(p).Error; err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+func (db *Db) UpdateProxyById(id int64, updates map[string]any) (int64, error) {
+	res := db.Model(&model.Proxy{}).Where("id = ?", id).Updates(updates)
+	return res.RowsAffected, res.Error
+}
+
+func (db *Db) DeleteProxyById(id int64) (int64, error) {
+	res := db.Where("id = ?", id).Delete(&model.Proxy{})
+	return res.RowsAffected, res.Error
+}
+
+// UpdateProxyUsageById counts a request through a proxy by its outcome, and sets the quarantine the outcome started if any.
+func (db *Db) UpdateProxyUsageById(id int64, outcome string, quarantinedUntil *time.Time) error {
+	column := proxyCountColumns[outcome]
+	updates := map[string]any{
+		column:         gorm.Expr(column + " + 1"),
+		"last_used_at": time.Now(),
+	}
+	if quarantinedUntil != nil {
+		updates["quarantined_until"] = quarantinedUntil
+	}
+
+	return db.Model(&model.Proxy{}).Where("id = ?", id).UpdateColumns(updates).Error
+}
@@ -0,0 +1,42 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+
+	"web-scraper.dev/internal/model"
+)
+
+func (db *Db) CreateRefreshToken(rt *model.RefreshToken) error {
+	return db.Create(rt).Error
+}
+
+func (db *Db) ReadRefreshTokenById(id string) (*model.RefreshToken, error) {
+	rt := &model.RefreshToken{}
+	if err := db.Where("id = ?", id).First(rt).Error; err != nil {
+		return nil, err
+	}
+	return rt, nil
+}
+
+// UseRefreshTokenById marks the refresh token as used and reports whether it was unused and not revoked,
+// so concurrent requests with the same token can't both rotate it.
+func (db *Db) UseRefreshTokenById(id string) (bool, error) {
+	res := db.Model(&model.RefreshToken{}).
+		Where("id = ? AND used_at IS NULL AND revoked_at IS NULL", id).
+		Update("used_at", time.Now())
+	return res.RowsAffected == 1, res.Error
+}
+
+func (db *Db) RevokeRefreshTokensByFamilyId(familyID uuid.UUID) error {
+	return db.Model(&model.RefreshToken{}).
+		Where("family_id = ? AND revoked_at IS NULL", familyID).
+		Update("revoked_at", time.Now()).Error
+}
+
+func (db *Db) RevokeRefreshTokensByUserId(userID uuid.UUID) error {
+	return db.Model(&model.RefreshToken{}).
+		Where("user_id = ? AND revoked_at IS NULL", userID).
+		Update("revoked_at", time.Now()).Error
+}
@@ -0,0 +1,53 @@
+package repository
+
+import (
+	"web-scraper.dev/internal/model"
+)
+
+func (db *Db) CreateSerpResultsAndAds(results model.SerpResults, ads model.SerpAds) error {
+	if len(results) > 0 {
+		if err := db.Create(&results).Error; err != nil {
+			return err
+		}
+	}
+
+	if len(ads) > 0 {
+		if err := db.Create(&ads).Error; err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (db *Db) ListSerpResultsByKeywordScrapeId(keywordScrapeID int64) (model.SerpResults, error) {
+	results := make([]*model.SerpResult, 0)
+	if err := db.Where("keyword_scrape_id = ?", keywordScrapeID).Order("position asc").Find(&results).Error; err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+func (db *Db) ListSerpAdsByKeywordScrapeId(keywordScrapeID int64) (model.SerpAds, error) {
+	ads := make([]*model.SerpAd, 0)
+	if err := db.Where("keyword_scrape_id = ?", keywordScrapeID).Order("block desc, position asc").Find(&ads).Error; err != nil {
+		return nil, err
+	}
+	return ads, nil
+}
+
+func (db *Db) ListSerpResultsByKeywordScrapeIds(keywordScrapeIDs []int64) (model.SerpResults, error) {
+	results := make([]*model.SerpResult, 0)
+	if err := db.Where("keyword_scrape_id IN ?", keywordScrapeIDs).Order("keyword_scrape_id, position asc").Find(&results).Error; err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+func (db *Db) ListSerpAdsByKeywordScrapeIds(keywordScrapeIDs []int64) (model.SerpAds, error) {
+	ads := make([]*model.SerpAd, 0)
+	if err := db.Where("keyword_scrape_id IN ?", keywordScrapeIDs).Order("keyword_scrape_id, block desc, position asc").Find(&ads).Error; err != nil {
+		return nil, err
+	}
+	return ads, nil
+}
@@ -0,0 +1,124 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+
+	"web-scraper.dev/internal/model"
+)
+
+func (db *Db) CreateUpload(u *model.Upload) error {
+	return db.Create(u).Error
+}
+
+func (db *Db) UpdateUploadById(id int64, updates map[string]any) error {
+	return db.Model(&model.Upload{}).Where("id = ?", id).Updates(updates).Error
+}
+
+func (db *Db) ListUploadsByWorkspaceId(workspaceID uuid.UUID, offset, limit int) (model.Uploads, int64, error) {
+	var total int64
+	if err := db.Model(&model.Upload{}).Where("workspace_id = ?", workspaceID).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	uploads := make([]*model.Upload, 0)
+	if err := db.Where("workspace_id = ?", workspaceID).
+		Order("created_at desc, id desc").
+		Offset(offset).
+		Limit(limit).
+		Find(&uploads).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return uploads, total, nil
+}
+
+func (db *Db) ReadUploadByIdAndWorkspaceId(id int64, workspaceId uuid.UUID) (*model.Upload, error) {
+	upload := &model.Upload{}
+	if err := db.Where("id = ? AND workspace_id = ?", id, workspaceId).First(upload).Error; err != nil {
+		return nil, err
+	}
+	return upload, nil
+}
+
+// CountKeywordsByUploadIds counts the keywords created by each upload, by status.
+func (db *Db) CountKeywordsByUploadIds(ids []int64) (map[int64]model.UploadStatusCounts, error) {
+	var rows []struct {
+		UploadID int64
+		Status   string
+		Count    int64
+	}
+
+	if err := db.Model(&model.Keyword{}).
+		Select("upload_id, status, count(*) AS count").
+		Where("upload_id IN ?", ids).
+		Group("upload_id, status").
+		Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	result := make(map[int64]model.UploadStatusCounts, len(ids))
+	for _, v := range rows {
+		if result[v.UploadID] == nil {
+			result[v.UploadID] = make(model.UploadStatusCounts)
+		}
+		result[v.UploadID][v.Status] = v.Count
+	}
+
+	return result, nil
+}
+
+func (db *Db) ReadUploadById(id int64) (*model.Upload, error) {
+	upload := &model.Upload{}
+	if err := db.Where("id = ?", id).First(upload).Error; err != nil {
+		return nil, err
+	}
+	return upload, nil
+}
+
+// CompleteUploadById sets the completion time of the upload once none of its keywords are pending or processing,
+// nor among the given retrying ones, failed with their scrape tasks waiting for a retry.
+// Reports whether this call completed it, so concurrent workers complete an upload only once.
+func (db *Db) CompleteUploadById(id int64, retrying []int64) (bool, error) {
+	unfinished := db.Model(&model.Keyword{}).
+		Select("1").
+		Where("upload_id = ? AND (status IN ? OR id IN ?)", id, []string{model.KeywordStatusPending, model.KeywordStatusProcessing}, retrying)
+
+	res := db.Model(&model.Upload{}).
+		Where("id = ? AND completed_at IS NULL AND NOT EXISTS (?)", id, unfinished).
+		Update("completed_at", time.Now())
+	return res.RowsAffected == 1, res.Error
+}
+
+// ListFailedKeywordEnginesByUploadId lists the failed keywords of the upload with their IDs and search engines only,
+// to find their scrape tasks. Empty while some keywords of the upload are pending or processing, as it isn't finished anyway.
+func (db *Db) ListFailedKeywordEnginesByUploadId(uploadID int64) (model.Keywords, error) {
+	unfinished := db.Model(&model.Keyword{}).
+		Select("1").
+		Where("upload_id = ? AND status IN ?", uploadID, []string{model.KeywordStatusPending, model.KeywordStatusProcessing})
+
+	keywords := make(model.Keywords, 0)
+	if err := db.Select("id", "search_engine").
+		Where("upload_id = ? AND status = ? AND NOT EXISTS (?)", uploadID, model.KeywordStatusFailed, unfinished).
+		Order("id").
+		Find(&keywords).Error; err != nil {
+		return nil, err
+	}
+
+	return keywords, nil
+}
+
+// ListTopKeywordsByUploadId returns the completed keywords of the upload with the most ads.
+func (db *Db) ListTopKeywordsByUploadId(uploadID int64, limit int) (model.Keywords, error) {
+	keywords := make([]*model.Keyword, 0)
+	if err := db.Omit("html_content").
+		Where("upload_id = ? AND status = ? AND ad_count > 0", uploadID, model.KeywordStatusCompleted).
+		Order("ad_count desc, id").
+		Limit(limit).
+		Find(&keywords).Error; err != nil {
+		return nil, err
+	}
+
+	return keywords, nil
+}
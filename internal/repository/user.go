@@ -1,6 +1,10 @@
 package repository
 
-import "web-scraper.dev/internal/model"
+import (
+	"github.com/google/uuid"
+
+	"web-scraper.dev/internal/model"
+)
 
 func (db *Db) CreateUser(u *model.User) error {
 	return db.Create(u).Error
@@ -34,3 +38,21 @@ func (db *Db) ReadUserWithActivationTokenAndUserAuthByEmail(email string) (*mode
 
 	return user, nil
 }
+
+func (db *Db) ReadUserWithResetPasswordTokenByEmail(email string) (*model.User, error) {
+	user := &model.User{}
+	if err := db.Preload("ResetPasswordToken").Where("email = ?", email).First(user).Error; err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+func (db *Db) ReadUserById(id uuid.UUID) (*model.User, error) {
+	user := &model.User{}
+	if err := db.Where("id = ?", id).First(user).Error; err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
@@ -1,6 +1,8 @@
 package repository
 
 import (
+	"time"
+
 	"github.com/google/uuid"
 	"gorm.io/gorm/clause"
 
@@ -14,6 +16,11 @@ func (db *Db) CreateOrUpdateUserActivationTokenByUserId(uat *model.UserActivatio
 	}).Create(&uat).Error
 }
 
+// ExpireUserActivationTokenByUserId invalidates the activation code; the next activation attempt sends a new one.
+func (db *Db) ExpireUserActivationTokenByUserId(userId uuid.UUID) error {
+	return db.Model(&model.UserActivationToken{}).Where("user_id = ?", userId).Update("token_expired_at", time.Now()).Error
+}
+
 func (db *Db) DeleteUserActivationTokenByUserId(userId uuid.UUID) error {
 	return db.Where("user_id = ?", userId).Delete(&model.UserActivationToken{}).Error
 }
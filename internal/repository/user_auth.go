@@ -0,0 +1,11 @@
+package repository
+
+import (
+	"github.com/google/uuid"
+
+	"web-scraper.dev/internal/model"
+)
+
+func (db *Db) UpdateUserAuthPasswordByUserId(userId uuid.UUID, hashedPassword string) error {
+	return db.Model(&model.UserAuth{}).Where("user_id = ?", userId).Update("password", hashedPassword).Error
+}
@@ -0,0 +1,31 @@
+package repository
+
+import (
+	"errors"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"web-scraper.dev/internal/model"
+)
+
+// ReadUserPreferenceByUserId returns the preferences of the user, or the defaults if they have never been saved.
+func (db *Db) ReadUserPreferenceByUserId(userID uuid.UUID) (*model.UserPreference, error) {
+	pref := &model.UserPreference{}
+	if err := db.Where("user_id = ?", userID).First(pref).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return model.NewUserPreference(userID), nil
+		}
+		return nil, err
+	}
+
+	return pref, nil
+}
+
+func (db *Db) CreateOrUpdateUserPreference(pref *model.UserPreference) error {
+	return db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"upload_summary_email", "updated_at"}),
+	}).Create(pref).Error
+}
@@ -0,0 +1,19 @@
+package repository
+
+import (
+	"github.com/google/uuid"
+	"gorm.io/gorm/clause"
+
+	"web-scraper.dev/internal/model"
+)
+
+func (db *Db) CreateOrUpdateUserResetPasswordTokenByUserId(urpt *model.UserResetPasswordToken) error {
+	return db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}},
+		DoUpdates: clause.Assignments(map[string]any{"token": urpt.Token, "token_expired_at": urpt.TokenExpiredAt}),
+	}).Create(&urpt).Error
+}
+
+func (db *Db) DeleteUserResetPasswordTokenByUserId(userId uuid.UUID) error {
+	return db.Where("user_id = ?", userId).Delete(&model.UserResetPasswordToken{}).Error
+}
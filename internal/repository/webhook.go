@@ -0,0 +1,118 @@
+package repository
+
+import (
+	"encoding/json"
+
+	"github.com/google/uuid"
+
+	"web-scraper.dev/internal/model"
+)
+
+func (db *Db) CreateWebhook(wh *model.Webhook) error {
+	return db.Create(wh).Error
+}
+
+func (db *Db) ListWebhooksByUserId(userID uuid.UUID, offset, limit int) (model.Webhooks, int64, error) {
+	var total int64
+	if err := db.Model(&model.Webhook{}).Where("user_id = ?", userID).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	webhooks := make([]*model.Webhook, 0)
+	if err := db.Where("user_id = ?", userID).
+		Order("created_at desc, id desc").
+		Offset(offset).
+		Limit(limit).
+		Find(&webhooks).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return webhooks, total, nil
+}
+
+// ListActiveWebhooksByUserIdAndEvent returns the active webhooks of the user subscribed to the event type.
+func (db *Db) ListActiveWebhooksByUserIdAndEvent(userID uuid.UUID, event string) (model.Webhooks, error) {
+	events, err := json.Marshal([]string{event})
+	if err != nil {
+		return nil, err
+	}
+
+	webhooks := make([]*model.Webhook, 0)
+	if err := db.Where("user_id = ? AND active AND events @> ?", userID, string(events)).Find(&webhooks).Error; err != nil {
+		return nil, err
+	}
+
+	return webhooks, nil
+}
+
+func (db *Db) ReadWebhookById(id int64) (*model.Webhook, error) {
+	webhook := &model.Webhook{}
+	if err := db.Where("id = ?", id).First(webhook).Error; err != nil {
+		return nil, err
+	}
+	return webhook, nil
+}
+
+func (db *Db) ReadWebhookByIdAndUserId(id int64, userId uuid.UUID) (*model.Webhook, error) {
+	webhook := &model.Webhook{}
+	if err := db.Where("id = ? AND user_id = ?", id, userId).First(webhook).Error; err != nil {
+		return nil, err
+	}
+	return webhook, nil
+}
+
+func (db *Db) UpdateWebhookByIdAndUserId(id int64, userId uuid.UUID, updates map[string]any) (int64, error) {
+	res := db.Model(&model.Webhook{}).Where("id = ? AND user_id = ?", id, userId).Updates(updates)
+	return res.RowsAffected, res.Error
+}
+
+func (db *Db) DeleteWebhookByIdAndUserId(id int64, userId uuid.UUID) (int64, error) {
+	res := db.Where("id = ? AND user_id = ?", id, userId).Delete(&model.Webhook{})
+	return res.RowsAffected, res.Error
+}
+
+func (db *Db) DeleteWebhookDeliveriesByWebhookId(webhookID int64) error {
+	return db.Where("webhook_id = ?", webhookID).Delete(&model.WebhookDelivery{}).Error
+}
+
+func (db *Db) CreateWebhookDelivery(d *model.WebhookDelivery) error {
+	return db.Create(d).Error
+}
+
+func (db *Db) UpdateWebhookDeliveryById(id int64, updates map[string]any) error {
+	return db.Model(&model.WebhookDelivery{}).Where("id = ?", id).Updates(updates).Error
+}
+
+func (db *Db) ReadWebhookDeliveryById(id int64) (*model.WebhookDelivery, error) {
+	delivery := &model.WebhookDelivery{}
+	if err := db.Where("id = ?", id).First(delivery).Error; err != nil {
+		return nil, err
+	}
+	return delivery, nil
+}
+
+func (db *Db) ReadWebhookDeliveryByIdAndWebhookId(id int64, webhookID int64) (*model.WebhookDelivery, error) {
+	delivery := &model.WebhookDelivery{}
+	if err := db.Where("id = ? AND webhook_id = ?", id, webhookID).First(delivery).Error; err != nil {
+		return nil, err
+	}
+	return delivery, nil
+}
+
+func (db *Db) ListWebhookDeliveriesByWebhookId(webhookID int64, offset, limit int) (model.WebhookDeliveries, int64, error) {
+	var total int64
+	if err := db.Model(&model.WebhookDelivery{}).Where("webhook_id = ?", webhookID).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	deliveries := make([]*model.WebhookDelivery, 0)
+	if err := db.Where("webhook_id = ?", webhookID).
+		Order("created_at desc, id desc").
+		Offset(offset).
+		Limit(limit).
+		Find(&deliveries).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return deliveries, total, nil
+}
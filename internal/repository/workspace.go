@@ -0,0 +1,123 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm/clause"
+
+	"web-scraper.dev/internal/model"
+)
+
+func (db *Db) CreateWorkspace(ws *model.Workspace) error {
+	return db.Create(ws).Error
+}
+
+func (db *Db) UpdateWorkspaceNameById(id uuid.UUID, name string) error {
+	return db.Model(&model.Workspace{}).Where("id = ?", id).Update("name", name).Error
+}
+
+func (db *Db) CreateWorkspaceMember(m *model.WorkspaceMember) error {
+	return db.Create(m).Error
+}
+
+// ListWorkspaceMembersByUserId returns the memberships of the user with their workspaces, the personal one first.
+func (db *Db) ListWorkspaceMembersByUserId(userID uuid.UUID) (model.WorkspaceMembers, error) {
+	members := make([]*model.WorkspaceMember, 0)
+	if err := db.Preload("Workspace").
+		Where("user_id = ?", userID).
+		Order(clause.OrderBy{Expression: clause.Expr{SQL: "workspace_id = ? DESC", Vars: []any{userID}}}).
+		Order("created_at").
+		Find(&members).Error; err != nil {
+		return nil, err
+	}
+
+	return members, nil
+}
+
+func (db *Db) ListWorkspaceMembersByWorkspaceId(workspaceID uuid.UUID) (model.WorkspaceMembers, error) {
+	members := make([]*model.WorkspaceMember, 0)
+	if err := db.Preload("User").
+		Where("workspace_id = ?", workspaceID).
+		Order("created_at").
+		Find(&members).Error; err != nil {
+		return nil, err
+	}
+
+	return members, nil
+}
+
+func (db *Db) ReadWorkspaceMemberByWorkspaceIdAndUserId(workspaceID, userID uuid.UUID) (*model.WorkspaceMember, error) {
+	member := &model.WorkspaceMember{}
+	if err := db.Preload("Workspace").
+		Where("workspace_id = ? AND user_id = ?", workspaceID, userID).
+		First(member).Error; err != nil {
+		return nil, err
+	}
+
+	return member, nil
+}
+
+func (db *Db) UpdateWorkspaceMemberRoleByWorkspaceIdAndUserId(workspaceID, userID uuid.UUID, role string) (int64, error) {
+	res := db.Model(&model.WorkspaceMember{}).
+		Where("workspace_id = ? AND user_id = ?", workspaceID, userID).
+		Update("role", role)
+	return res.RowsAffected, res.Error
+}
+
+func (db *Db) DeleteWorkspaceMemberByWorkspaceIdAndUserId(workspaceID, userID uuid.UUID) (int64, error) {
+	res := db.Where("workspace_id = ? AND user_id = ?", workspaceID, userID).Delete(&model.WorkspaceMember{})
+	return res.RowsAffected, res.Error
+}
+
+// CountWorkspaceOwnersByWorkspaceId counts the owners, locking their rows so the last one can't be removed concurrently.
+func (db *Db) CountWorkspaceOwnersByWorkspaceId(workspaceID uuid.UUID) (int64, error) {
+	var owners []uuid.UUID
+	if err := db.Model(&model.WorkspaceMember{}).
+		Where("workspace_id = ? AND role = ?", workspaceID, model.WorkspaceRoleOwner).
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		Pluck("user_id", &owners).Error; err != nil {
+		return 0, err
+	}
+
+	return int64(len(owners)), nil
+}
+
+func (db *Db) CreateWorkspaceInvitation(i *model.WorkspaceInvitation) error {
+	return db.Create(i).Error
+}
+
+// ListPendingWorkspaceInvitationsByWorkspaceId returns the invitations neither accepted nor expired, latest first.
+func (db *Db) ListPendingWorkspaceInvitationsByWorkspaceId(workspaceID uuid.UUID) (model.WorkspaceInvitations, error) {
+	invitations := make([]*model.WorkspaceInvitation, 0)
+	if err := db.Where("workspace_id = ? AND accepted_at IS NULL AND expires_at > ?", workspaceID, time.Now()).
+		Order("created_at desc, id desc").
+		Find(&invitations).Error; err != nil {
+		return nil, err
+	}
+
+	return invitations, nil
+}
+
+func (db *Db) ReadWorkspaceInvitationByToken(token string) (*model.WorkspaceInvitation, error) {
+	invitation := &model.WorkspaceInvitation{}
+	if err := db.Where("token = ?", token).First(invitation).Error; err != nil {
+		return nil, err
+	}
+	return invitation, nil
+}
+
+// AcceptWorkspaceInvitationById marks the invitation as accepted and reports whether it was pending,
+// so it can't be accepted twice.
+func (db *Db) AcceptWorkspaceInvitationById(id int64) (bool, error) {
+	now := time.Now()
+	res := db.Model(&model.WorkspaceInvitation{}).
+		Where("id = ? AND accepted_at IS NULL AND expires_at > ?", id, now).
+		Update("accepted_at", now)
+	return res.RowsAffected == 1, res.Error
+}
+
+func (db *Db) DeleteWorkspaceInvitationByIdAndWorkspaceId(id int64, workspaceID uuid.UUID) (int64, error) {
+	res := db.Where("id = ? AND workspace_id = ?", id, workspaceID).Delete(&model.WorkspaceInvitation{})
+	return res.RowsAffected, res.Error
+}
@@ -0,0 +1,136 @@
+package revocation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	fmtTokenKey = "revoked:token:%s"
+	fmtUserKey  = "revoked:user:%s"
+
+	// cacheTTL bounds how long a token signed out through another API server can still be used here
+	cacheTTL = 5 * time.Second
+
+	// cacheSweepSize is the number of cached tokens to start dropping the expired ones at
+	cacheSweepSize = 10000
+)
+
+// Denylist keeps track of the access tokens revoked before they expire, by their jti in Redis,
+// and caches the lookups in-process so most of the requests don't reach Redis.
+type Denylist struct {
+	rdb                 redis.UniversalClient
+	accessTokenLifetime time.Duration
+
+	mu    sync.Mutex
+	cache map[string]cacheEntry
+}
+
+type cacheEntry struct {
+	userID    string
+	revoked   bool
+	expiresAt time.Time
+}
+
+func NewDenylist(rdb redis.UniversalClient, accessTokenLifetime time.Duration) *Denylist {
+	return &Denylist{
+		rdb:                 rdb,
+		accessTokenLifetime: accessTokenLifetime,
+		cache:               make(map[string]cacheEntry),
+	}
+}
+
+// RevokeToken revokes a single access token until it expires.
+func (d *Denylist) RevokeToken(ctx context.Context, userID, tokenID string, expiresAt time.Time) error {
+	ttl := time.Until(expiresAt)
+	if ttl <= 0 {
+		return nil
+	}
+
+	if err := d.rdb.Set(ctx, fmt.Sprintf(fmtTokenKey, tokenID), 1, ttl).Err(); err != nil {
+		return err
+	}
+
+	d.mu.Lock()
+	d.cache[tokenID] = cacheEntry{userID: userID, revoked: true, expiresAt: expiresAt}
+	d.mu.Unlock()
+
+	return nil
+}
+
+// RevokeUser revokes all the access tokens of a user issued up to now. The record outlives
+// the tokens it covers by an access token lifetime at most.
+func (d *Denylist) RevokeUser(ctx context.Context, userID string) error {
+	if err := d.rdb.Set(ctx, fmt.Sprintf(fmtUserKey, userID), time.Now().Unix(), d.accessTokenLifetime).Err(); err != nil {
+		return err
+	}
+
+	d.mu.Lock()
+	for tokenID, entry := range d.cache {
+		if entry.userID == userID {
+			delete(d.cache, tokenID)
+		}
+	}
+	d.mu.Unlock()
+
+	return nil
+}
+
+// IsRevoked reports whether the access token was revoked by itself or along with all the tokens of its user.
+func (d *Denylist) IsRevoked(ctx context.Context, userID, tokenID string, issuedAt, expiresAt time.Time) (bool, error) {
+	now := time.Now()
+
+	d.mu.Lock()
+	entry, ok := d.cache[tokenID]
+	d.mu.Unlock()
+	if ok && now.Before(entry.expiresAt) {
+		return entry.revoked, nil
+	}
+
+	vals, err := d.rdb.MGet(ctx, fmt.Sprintf(fmtTokenKey, tokenID), fmt.Sprintf(fmtUserKey, userID)).Result()
+	if err != nil {
+		return false, err
+	}
+
+	revoked := vals[0] != nil
+	if !revoked && vals[1] != nil {
+		s, _ := vals[1].(string)
+		revokedAt, err := strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			return false, errors.New("invalid user revocation time")
+		}
+
+		// Token times are in seconds; a token issued within the second of the revocation is revoked too
+		revoked = issuedAt.Unix() <= revokedAt
+	}
+
+	// A revocation can't be undone, so it's cached until the token expires
+	entry = cacheEntry{userID: userID, revoked: revoked, expiresAt: expiresAt}
+	if !revoked && now.Add(cacheTTL).Before(expiresAt) {
+		entry.expiresAt = now.Add(cacheTTL)
+	}
+
+	d.mu.Lock()
+	if len(d.cache) >= cacheSweepSize {
+		for id, e := range d.cache {
+			if !now.Before(e.expiresAt) {
+				delete(d.cache, id)
+			}
+		}
+
+		// Still full of live tokens; the cache is only a shortcut to Redis, so start over
+		if len(d.cache) >= cacheSweepSize {
+			clear(d.cache)
+		}
+	}
+	d.cache[tokenID] = entry
+	d.mu.Unlock()
+
+	return revoked, nil
+}
@@ -0,0 +1,57 @@
+package scheduler
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/robfig/cron/v3"
+)
+
+const (
+	ScheduleHourly = "hourly"
+	ScheduleDaily  = "daily"
+	ScheduleWeekly = "weekly"
+)
+
+var ErrInvalidSchedule = errors.New("invalid schedule")
+
+var scheduleCronspecs = map[string]string{
+	ScheduleHourly: "@hourly",
+	ScheduleDaily:  "@daily",
+	ScheduleWeekly: "@weekly",
+}
+
+// Normalize returns the schedule in the form stored in keywords.schedule;
+// one of hourly, daily, weekly or a standard 5-field cron expression.
+func Normalize(schedule string) (string, error) {
+	schedule = strings.TrimSpace(schedule)
+	if _, ok := scheduleCronspecs[strings.ToLower(schedule)]; ok {
+		return strings.ToLower(schedule), nil
+	}
+
+	if _, err := cron.ParseStandard(schedule); err != nil || strings.HasPrefix(schedule, "@") {
+		return "", ErrInvalidSchedule
+	}
+
+	return strings.Join(strings.Fields(schedule), " "), nil
+}
+
+// Cronspec returns the cron expression of a normalized schedule.
+func Cronspec(schedule string) string {
+	if v, ok := scheduleCronspecs[schedule]; ok {
+		return v
+	}
+
+	return schedule
+}
+
+// Next returns the next activation time of the schedule after the given time.
+func Next(schedule string, t time.Time) (time.Time, error) {
+	s, err := cron.ParseStandard(Cronspec(schedule))
+	if err != nil {
+		return time.Time{}, ErrInvalidSchedule
+	}
+
+	return s.Next(t), nil
+}
@@ -0,0 +1,71 @@
+package scheduler_test
+
+import (
+	"testing"
+	"time"
+
+	"web-scraper.dev/internal/scheduler"
+)
+
+func TestNormalize(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		schedule string
+		expected string
+		err      error
+	}{
+		{"hourly", "hourly", scheduler.ScheduleHourly, nil},
+		{"daily uppercase", " Daily ", scheduler.ScheduleDaily, nil},
+		{"cron", "30  6 * * 1-5", "30 6 * * 1-5", nil},
+		{"descriptor", "@every 1s", "", scheduler.ErrInvalidSchedule},
+		{"invalid cron", "* * *", "", scheduler.ErrInvalidSchedule},
+		{"empty", "", "", scheduler.ErrInvalidSchedule},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			schedule, err := scheduler.Normalize(tc.schedule)
+			if err != tc.err {
+				t.Fatalf("Wrong error: got %v want %v", err, tc.err)
+			}
+
+			if schedule != tc.expected {
+				t.Errorf("Wrong schedule: got %v want %v", schedule, tc.expected)
+			}
+		})
+	}
+}
+
+func TestNext(t *testing.T) {
+	t.Parallel()
+
+	from := time.Date(2025, 7, 1, 10, 15, 0, 0, time.UTC) // Tuesday
+	tests := []struct {
+		schedule string
+		expected time.Time
+	}{
+		{scheduler.ScheduleHourly, time.Date(2025, 7, 1, 11, 0, 0, 0, time.UTC)},
+		{scheduler.ScheduleDaily, time.Date(2025, 7, 2, 0, 0, 0, 0, time.UTC)},
+		{scheduler.ScheduleWeekly, time.Date(2025, 7, 6, 0, 0, 0, 0, time.UTC)},
+		{"30 6 * * 1-5", time.Date(2025, 7, 2, 6, 30, 0, 0, time.UTC)},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.schedule, func(t *testing.T) {
+			t.Parallel()
+
+			next, err := scheduler.Next(tc.schedule, from)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			if !next.Equal(tc.expected) {
+				t.Errorf("Wrong next time: got %v want %v", next, tc.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,98 @@
+package scheduler
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/hibiken/asynq"
+	"gorm.io/gorm"
+
+	"web-scraper.dev/internal/repository"
+	"web-scraper.dev/internal/tasks"
+	l "web-scraper.dev/internal/utils/logger"
+)
+
+type Scheduler struct {
+	mgr       *asynq.PeriodicTaskManager
+	inspector *asynq.Inspector
+}
+
+func New(redisOpt asynq.RedisClientOpt, db *gorm.DB, logger *l.Logger, syncInterval time.Duration) (*Scheduler, error) {
+	inspector := asynq.NewInspector(redisOpt)
+
+	mgr, err := asynq.NewPeriodicTaskManager(asynq.PeriodicTaskManagerOpts{
+		RedisConnOpt: redisOpt,
+		PeriodicTaskConfigProvider: &keywordConfigProvider{
+			db:     repository.New(db),
+			logger: logger,
+		},
+		SyncInterval: syncInterval,
+		SchedulerOpts: &asynq.SchedulerOpts{
+			Location: time.Local,
+			// Free the deterministic task ID held by the previous archived or completed run in the low queue; waiting and
+			// active runs are left to conflict, so a pending re-scrape or a retry isn't replaced
+			PreEnqueueFunc: func(task *asynq.Task, _ []asynq.Option) {
+				var p tasks.ScrapeKeywordPayload
+				if err := json.Unmarshal(task.Payload(), &p); err != nil {
+					return
+				}
+
+				id := tasks.ScrapeKeywordTaskID(p.KeywordID)
+				queues := []string{tasks.ScrapeKeywordQueue(tasks.QueueLow, p.SearchEngine)}
+				if err := tasks.DeleteFinishedTask(inspector, queues, id); err != nil && !errors.Is(err, tasks.ErrTaskLive) {
+					logger.Error().Err(err).Str("task_id", id).Msg("failed to delete previous scheduled task")
+				}
+			},
+			PostEnqueueFunc: func(info *asynq.TaskInfo, err error) {
+				if err != nil {
+					logger.Warn().Err(err).Msg("failed to enqueue scheduled task")
+				}
+			},
+		},
+	})
+	if err != nil {
+		inspector.Close()
+		return nil, err
+	}
+
+	return &Scheduler{mgr: mgr, inspector: inspector}, nil
+}
+
+func (s *Scheduler) Start() error {
+	return s.mgr.Start()
+}
+
+func (s *Scheduler) Stop() error {
+	s.mgr.Shutdown()
+	return s.inspector.Close()
+}
+
+// keywordConfigProvider provides a periodic scrape task per scheduled keyword.
+// The PeriodicTaskManager calls GetConfigs on every sync and adds or removes the changed ones.
+type keywordConfigProvider struct {
+	db     *repository.Db
+	logger *l.Logger
+}
+
+func (p *keywordConfigProvider) GetConfigs() ([]*asynq.PeriodicTaskConfig, error) {
+	keywords, err := p.db.ListScheduledKeywords()
+	if err != nil {
+		p.logger.Error().Err(err).Msg("failed to list scheduled keywords")
+		return nil, err
+	}
+
+	configs := make([]*asynq.PeriodicTaskConfig, 0, len(keywords))
+	for _, v := range keywords {
+		if v.Schedule == nil {
+			continue
+		}
+
+		configs = append(configs, &asynq.PeriodicTaskConfig{
+			Cronspec: Cronspec(*v.Schedule),
+			Task:     tasks.NewScrapeKeywordTask(v.ID, v.SearchEngine, tasks.QueueLow),
+		})
+	}
+
+	return configs, nil
+}
@@ -0,0 +1,76 @@
+package searchengine
+
+import (
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/gocolly/colly/v2"
+
+	"web-scraper.dev/internal/tasks"
+)
+
+const (
+	fmtBingSearchURL = "https://www.bing.com/search?q=%s"
+	bingAdSelector   = ".b_ad, .b_adurl, .sb_add, [data-bm], .b_adSlug"
+
+	bingOrganicResultSelector = "#b_results > li.b_algo"
+	bingAdBlockSelector       = "#b_results > li.b_ad"
+)
+
+type bing struct{}
+
+func (*bing) Name() string {
+	return Bing
+}
+
+func (*bing) SearchURL(keyword, market string) string {
+	searchURL := fmt.Sprintf(fmtBingSearchURL, url.QueryEscape(keyword))
+	if market != "" {
+		searchURL += "&setmkt=" + url.QueryEscape(market)
+	}
+	return searchURL
+}
+
+func (*bing) LimitRule() *colly.LimitRule {
+	return &colly.LimitRule{
+		DomainGlob:  "*bing.*",
+		Parallelism: 1,
+		Delay:       tasks.ScrapeKeywordDelayInSeconds * time.Second,
+	}
+}
+
+func (*bing) Markers() *Markers {
+	return &Markers{
+		CaptchaURLs: []string{"/turing/captcha"},
+		Captcha:     "#b_captcha, .captcha, form#challenge-form",
+		Consent:     "#bnp_container .bnp_btn_accept",
+		NoResults:   "#b_results > li.b_no",
+	}
+}
+
+func (*bing) Extract(e *colly.HTMLElement, result *Result) {
+	result.AdCount += e.DOM.Find(bingAdSelector).Length()
+	countLinks(e, result)
+
+	e.ForEach(bingOrganicResultSelector, func(_ int, el *colly.HTMLElement) {
+		href := el.ChildAttr("h2 a", "href")
+		if href == "" {
+			return
+		}
+
+		result.addOrganicResult(firstText(el, "h2"), resultURL(el, href), firstText(el, "cite"), firstText(el, ".b_caption p, p"))
+	})
+
+	// Ad blocks above the organic results are marked with b_adTop and those below with b_adBottom
+	e.ForEach(bingAdBlockSelector, func(_ int, block *colly.HTMLElement) {
+		blockName := AdBlockTop
+		if block.DOM.HasClass("b_adBottom") {
+			blockName = AdBlockBottom
+		}
+
+		block.ForEach("ul > li", func(_ int, el *colly.HTMLElement) {
+			result.addAd(blockName, domain(firstText(el, "cite"), el.ChildAttr("h2 a", "href")), firstText(el, "h2"))
+		})
+	})
+}
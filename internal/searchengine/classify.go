@@ -0,0 +1,85 @@
+package searchengine
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gocolly/colly/v2"
+)
+
+// Classifications of a results page, stored with the keywords and their runs
+const (
+	ClassificationOK = "ok"
+	// ClassificationCaptcha is a challenge page asking to prove being a human
+	ClassificationCaptcha = "captcha"
+	// ClassificationConsent is a cookie consent page in place of the results
+	ClassificationConsent = "consent"
+	// ClassificationRateLimited is a 429 response
+	ClassificationRateLimited = "rate_limited"
+	// ClassificationEmpty is a results page saying there is no result for the keyword
+	ClassificationEmpty = "empty"
+	// ClassificationParseDrift is a results page without any result found, most likely as its markup changed
+	ClassificationParseDrift = "parse_drift"
+)
+
+// Markers are how the pages other than the results pages of a search engine are recognized.
+type Markers struct {
+	// CaptchaURLs are parts of the URLs the challenge pages are redirected to
+	CaptchaURLs []string
+	// Captcha, Consent and NoResults are selectors of the elements only on the challenge,
+	// the consent and the no results pages
+	Captcha   string
+	Consent   string
+	NoResults string
+}
+
+// IsBlocked reports whether a page of the classification refused the request, so it's worth retrying later.
+func IsBlocked(classification string) bool {
+	switch classification {
+	case ClassificationCaptcha, ClassificationConsent, ClassificationRateLimited:
+		return true
+	}
+	return false
+}
+
+// ClassifyStatus classifies the responses with an error status; empty for the ones not refusing the request.
+func ClassifyStatus(statusCode int) string {
+	switch statusCode {
+	case http.StatusTooManyRequests:
+		return ClassificationRateLimited
+	case http.StatusForbidden:
+		return ClassificationCaptcha
+	}
+	return ""
+}
+
+// Classify classifies a page with the result extracted from it.
+func Classify(se SearchEngine, e *colly.HTMLElement, result *Result) string {
+	markers := se.Markers()
+
+	pageURL := e.Request.URL.String()
+	for _, v := range markers.CaptchaURLs {
+		if strings.Contains(pageURL, v) {
+			return ClassificationCaptcha
+		}
+	}
+
+	if len(result.OrganicResults) > 0 {
+		return ClassificationOK
+	}
+
+	switch {
+	case has(e, markers.Captcha):
+		return ClassificationCaptcha
+	case has(e, markers.Consent):
+		return ClassificationConsent
+	case has(e, markers.NoResults):
+		return ClassificationEmpty
+	}
+
+	return ClassificationParseDrift
+}
+
+func has(e *colly.HTMLElement, selector string) bool {
+	return selector != "" && e.DOM.Find(selector).Length() > 0
+}
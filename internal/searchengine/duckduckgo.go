@@ -0,0 +1,70 @@
+package searchengine
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gocolly/colly/v2"
+
+	"web-scraper.dev/internal/tasks"
+)
+
+const (
+	// The HTML-only endpoint renders results server side, no JS required
+	fmtDuckDuckGoSearchURL = "https://html.duckduckgo.com/html/?q=%s"
+	duckDuckGoAdSelector   = ".result--ad"
+
+	duckDuckGoOrganicResultSelector = ".result:not(.result--ad)"
+)
+
+type duckDuckGo struct{}
+
+func (*duckDuckGo) Name() string {
+	return DuckDuckGo
+}
+
+func (*duckDuckGo) SearchURL(keyword, market string) string {
+	searchURL := fmt.Sprintf(fmtDuckDuckGoSearchURL, url.QueryEscape(keyword))
+
+	// DuckDuckGo regions are in the region-language form, ex: "us-en"
+	if lang, region, ok := strings.Cut(market, "-"); ok {
+		searchURL += "&kl=" + url.QueryEscape(strings.ToLower(region+"-"+lang))
+	}
+	return searchURL
+}
+
+func (*duckDuckGo) LimitRule() *colly.LimitRule {
+	return &colly.LimitRule{
+		DomainGlob:  "*duckduckgo.*",
+		Parallelism: 1,
+		Delay:       tasks.ScrapeKeywordDelayInSeconds * time.Second,
+	}
+}
+
+func (*duckDuckGo) Markers() *Markers {
+	return &Markers{
+		Captcha:   ".anomaly-modal, #challenge-form",
+		NoResults: ".no-results",
+	}
+}
+
+func (*duckDuckGo) Extract(e *colly.HTMLElement, result *Result) {
+	result.AdCount += e.DOM.Find(duckDuckGoAdSelector).Length()
+	countLinks(e, result)
+
+	e.ForEach(duckDuckGoOrganicResultSelector, func(_ int, el *colly.HTMLElement) {
+		href := el.ChildAttr(".result__a", "href")
+		if href == "" {
+			return
+		}
+
+		result.addOrganicResult(firstText(el, ".result__a"), resultURL(el, href), firstText(el, ".result__url"), firstText(el, ".result__snippet"))
+	})
+
+	// Ads are only shown above the organic results
+	e.ForEach(duckDuckGoAdSelector, func(_ int, el *colly.HTMLElement) {
+		result.addAd(AdBlockTop, domain(firstText(el, ".result__url")), firstText(el, ".result__a"))
+	})
+}
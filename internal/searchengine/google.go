@@ -0,0 +1,80 @@
+package searchengine
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gocolly/colly/v2"
+
+	"web-scraper.dev/internal/tasks"
+)
+
+const (
+	fmtGoogleSearchURL = "https://www.google.com/search?q=%s&hl=%s"
+	googleAdSelector   = "#tads [data-text-ad], #bottomads [data-text-ad]"
+
+	googleOrganicResultSelector = "#search div.g"
+	googleTopAdSelector         = "#tads [data-text-ad]"
+	googleBottomAdSelector      = "#bottomads [data-text-ad]"
+)
+
+type google struct{}
+
+func (*google) Name() string {
+	return Google
+}
+
+func (*google) SearchURL(keyword, market string) string {
+	lang, region, ok := strings.Cut(market, "-")
+	if !ok {
+		return fmt.Sprintf(fmtGoogleSearchURL, url.QueryEscape(keyword), "en")
+	}
+	return fmt.Sprintf(fmtGoogleSearchURL, url.QueryEscape(keyword), url.QueryEscape(lang)) + "&gl=" + url.QueryEscape(region)
+}
+
+func (*google) LimitRule() *colly.LimitRule {
+	return &colly.LimitRule{
+		DomainGlob:  "*google.*",
+		Parallelism: 1,
+		Delay:       tasks.ScrapeKeywordDelayInSeconds * time.Second,
+	}
+}
+
+func (*google) Markers() *Markers {
+	return &Markers{
+		CaptchaURLs: []string{"/sorry/"},
+		Captcha:     "form#captcha-form, #recaptcha",
+		Consent:     "form[action*='consent.google']",
+		NoResults:   "#topstuff .card-section, #botstuff .card-section",
+	}
+}
+
+func (*google) Extract(e *colly.HTMLElement, result *Result) {
+	result.AdCount += e.DOM.Find(googleAdSelector).Length()
+	countLinks(e, result)
+
+	e.ForEach(googleOrganicResultSelector, func(_ int, el *colly.HTMLElement) {
+		// Result groups nest div.g elements in each other
+		if el.DOM.ParentsFiltered("div.g").Length() > 0 {
+			return
+		}
+
+		href := el.ChildAttr("a:has(h3)", "href")
+		if href == "" {
+			return
+		}
+
+		result.addOrganicResult(firstText(el, "h3"), resultURL(el, href), firstText(el, "cite"), firstText(el, "[data-sncf], .VwiC3b"))
+	})
+
+	extractAds := func(selector, block string) {
+		e.ForEach(selector, func(_ int, el *colly.HTMLElement) {
+			href := resultURL(el, el.ChildAttr("a[href]", "href"))
+			result.addAd(block, domain(el.ChildAttr("[data-dtld]", "data-dtld"), href), firstText(el, "[role=heading]"))
+		})
+	}
+	extractAds(googleTopAdSelector, AdBlockTop)
+	extractAds(googleBottomAdSelector, AdBlockBottom)
+}
@@ -0,0 +1,198 @@
+package searchengine
+
+import (
+	"errors"
+	"net/url"
+	"slices"
+	"strings"
+
+	"github.com/gocolly/colly/v2"
+)
+
+const (
+	Bing       = "bing"
+	Google     = "google"
+	DuckDuckGo = "duckduckgo"
+)
+
+const (
+	AdBlockTop    = "top"
+	AdBlockBottom = "bottom"
+)
+
+var ErrUnsupported = errors.New("unsupported search engine")
+
+type SearchEngine interface {
+	// Name returns the value stored in keywords.search_engine.
+	Name() string
+	// SearchURL builds the results page URL for the given keyword and market, an empty market for the engine's default.
+	SearchURL(keyword, market string) string
+	// LimitRule returns the colly rate-limit rule for the engine's domains.
+	LimitRule() *colly.LimitRule
+	// Markers returns how the engine's challenge, consent and no results pages are recognized.
+	Markers() *Markers
+	// Extract collects the result data from the root element of a results page.
+	Extract(e *colly.HTMLElement, result *Result)
+}
+
+type Result struct {
+	AdCount        int
+	LinkCount      int
+	OrganicResults []*OrganicResult
+	Ads            []*Ad
+}
+
+type OrganicResult struct {
+	Position   int
+	Title      string
+	URL        string
+	DisplayURL string
+	Snippet    string
+}
+
+type Ad struct {
+	Position         int
+	Block            string
+	AdvertiserDomain string
+	Headline         string
+}
+
+var engines = map[string]SearchEngine{
+	Bing:       &bing{},
+	Google:     &google{},
+	DuckDuckGo: &duckDuckGo{},
+}
+
+func Get(name string) (SearchEngine, error) {
+	se, ok := engines[name]
+	if !ok {
+		return nil, ErrUnsupported
+	}
+
+	return se, nil
+}
+
+// Names returns the names of the supported search engines, sorted.
+func Names() []string {
+	names := make([]string, 0, len(engines))
+	for k := range engines {
+		names = append(names, k)
+	}
+	slices.Sort(names)
+
+	return names
+}
+
+func IsSupported(name string) bool {
+	_, ok := engines[name]
+	return ok
+}
+
+// NormalizeMarket returns the market in the language-REGION form, ex: "en-US", and reports whether it is valid.
+func NormalizeMarket(market string) (string, bool) {
+	lang, region, ok := strings.Cut(market, "-")
+	if !ok || len(lang) != 2 || len(region) != 2 || !isLetters(lang+region) {
+		return "", false
+	}
+
+	return strings.ToLower(lang) + "-" + strings.ToUpper(region), true
+}
+
+func isLetters(s string) bool {
+	for _, r := range s {
+		if (r < 'a' || r > 'z') && (r < 'A' || r > 'Z') {
+			return false
+		}
+	}
+	return true
+}
+
+func countLinks(e *colly.HTMLElement, result *Result) {
+	e.ForEach("a[href]", func(_ int, a *colly.HTMLElement) {
+		href := a.Attr("href")
+		if href != "" && !strings.HasPrefix(href, "#") {
+			result.LinkCount++
+		}
+	})
+}
+
+func (r *Result) addOrganicResult(title, url, displayURL, snippet string) {
+	if title == "" || url == "" {
+		return
+	}
+
+	r.OrganicResults = append(r.OrganicResults, &OrganicResult{
+		Position:   len(r.OrganicResults) + 1,
+		Title:      title,
+		URL:        url,
+		DisplayURL: displayURL,
+		Snippet:    snippet,
+	})
+}
+
+func (r *Result) addAd(block, advertiserDomain, headline string) {
+	if headline == "" && advertiserDomain == "" {
+		return
+	}
+
+	position := 1
+	for _, v := range r.Ads {
+		if v.Block == block {
+			position++
+		}
+	}
+
+	r.Ads = append(r.Ads, &Ad{
+		Position:         position,
+		Block:            block,
+		AdvertiserDomain: advertiserDomain,
+		Headline:         headline,
+	})
+}
+
+// firstText returns the stripped text content of the first matching element.
+func firstText(e *colly.HTMLElement, selector string) string {
+	return strings.Join(strings.Fields(e.DOM.Find(selector).First().Text()), " ")
+}
+
+// resultURL returns the absolute URL of a result link, unwrapping the search engine's own redirect links.
+func resultURL(e *colly.HTMLElement, href string) string {
+	href = e.Request.AbsoluteURL(href)
+
+	u, err := url.Parse(href)
+	if err != nil {
+		return href
+	}
+
+	q := u.Query()
+	switch {
+	case strings.HasSuffix(u.Host, "google.com") && u.Path == "/url" && q.Get("q") != "":
+		return q.Get("q")
+	case strings.HasSuffix(u.Host, "duckduckgo.com") && u.Path == "/l/" && q.Get("uddg") != "":
+		return q.Get("uddg")
+	}
+
+	return href
+}
+
+// domain returns the host name of the first parsable URL or bare domain, without the "www." prefix.
+func domain(values ...string) string {
+	for _, v := range values {
+		// Display URLs may have breadcrumbs after the host, ex: "https://example.com › docs"
+		fields := strings.Fields(v)
+		if len(fields) == 0 {
+			continue
+		}
+
+		v = fields[0]
+		if !strings.Contains(v, "://") {
+			v = "https://" + v
+		}
+
+		if u, err := url.Parse(v); err == nil && u.Hostname() != "" {
+			return strings.TrimPrefix(strings.ToLower(u.Hostname()), "www.")
+		}
+	}
+
+	return ""
+}
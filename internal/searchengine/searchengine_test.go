@@ -0,0 +1,267 @@
+package searchengine_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gocolly/colly/v2"
+
+	"web-scraper.dev/internal/searchengine"
+)
+
+func TestGet(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name            string
+		searchURL       string
+		marketSearchURL string
+	}{
+		{searchengine.Bing, "https://www.bing.com/search?q=go+lang", "https://www.bing.com/search?q=go+lang&setmkt=de-DE"},
+		{searchengine.Google, "https://www.google.com/search?q=go+lang&hl=en", "https://www.google.com/search?q=go+lang&hl=de&gl=DE"},
+		{searchengine.DuckDuckGo, "https://html.duckduckgo.com/html/?q=go+lang", "https://html.duckduckgo.com/html/?q=go+lang&kl=de-de"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			se, err := searchengine.Get(tc.name)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			if se.Name() != tc.name {
+				t.Errorf("Wrong name: got %v want %v", se.Name(), tc.name)
+			}
+
+			if searchURL := se.SearchURL("go lang", ""); searchURL != tc.searchURL {
+				t.Errorf("Wrong search URL: got %v want %v", searchURL, tc.searchURL)
+			}
+
+			if searchURL := se.SearchURL("go lang", "de-DE"); searchURL != tc.marketSearchURL {
+				t.Errorf("Wrong market search URL: got %v want %v", searchURL, tc.marketSearchURL)
+			}
+		})
+	}
+
+	if _, err := searchengine.Get("yahoo"); err != searchengine.ErrUnsupported {
+		t.Errorf("Wrong error: got %v want %v", err, searchengine.ErrUnsupported)
+	}
+}
+
+func TestNormalizeMarket(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		input    string
+		expected string
+		ok       bool
+	}{
+		{"en-US", "en-US", true},
+		{"EN-us", "en-US", true},
+		{"en", "", false},
+		{"en-USA", "", false},
+		{"e1-US", "", false},
+	}
+
+	for _, tc := range tests {
+		market, ok := searchengine.NormalizeMarket(tc.input)
+		if market != tc.expected || ok != tc.ok {
+			t.Errorf("Wrong market for %v: got %v, %v want %v, %v", tc.input, market, ok, tc.expected, tc.ok)
+		}
+	}
+}
+
+func TestExtract(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		body      string
+		adCount   int
+		linkCount int
+	}{
+		{
+			name:      searchengine.Bing,
+			body:      `<ol id="b_results"><li class="b_ad"><a href="https://ad.example.com">Ad</a></li><li class="b_algo"><a href="https://example.com">Result</a></li></ol><a href="#top">Top</a>`,
+			adCount:   1,
+			linkCount: 2,
+		},
+		{
+			name:      searchengine.Google,
+			body:      `<div id="tads"><div data-text-ad="1"><a href="https://ad.example.com">Ad</a></div></div><div class="g"><a href="https://example.com">Result</a></div><div id="bottomads"><div data-text-ad="1"></div></div>`,
+			adCount:   2,
+			linkCount: 2,
+		},
+		{
+			name:      searchengine.DuckDuckGo,
+			body:      `<div class="result result--ad"><a href="https://ad.example.com">Ad</a></div><div class="result"><a href="https://example.com">Result</a></div>`,
+			adCount:   1,
+			linkCount: 2,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "text/html")
+				fmt.Fprintf(w, "<html><body>%s</body></html>", tc.body)
+			}))
+			defer srv.Close()
+
+			se, _ := searchengine.Get(tc.name)
+
+			var result searchengine.Result
+			c := colly.NewCollector()
+			c.OnHTML("html", func(e *colly.HTMLElement) {
+				se.Extract(e, &result)
+			})
+
+			if err := c.Visit(srv.URL); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			if result.AdCount != tc.adCount {
+				t.Errorf("Wrong ad count: got %v want %v", result.AdCount, tc.adCount)
+			}
+
+			if result.LinkCount != tc.linkCount {
+				t.Errorf("Wrong link count: got %v want %v", result.LinkCount, tc.linkCount)
+			}
+		})
+	}
+}
+
+func TestExtractResults(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		body    string
+		results []searchengine.OrganicResult
+		ads     []searchengine.Ad
+	}{
+		{
+			name: searchengine.Bing,
+			body: `<ol id="b_results">
+<li class="b_ad b_adTop"><ul><li><h2><a href="https://www.bing.com/aclk?ld=1">Buy Go Books</a></h2><cite>https://www.gobooks.example › go</cite></li></ul></li>
+<li class="b_algo"><h2><a href="https://go.dev/">The Go Programming Language</a></h2><cite>https://go.dev</cite><div class="b_caption"><p>Go is an open source programming language.</p></div></li>
+<li class="b_ad b_adBottom"><ul><li><h2><a href="https://www.bing.com/aclk?ld=2">Learn Go</a></h2><cite>learn.example</cite></li></ul></li>
+</ol>`,
+			results: []searchengine.OrganicResult{
+				{Position: 1, Title: "The Go Programming Language", URL: "https://go.dev/", DisplayURL: "https://go.dev", Snippet: "Go is an open source programming language."},
+			},
+			ads: []searchengine.Ad{
+				{Position: 1, Block: searchengine.AdBlockTop, AdvertiserDomain: "gobooks.example", Headline: "Buy Go Books"},
+				{Position: 1, Block: searchengine.AdBlockBottom, AdvertiserDomain: "learn.example", Headline: "Learn Go"},
+			},
+		},
+		{
+			name: searchengine.DuckDuckGo,
+			body: `<div class="result result--ad"><a class="result__a" href="/y.js?ad=1">Go Hosting</a><a class="result__url">www.hosting.example</a></div>
+<div class="result"><a class="result__a" href="https://duckduckgo.com/l/?uddg=https%3A%2F%2Fgo.dev%2F">The Go Programming Language</a><a class="result__url">go.dev</a><a class="result__snippet">Build simple, secure, scalable systems.</a></div>`,
+			results: []searchengine.OrganicResult{
+				{Position: 1, Title: "The Go Programming Language", URL: "https://go.dev/", DisplayURL: "go.dev", Snippet: "Build simple, secure, scalable systems."},
+			},
+			ads: []searchengine.Ad{
+				{Position: 1, Block: searchengine.AdBlockTop, AdvertiserDomain: "hosting.example", Headline: "Go Hosting"},
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "text/html")
+				fmt.Fprintf(w, "<html><body>%s</body></html>", tc.body)
+			}))
+			defer srv.Close()
+
+			se, _ := searchengine.Get(tc.name)
+
+			var result searchengine.Result
+			c := colly.NewCollector()
+			c.OnHTML("html", func(e *colly.HTMLElement) {
+				se.Extract(e, &result)
+			})
+
+			if err := c.Visit(srv.URL); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			if len(result.OrganicResults) != len(tc.results) {
+				t.Fatalf("Wrong organic result count: got %v want %v", len(result.OrganicResults), len(tc.results))
+			}
+			for i, v := range result.OrganicResults {
+				if *v != tc.results[i] {
+					t.Errorf("Wrong organic result: got %+v want %+v", *v, tc.results[i])
+				}
+			}
+
+			if len(result.Ads) != len(tc.ads) {
+				t.Fatalf("Wrong ad count: got %v want %v", len(result.Ads), len(tc.ads))
+			}
+			for i, v := range result.Ads {
+				if *v != tc.ads[i] {
+					t.Errorf("Wrong ad: got %+v want %+v", *v, tc.ads[i])
+				}
+			}
+		})
+	}
+}
+
+func TestClassify(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		engine   string
+		path     string
+		body     string
+		expected string
+	}{
+		{"results", searchengine.Bing, "/search", `<ol id="b_results"><li class="b_algo"><h2><a href="https://go.dev/">Go</a></h2></li></ol>`, searchengine.ClassificationOK},
+		{"challenge redirect", searchengine.Bing, "/turing/captcha/challenge", `<a href="https://www.bing.com">Bing</a>`, searchengine.ClassificationCaptcha},
+		{"challenge page", searchengine.Google, "/search", `<form id="captcha-form"><div id="recaptcha"></div></form>`, searchengine.ClassificationCaptcha},
+		{"consent wall", searchengine.Google, "/search", `<form action="https://consent.google.com/save"><button>Accept all</button></form>`, searchengine.ClassificationConsent},
+		{"no results", searchengine.DuckDuckGo, "/html/", `<div class="no-results">No results.</div>`, searchengine.ClassificationEmpty},
+		{"parse drift", searchengine.Bing, "/search", `<div id="b_content"><a href="https://go.dev/">Go</a></div>`, searchengine.ClassificationParseDrift},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "text/html")
+				fmt.Fprintf(w, "<html><body>%s</body></html>", tc.body)
+			}))
+			defer srv.Close()
+
+			se, _ := searchengine.Get(tc.engine)
+
+			var classification string
+			c := colly.NewCollector()
+			c.OnHTML("html", func(e *colly.HTMLElement) {
+				var result searchengine.Result
+				se.Extract(e, &result)
+				classification = searchengine.Classify(se, e, &result)
+			})
+
+			if err := c.Visit(srv.URL + tc.path); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			if classification != tc.expected {
+				t.Errorf("Wrong classification: got %v want %v", classification, tc.expected)
+			}
+		})
+	}
+}
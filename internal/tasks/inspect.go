@@ -0,0 +1,94 @@
+package tasks
+
+import (
+	"errors"
+
+	"github.com/hibiken/asynq"
+)
+
+var (
+	ErrTaskActive = errors.New("task is being processed")
+	ErrTaskLive   = errors.New("task is waiting or being processed")
+)
+
+// DeleteTask deletes the task with the given ID from the queues unless it's being processed, so a new task can be enqueued with the same ID.
+// Asynq keeps archived tasks and rejects new tasks with their IDs.
+func DeleteTask(inspector *asynq.Inspector, queues []string, id string) error {
+	return deleteTasks(inspector, queues, id, false)
+}
+
+// DeleteFinishedTask deletes the task with the given ID from the queues only once archived or completed, returning ErrTaskLive
+// if it's still waiting or being processed, so a new task doesn't replace a pending or retrying one.
+func DeleteFinishedTask(inspector *asynq.Inspector, queues []string, id string) error {
+	return deleteTasks(inspector, queues, id, true)
+}
+
+// CancelTask deletes the task with the given ID from the queues or cancels it if it's being processed.
+func CancelTask(inspector *asynq.Inspector, queues []string, id string) error {
+	err := DeleteTask(inspector, queues, id)
+	if errors.Is(err, ErrTaskActive) {
+		return inspector.CancelProcessing(id)
+	}
+
+	return err
+}
+
+// TaskRetrying reports whether the task with the given ID is waiting for a retry in any of the queues.
+func TaskRetrying(inspector *asynq.Inspector, queues []string, id string) (bool, error) {
+	for _, queue := range queues {
+		info, err := inspector.GetTaskInfo(queue, id)
+		if err != nil {
+			if errors.Is(err, asynq.ErrTaskNotFound) || errors.Is(err, asynq.ErrQueueNotFound) {
+				continue
+			}
+			return false, err
+		}
+
+		if info.State == asynq.TaskStateRetry {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+func deleteTasks(inspector *asynq.Inspector, queues []string, id string, finishedOnly bool) error {
+	var kept error
+	for _, queue := range queues {
+		err := deleteTask(inspector, queue, id, finishedOnly)
+		if errors.Is(err, ErrTaskActive) || errors.Is(err, ErrTaskLive) {
+			kept = err
+			continue
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	return kept
+}
+
+func deleteTask(inspector *asynq.Inspector, queue, id string, finishedOnly bool) error {
+	info, err := inspector.GetTaskInfo(queue, id)
+	if err != nil {
+		if errors.Is(err, asynq.ErrTaskNotFound) || errors.Is(err, asynq.ErrQueueNotFound) {
+			return nil
+		}
+		return err
+	}
+
+	finished := info.State == asynq.TaskStateArchived || info.State == asynq.TaskStateCompleted
+	if finishedOnly && !finished {
+		return ErrTaskLive
+	}
+
+	if info.State == asynq.TaskStateActive {
+		return ErrTaskActive
+	}
+
+	if err := inspector.DeleteTask(queue, id); err != nil && !errors.Is(err, asynq.ErrTaskNotFound) {
+		return err
+	}
+
+	return nil
+}
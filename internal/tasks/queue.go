@@ -0,0 +1,70 @@
+package tasks
+
+import (
+	"fmt"
+	"strings"
+)
+
+// The queues by priority. The scrape tasks go to the queues of their search engine, ex: "critical:bing",
+// so a worker can process the scrapes of some search engines only.
+const (
+	// QueueCritical is for the manual re-scrapes
+	QueueCritical = "critical"
+	// QueueDefault is for the uploads, the webhooks and the upload summaries
+	QueueDefault = "default"
+	// QueueLow is for the scheduled scrapes
+	QueueLow = "low"
+
+	fmtEngineQueue = "%s:%s"
+)
+
+var Priorities = []string{QueueCritical, QueueDefault, QueueLow}
+
+// FairPriorities are the priorities of the scrapes going through the fair queues before asynq, taken round-robin
+// across the workspaces. The scheduled scrapes go straight to asynq.
+var FairPriorities = []string{QueueCritical, QueueDefault}
+
+// ScrapeKeywordQueue returns the queue of the scrape tasks of the search engine with the priority.
+func ScrapeKeywordQueue(priority, engine string) string {
+	return fmt.Sprintf(fmtEngineQueue, priority, engine)
+}
+
+// ScrapeKeywordQueues returns the queues of the scrape tasks of the search engine, by priority.
+func ScrapeKeywordQueues(engine string) []string {
+	queues := make([]string, len(Priorities))
+	for i, v := range Priorities {
+		queues[i] = ScrapeKeywordQueue(v, engine)
+	}
+
+	return queues
+}
+
+// FairScrapeKeywordQueues returns the queues of the scrape tasks of the search engine going through the fair queues, by priority.
+func FairScrapeKeywordQueues(engine string) []string {
+	queues := make([]string, len(FairPriorities))
+	for i, v := range FairPriorities {
+		queues[i] = ScrapeKeywordQueue(v, engine)
+	}
+
+	return queues
+}
+
+// WorkerQueues expands the configured queue weights of a worker: a priority queue includes the queues of all the search engines
+// with the same weight, while the queue of a search engine is kept as is, ex: "default:google".
+func WorkerQueues(weights map[string]int, engines []string) map[string]int {
+	queues := make(map[string]int, len(weights)*(len(engines)+1))
+	for name, weight := range weights {
+		queues[name] = weight
+		if strings.Contains(name, ":") {
+			continue
+		}
+
+		for _, engine := range engines {
+			if _, ok := weights[ScrapeKeywordQueue(name, engine)]; !ok {
+				queues[ScrapeKeywordQueue(name, engine)] = weight
+			}
+		}
+	}
+
+	return queues
+}
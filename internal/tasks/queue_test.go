@@ -0,0 +1,50 @@
+package tasks_test
+
+import (
+	"maps"
+	"testing"
+
+	"web-scraper.dev/internal/tasks"
+)
+
+func TestWorkerQueues(t *testing.T) {
+	t.Parallel()
+
+	engines := []string{"bing", "google"}
+
+	tests := []struct {
+		name     string
+		weights  map[string]int
+		expected map[string]int
+	}{
+		{
+			name:    "priorities",
+			weights: map[string]int{"critical": 6, "default": 3, "low": 1},
+			expected: map[string]int{
+				"critical": 6, "critical:bing": 6, "critical:google": 6,
+				"default": 3, "default:bing": 3, "default:google": 3,
+				"low": 1, "low:bing": 1, "low:google": 1,
+			},
+		},
+		{
+			name:     "search engine",
+			weights:  map[string]int{"critical:google": 6, "low:google": 1},
+			expected: map[string]int{"critical:google": 6, "low:google": 1},
+		},
+		{
+			name:     "override",
+			weights:  map[string]int{"default": 3, "default:google": 1},
+			expected: map[string]int{"default": 3, "default:bing": 3, "default:google": 1},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			if queues := tasks.WorkerQueues(tc.weights, engines); !maps.Equal(queues, tc.expected) {
+				t.Errorf("Wrong queues: got %v want %v", queues, tc.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,64 @@
+package tasks
+
+import (
+	"errors"
+	"math/rand/v2"
+	"time"
+
+	"github.com/hibiken/asynq"
+)
+
+const retryJitterFraction = 0.1
+
+// RetryDelay is the asynq.RetryDelayFunc of the workers.
+//   - Webhook deliveries are retried after 30s, 1m, 2m, ... capped at 6h, so a receiver can be down for about 8 hours.
+//   - Scrapes blocked by the search engine are retried after 1m, 2m, 4m, ... capped at 1h.
+//   - Scrapes of a paused search engine are retried once the pause is over.
+//   - Scrapes of a user with too many scrapes in flight are retried after 10s.
+//
+// Other tasks keep the asynq default.
+func RetryDelay(n int, err error, t *asynq.Task) time.Duration {
+	switch t.Type() {
+	case TypeDeliverWebhook:
+		return backoff(n, deliverWebhookRetryDelayBase, deliverWebhookRetryDelayMax)
+	case TypeScrapeKeyword:
+		var paused *EnginePausedError
+		if errors.As(err, &paused) {
+			return jitter(max(time.Until(paused.Until), time.Second))
+		}
+
+		var busy *UserBusyError
+		if errors.As(err, &busy) {
+			return jitter(scrapeKeywordBusyDelay)
+		}
+
+		if errors.Is(err, ErrScrapeBlocked) {
+			return backoff(n, scrapeKeywordRetryDelayBase, scrapeKeywordRetryDelayMax)
+		}
+	}
+
+	return asynq.DefaultRetryDelayFunc(n, err, t)
+}
+
+// IsFailure is the asynq IsFailure func of the workers; waiting for a paused search engine or for the other scrapes of a user
+// doesn't use up the retries of a scrape.
+func IsFailure(err error) bool {
+	var paused *EnginePausedError
+	var busy *UserBusyError
+	return !errors.As(err, &paused) && !errors.As(err, &busy)
+}
+
+// backoff doubles the delay from base after each retry, capped at maxDelay.
+func backoff(n int, base, maxDelay time.Duration) time.Duration {
+	delay := maxDelay
+	if n < 20 {
+		delay = min(base<<n, maxDelay)
+	}
+
+	return jitter(delay)
+}
+
+// jitter spreads the retries of the tasks failed at the same time.
+func jitter(delay time.Duration) time.Duration {
+	return delay + time.Duration(rand.Float64()*retryJitterFraction*float64(delay))
+}
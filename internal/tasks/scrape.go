@@ -1,22 +1,63 @@
 package tasks
 
 import (
+	"errors"
 	"fmt"
+	"time"
 
+	"github.com/google/uuid"
 	"github.com/hibiken/asynq"
 )
 
 const (
 	TypeScrapeKeyword           = "scrape:keyword"
-	fmtScrapeKeywordPayloadJSON = `{"keywordID": %d}`
+	fmtScrapeKeywordPayloadJSON = `{"keywordID": %d, "searchEngine": %q}`
+	fmtScrapeKeywordTaskID      = "scrape:keyword:%d"
 	ScrapeKeywordDelayInSeconds = 1 // Enqueue with a delay to avoid rate limiting
+	scrapeKeywordRetryDelayBase = time.Minute
+	scrapeKeywordRetryDelayMax  = time.Hour
+	scrapeKeywordBusyDelay      = 10 * time.Second
 )
 
+// ErrScrapeBlocked is returned by the scrape tasks refused by the search engine, retried with an exponential backoff by RetryDelay.
+var ErrScrapeBlocked = errors.New("scrape blocked by search engine")
+
+// EnginePausedError is returned by the scrape tasks of a search engine paused for blocking too many scrapes.
+// The tasks are retried once the pause is over, without counting as a failure.
+type EnginePausedError struct {
+	Engine string
+	Until  time.Time
+}
+
+func (e *EnginePausedError) Error() string {
+	return fmt.Sprintf("search engine %s paused until %s", e.Engine, e.Until.Format(time.RFC3339))
+}
+
+// UserBusyError is returned by the scrape tasks of a user with too many scrapes being processed already.
+// The tasks are retried shortly, without counting as a failure, letting the tasks of the other users through.
+type UserBusyError struct {
+	UserID uuid.UUID
+}
+
+func (e *UserBusyError) Error() string {
+	return fmt.Sprintf("user %s has too many scrapes in flight", e.UserID)
+}
+
 type ScrapeKeywordPayload struct {
-	KeywordID int64 `json:"keywordID"`
+	KeywordID    int64  `json:"keywordID"`
+	SearchEngine string `json:"searchEngine"`
+}
+
+// NewScrapeKeywordTask creates the scrape task of a keyword in the queue of its search engine with the priority,
+// with a deterministic task ID, so the pending or in-flight task of a keyword can be looked up, deleted or canceled.
+func NewScrapeKeywordTask(keywordID int64, engine, priority string) *asynq.Task {
+	payload := []byte(fmt.Sprintf(fmtScrapeKeywordPayloadJSON, keywordID, engine))
+	return asynq.NewTask(TypeScrapeKeyword, payload,
+		asynq.TaskID(ScrapeKeywordTaskID(keywordID)),
+		asynq.Queue(ScrapeKeywordQueue(priority, engine)),
+	)
 }
 
-func NewScrapeKeywordTask(keywordID int64) *asynq.Task {
-	payload := []byte(fmt.Sprintf(fmtScrapeKeywordPayloadJSON, keywordID))
-	return asynq.NewTask(TypeScrapeKeyword, payload)
+func ScrapeKeywordTaskID(keywordID int64) string {
+	return fmt.Sprintf(fmtScrapeKeywordTaskID, keywordID)
 }
@@ -0,0 +1,23 @@
+package tasks
+
+import (
+	"fmt"
+
+	"github.com/hibiken/asynq"
+)
+
+const (
+	TypeUploadCompleted           = "upload:completed"
+	fmtUploadCompletedPayloadJSON = `{"uploadID": %d}`
+	fmtUploadCompletedTaskID      = "upload:completed:%d"
+)
+
+type UploadCompletedPayload struct {
+	UploadID int64 `json:"uploadID"`
+}
+
+// NewUploadCompletedTask creates the task sending the upload.completed webhooks and the summary email of an upload.
+func NewUploadCompletedTask(uploadID int64) *asynq.Task {
+	payload := []byte(fmt.Sprintf(fmtUploadCompletedPayloadJSON, uploadID))
+	return asynq.NewTask(TypeUploadCompleted, payload, asynq.TaskID(fmt.Sprintf(fmtUploadCompletedTaskID, uploadID)))
+}
@@ -0,0 +1,30 @@
+package tasks
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/hibiken/asynq"
+)
+
+const (
+	TypeDeliverWebhook           = "webhook:deliver"
+	fmtDeliverWebhookPayloadJSON = `{"deliveryID": %d}`
+	fmtDeliverWebhookTaskID      = "webhook:deliver:%d"
+	DeliverWebhookMaxRetry       = 10
+	deliverWebhookRetryDelayBase = 30 * time.Second
+	deliverWebhookRetryDelayMax  = 6 * time.Hour
+)
+
+type DeliverWebhookPayload struct {
+	DeliveryID int64 `json:"deliveryID"`
+}
+
+// NewDeliverWebhookTask creates the task sending a webhook delivery, retried with an exponential backoff by RetryDelay.
+func NewDeliverWebhookTask(deliveryID int64) *asynq.Task {
+	payload := []byte(fmt.Sprintf(fmtDeliverWebhookPayloadJSON, deliveryID))
+	return asynq.NewTask(TypeDeliverWebhook, payload,
+		asynq.TaskID(fmt.Sprintf(fmtDeliverWebhookTaskID, deliveryID)),
+		asynq.MaxRetry(DeliverWebhookMaxRetry),
+	)
+}
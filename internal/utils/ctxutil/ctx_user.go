@@ -2,6 +2,7 @@ package ctxutil
 
 import (
 	"context"
+	"time"
 
 	"github.com/google/uuid"
 )
@@ -9,6 +10,14 @@ import (
 type User struct {
 	ID    *uuid.UUID
 	Email string
+
+	// TokenID, SessionID and TokenExpiresAt are of the access token the user is authenticated with
+	TokenID        string
+	SessionID      *uuid.UUID
+	TokenExpiresAt time.Time
+
+	// APIKeyScope is the scope of the API key the user is authenticated with; empty for the access tokens
+	APIKeyScope string
 }
 
 func SetUser(ctx context.Context, user User) context.Context {
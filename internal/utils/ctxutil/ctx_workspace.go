@@ -0,0 +1,23 @@
+package ctxutil
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+// Workspace is the workspace a request is for, with the role of the user in it.
+type Workspace struct {
+	ID   uuid.UUID
+	Role string
+}
+
+func SetWorkspace(ctx context.Context, workspace Workspace) context.Context {
+	return context.WithValue(ctx, keyWorkspace, workspace)
+}
+
+func WorkspaceFromCtx(ctx context.Context) Workspace {
+	workspace, _ := ctx.Value(keyWorkspace).(Workspace)
+
+	return workspace
+}
@@ -5,6 +5,7 @@ import "context"
 const (
 	keyRequestID key = "requestID"
 	keyUser      key = "user"
+	keyWorkspace key = "workspace"
 )
 
 type key string
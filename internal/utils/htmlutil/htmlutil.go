@@ -0,0 +1,149 @@
+package htmlutil
+
+import (
+	"errors"
+	"io"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// Elements which are removed along with their content.
+var strippedElements = map[atom.Atom]bool{
+	atom.Script:   true,
+	atom.Noscript: true,
+	atom.Iframe:   true,
+	atom.Frame:    true,
+	atom.Frameset: true,
+	atom.Object:   true,
+	atom.Embed:    true,
+	atom.Applet:   true,
+	atom.Base:     true,
+}
+
+// Attributes which can hold a URL.
+var urlAttrs = map[string]bool{
+	"href":       true,
+	"src":        true,
+	"action":     true,
+	"formaction": true,
+	"xlink:href": true,
+}
+
+// StripScripts copies the HTML document from r to w without scripts, embedded frames and objects,
+// inline event handlers, javascript: URLs and meta refreshes. Everything else is copied as is.
+func StripScripts(w io.Writer, r io.Reader) error {
+	z := html.NewTokenizer(r)
+
+	// Depth of the stripped element the tokenizer is in, zero when not in one
+	var skipDepth int
+	var skipAtom atom.Atom
+
+	for {
+		tt := z.Next()
+		if tt == html.ErrorToken {
+			if errors.Is(z.Err(), io.EOF) {
+				return nil
+			}
+			return z.Err()
+		}
+
+		raw := z.Raw()
+		switch tt {
+		case html.StartTagToken, html.SelfClosingTagToken:
+			t := z.Token()
+
+			if skipDepth > 0 {
+				if t.DataAtom == skipAtom && tt == html.StartTagToken {
+					skipDepth++
+				}
+				continue
+			}
+
+			if strippedElements[t.DataAtom] {
+				if tt == html.StartTagToken && t.DataAtom != atom.Embed && t.DataAtom != atom.Base {
+					skipDepth, skipAtom = 1, t.DataAtom
+				}
+				continue
+			}
+
+			if isMetaRefresh(t) {
+				continue
+			}
+
+			if attrs, changed := safeAttrs(t.Attr); changed {
+				t.Attr = attrs
+				if _, err := io.WriteString(w, t.String()); err != nil {
+					return err
+				}
+				continue
+			}
+		case html.EndTagToken:
+			if skipDepth > 0 {
+				t := z.Token()
+				if t.DataAtom == skipAtom {
+					skipDepth--
+				}
+				continue
+			}
+
+			if name, _ := z.TagName(); strippedElements[atom.Lookup(name)] {
+				continue
+			}
+		default:
+			if skipDepth > 0 {
+				continue
+			}
+		}
+
+		if _, err := w.Write(raw); err != nil {
+			return err
+		}
+	}
+}
+
+func isMetaRefresh(t html.Token) bool {
+	if t.DataAtom != atom.Meta {
+		return false
+	}
+
+	for _, a := range t.Attr {
+		if strings.EqualFold(a.Key, "http-equiv") && strings.EqualFold(strings.TrimSpace(a.Val), "refresh") {
+			return true
+		}
+	}
+
+	return false
+}
+
+// safeAttrs returns the attributes without event handlers and script URLs, and reports whether any was removed.
+func safeAttrs(attrs []html.Attribute) ([]html.Attribute, bool) {
+	result := make([]html.Attribute, 0, len(attrs))
+	for _, a := range attrs {
+		key := strings.ToLower(a.Key)
+		if a.Namespace != "" {
+			key = a.Namespace + ":" + key
+		}
+
+		if strings.HasPrefix(key, "on") || (urlAttrs[key] && isScriptURL(a.Val)) {
+			continue
+		}
+
+		result = append(result, a)
+	}
+
+	return result, len(result) != len(attrs)
+}
+
+func isScriptURL(v string) bool {
+	// Browsers ignore whitespace and control characters in URL schemes
+	v = strings.Map(func(r rune) rune {
+		if r <= ' ' {
+			return -1
+		}
+		return r
+	}, strings.ToLower(v))
+
+	return strings.HasPrefix(v, "javascript:") || strings.HasPrefix(v, "vbscript:") || strings.HasPrefix(v, "data:text/html")
+}
@@ -0,0 +1,64 @@
+package htmlutil_test
+
+import (
+	"strings"
+	"testing"
+
+	"web-scraper.dev/internal/utils/htmlutil"
+)
+
+func TestStripScripts(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{
+			name:     "script",
+			input:    `<p>a</p><script>alert("<p>x</p>")</script><p>b</p>`,
+			expected: `<p>a</p><p>b</p>`,
+		},
+		{
+			name:     "nested frames",
+			input:    `<div><iframe src="https://example.com"><iframe></iframe></iframe>ok</div>`,
+			expected: `<div>ok</div>`,
+		},
+		{
+			name:     "event handlers",
+			input:    `<img src="a.png" onerror="alert(1)" alt="a">`,
+			expected: `<img src="a.png" alt="a">`,
+		},
+		{
+			name:     "script urls",
+			input:    `<a href=" java	script:alert(1)">x</a><a href="https://example.com">y</a>`,
+			expected: `<a>x</a><a href="https://example.com">y</a>`,
+		},
+		{
+			name:     "meta refresh and base",
+			input:    `<head><meta http-equiv="Refresh" content="0;url=https://example.com"><base href="https://example.com"><meta charset="utf-8"></head>`,
+			expected: `<head><meta charset="utf-8"></head>`,
+		},
+		{
+			name:     "style kept as is",
+			input:    `<style>a > b { color: red }</style>`,
+			expected: `<style>a > b { color: red }</style>`,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			var sb strings.Builder
+			if err := htmlutil.StripScripts(&sb, strings.NewReader(tc.input)); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			if sb.String() != tc.expected {
+				t.Errorf("Wrong output: got %v want %v", sb.String(), tc.expected)
+			}
+		})
+	}
+}
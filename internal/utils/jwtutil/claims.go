@@ -16,6 +16,7 @@ const issuer = "https://web-scraper.dev"
 type AccessTokenClaims struct {
 	TokenType string `json:"tokenType"`
 	UserEmail string `json:"userEmail"`
+	SessionID string `json:"sid,omitempty"`
 	*jwt.RegisteredClaims
 }
 
@@ -25,12 +26,13 @@ type RefreshTokenClaims struct {
 	*jwt.RegisteredClaims
 }
 
-func newAccessTokenClaims(userID, userEmail string) *AccessTokenClaims {
+func newAccessTokenClaims(userID, userEmail, sessionID string) *AccessTokenClaims {
 	id := fmt.Sprintf("%s-%06d", accessTokenIDPrefix, atomic.AddUint64(&accessTokenID, 1))
 
 	return &AccessTokenClaims{
 		TokenType: "access",
 		UserEmail: userEmail,
+		SessionID: sessionID,
 		RegisteredClaims: &jwt.RegisteredClaims{
 			Issuer:    issuer,
 			Subject:   userID,
@@ -87,9 +89,39 @@ func ClaimsFromAccessToken(token string) (*AccessTokenClaims, error) {
 	return claims, nil
 }
 
+func ClaimsFromRefreshToken(token string) (*RefreshTokenClaims, error) {
+	claims := &RefreshTokenClaims{}
+	_, err := jwt.ParseWithClaims(token, claims, func(token *jwt.Token) (interface{}, error) {
+		if token.Header["typ"] != "JWT" || token.Header["alg"] != jwt.SigningMethodEdDSA.Alg() {
+			return nil, errInvalidToken
+		}
+
+		return jwt.ParseEdPublicKeyFromPEM([]byte(refreshTokenPublicKey))
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case claims.ExpiresAt == nil,
+		claims.Issuer != "https://web-scraper.dev",
+		claims.Subject == "",
+		claims.IssuedAt == nil,
+		claims.ID == "",
+		claims.TokenType != "refresh",
+		claims.UserEmail == "":
+
+		return nil, errInvalidToken
+	}
+
+	return claims, nil
+}
+
 func (c *AccessTokenClaims) ToCtxUser() ctxutil.User {
 	ctxUser := ctxutil.User{
-		Email: c.UserEmail,
+		Email:          c.UserEmail,
+		TokenID:        c.RegisteredClaims.ID,
+		TokenExpiresAt: c.RegisteredClaims.ExpiresAt.Time,
 	}
 
 	userId, err := uuid.Parse(c.RegisteredClaims.Subject)
@@ -97,5 +129,11 @@ func (c *AccessTokenClaims) ToCtxUser() ctxutil.User {
 		ctxUser.ID = &userId
 	}
 
+	// Tokens issued before the sessions were tracked have no session ID
+	sessionID, err := uuid.Parse(c.SessionID)
+	if err == nil {
+		ctxUser.SessionID = &sessionID
+	}
+
 	return ctxUser
 }
@@ -20,7 +20,7 @@ var (
 	accessTokenLifetime, _ = time.ParseDuration(os.Getenv("ACCESS_TOKEN_LIFETIME"))
 	accessTokenAudiences   = []string{"web-scraper.dev"}
 
-	// refreshTokenPublicKey   = os.Getenv("REFRESH_TOKEN_PUBLIC_KEY")
+	refreshTokenPublicKey   = os.Getenv("REFRESH_TOKEN_PUBLIC_KEY")
 	refreshTokenPrivateKey  = os.Getenv("REFRESH_TOKEN_PRIVATE_KEY")
 	refreshTokenLifetime, _ = time.ParseDuration(os.Getenv("REFRESH_TOKEN_LIFETIME"))
 	refreshTokenAudiences   = []string{"web-scraper.dev"}
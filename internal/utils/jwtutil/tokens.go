@@ -1,27 +1,48 @@
 package jwtutil
 
-import "github.com/golang-jwt/jwt/v5"
+import (
+	"time"
 
-func NewAccessTokenAndRefreshToken(userID, userEmail string) (string, string, error) {
-	jwtAccessToken := jwt.NewWithClaims(jwt.SigningMethodEdDSA, newAccessTokenClaims(userID, userEmail))
+	"github.com/golang-jwt/jwt/v5"
+)
+
+type Tokens struct {
+	Access  string
+	Refresh string
+
+	// RefreshID and RefreshExpiresAt are the jti and exp claims of the refresh token, to keep track of its use
+	RefreshID        string
+	RefreshExpiresAt time.Time
+}
+
+// NewAccessTokenAndRefreshToken issues a pair of tokens; the access token carries the session ID,
+// so signing out with it can revoke the refresh tokens of the same session.
+func NewAccessTokenAndRefreshToken(userID, userEmail, sessionID string) (*Tokens, error) {
+	jwtAccessToken := jwt.NewWithClaims(jwt.SigningMethodEdDSA, newAccessTokenClaims(userID, userEmail, sessionID))
 	jwtAccessTokenPrivateKey, err := jwt.ParseEdPrivateKeyFromPEM([]byte(accessTokenPrivateKey))
 	if err != nil {
-		return "", "", err
+		return nil, err
 	}
 	accessToken, err := jwtAccessToken.SignedString(jwtAccessTokenPrivateKey)
 	if err != nil {
-		return "", "", err
+		return nil, err
 	}
 
-	jwtRefreshToken := jwt.NewWithClaims(jwt.SigningMethodEdDSA, newRefreshTokenClaims(userID, userEmail))
+	refreshClaims := newRefreshTokenClaims(userID, userEmail)
+	jwtRefreshToken := jwt.NewWithClaims(jwt.SigningMethodEdDSA, refreshClaims)
 	jwtRefreshTokenPrivateKey, err := jwt.ParseEdPrivateKeyFromPEM([]byte(refreshTokenPrivateKey))
 	if err != nil {
-		return "", "", err
+		return nil, err
 	}
 	refreshToken, err := jwtRefreshToken.SignedString(jwtRefreshTokenPrivateKey)
 	if err != nil {
-		return "", "", err
+		return nil, err
 	}
 
-	return accessToken, refreshToken, nil
+	return &Tokens{
+		Access:           accessToken,
+		Refresh:          refreshToken,
+		RefreshID:        refreshClaims.ID,
+		RefreshExpiresAt: refreshClaims.ExpiresAt.Time,
+	}, nil
 }
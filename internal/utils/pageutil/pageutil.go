@@ -0,0 +1,49 @@
+package pageutil
+
+import (
+	"net/http"
+	"strconv"
+)
+
+const (
+	HeaderKeyTotalCount = "X-Total-Count"
+
+	queryKeyPage    = "page"
+	queryKeyPerPage = "per_page"
+
+	DefaultPerPage = 20
+	MaxPerPage     = 100
+)
+
+type Page struct {
+	Number  int
+	PerPage int
+}
+
+// FromRequest reads the page and per_page query params, falling back to the defaults on missing or invalid values.
+func FromRequest(r *http.Request) Page {
+	p := Page{Number: 1, PerPage: DefaultPerPage}
+
+	q := r.URL.Query()
+	if v, err := strconv.Atoi(q.Get(queryKeyPage)); err == nil && v > 0 {
+		p.Number = v
+	}
+
+	if v, err := strconv.Atoi(q.Get(queryKeyPerPage)); err == nil && v > 0 {
+		p.PerPage = min(v, MaxPerPage)
+	}
+
+	return p
+}
+
+func (p Page) Offset() int {
+	return (p.Number - 1) * p.PerPage
+}
+
+func (p Page) Limit() int {
+	return p.PerPage
+}
+
+func SetTotalCount(w http.ResponseWriter, total int64) {
+	w.Header().Set(HeaderKeyTotalCount, strconv.FormatInt(total, 10))
+}
@@ -0,0 +1,41 @@
+package pageutil_test
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"web-scraper.dev/internal/utils/pageutil"
+)
+
+func TestFromRequest(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		query  string
+		page   pageutil.Page
+		offset int
+	}{
+		{"defaults", "", pageutil.Page{Number: 1, PerPage: pageutil.DefaultPerPage}, 0},
+		{"page and per page", "?page=3&per_page=10", pageutil.Page{Number: 3, PerPage: 10}, 20},
+		{"invalid values", "?page=-1&per_page=abc", pageutil.Page{Number: 1, PerPage: pageutil.DefaultPerPage}, 0},
+		{"exceed max per page", "?per_page=1000", pageutil.Page{Number: 1, PerPage: pageutil.MaxPerPage}, 0},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			r := httptest.NewRequest("GET", "/"+tc.query, nil)
+			page := pageutil.FromRequest(r)
+
+			if page != tc.page {
+				t.Errorf("Wrong page: got %+v want %+v", page, tc.page)
+			}
+
+			if page.Offset() != tc.offset {
+				t.Errorf("Wrong offset: got %v want %v", page.Offset(), tc.offset)
+			}
+		})
+	}
+}
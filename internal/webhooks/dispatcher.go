@@ -0,0 +1,120 @@
+package webhooks
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hibiken/asynq"
+
+	"web-scraper.dev/internal/model"
+	"web-scraper.dev/internal/repository"
+	"web-scraper.dev/internal/tasks"
+)
+
+// Dispatcher records the deliveries of the events to the webhooks of a user and enqueues their delivery tasks.
+type Dispatcher struct {
+	db   *repository.Db
+	asyq *asynq.Client
+}
+
+func NewDispatcher(db *repository.Db, asyq *asynq.Client) *Dispatcher {
+	return &Dispatcher{
+		db:   db,
+		asyq: asyq,
+	}
+}
+
+// Emit sends the event to the active webhooks of the user subscribed to its type.
+func (d *Dispatcher) Emit(userID uuid.UUID, eventType string, data any) error {
+	webhooks, err := d.db.ListActiveWebhooksByUserIdAndEvent(userID, eventType)
+	if err != nil || len(webhooks) == 0 {
+		return err
+	}
+
+	payload := &Payload{
+		ID:        uuid.New(),
+		Type:      eventType,
+		CreatedAt: time.Now().UTC(),
+		Data:      data,
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, v := range webhooks {
+		delivery := &model.WebhookDelivery{
+			WebhookID: v.ID,
+			EventID:   payload.ID,
+			EventType: eventType,
+			Payload:   string(body),
+			Status:    model.WebhookDeliveryStatusPending,
+		}
+
+		if err := d.deliver(delivery); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// EmitKeyword sends keyword.completed or keyword.failed with the keyword, by its status.
+func (d *Dispatcher) EmitKeyword(keyword *model.Keyword) error {
+	switch keyword.Status {
+	case model.KeywordStatusCompleted:
+		return d.Emit(keyword.UserID, model.WebhookEventKeywordCompleted, keyword.ToDTO())
+	case model.KeywordStatusFailed:
+		return d.Emit(keyword.UserID, model.WebhookEventKeywordFailed, keyword.ToDTO())
+	}
+
+	return nil
+}
+
+// EmitUpload sends upload.completed with the progress of the upload.
+func (d *Dispatcher) EmitUpload(upload *model.Upload, counts model.UploadStatusCounts) error {
+	return d.Emit(upload.UserID, model.WebhookEventUploadCompleted, upload.ToDTO(counts))
+}
+
+// Redeliver sends the event of a delivery again, as a new delivery.
+func (d *Dispatcher) Redeliver(delivery *model.WebhookDelivery) (*model.WebhookDelivery, error) {
+	redelivery := &model.WebhookDelivery{
+		WebhookID: delivery.WebhookID,
+		EventID:   delivery.EventID,
+		EventType: delivery.EventType,
+		Payload:   delivery.Payload,
+		Status:    model.WebhookDeliveryStatusPending,
+	}
+
+	if err := d.deliver(redelivery); err != nil {
+		return nil, err
+	}
+
+	return redelivery, nil
+}
+
+func (d *Dispatcher) deliver(delivery *model.WebhookDelivery) error {
+	if err := d.db.CreateWebhookDelivery(delivery); err != nil {
+		return err
+	}
+
+	if _, err := d.asyq.Enqueue(tasks.NewDeliverWebhookTask(delivery.ID)); err != nil {
+		errMsg := err.Error()
+		updates := map[string]any{
+			"status":        model.WebhookDeliveryStatusFailed,
+			"error_message": errMsg,
+		}
+		if err := d.db.UpdateWebhookDeliveryById(delivery.ID, updates); err != nil {
+			return err
+		}
+
+		delivery.Status, delivery.ErrorMessage = model.WebhookDeliveryStatusFailed, &errMsg
+		return err
+	}
+
+	return nil
+}
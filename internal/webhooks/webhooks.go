@@ -0,0 +1,225 @@
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/google/uuid"
+
+	"web-scraper.dev/internal/model"
+)
+
+const (
+	HeaderEvent     = "X-Webhook-Event"
+	HeaderEventID   = "X-Webhook-Event-Id"
+	HeaderDelivery  = "X-Webhook-Delivery"
+	HeaderSignature = "X-Webhook-Signature"
+
+	secretPrefix = "whsec_"
+	secretSize   = 32
+
+	userAgent           = "web-scraper-webhooks/1.0"
+	deliveryTimeout     = 10 * time.Second
+	dialTimeout         = 5 * time.Second
+	responseBodyMaxSize = 1024
+)
+
+// ErrPrivateAddress is returned for the webhook URLs of the hosts resolving to loopback, private or link-local addresses,
+// so the deliveries can't reach the internal services.
+var ErrPrivateAddress = errors.New("webhook host resolves to a private address")
+
+// Non public prefixes left out by netip.Addr.IsGlobalUnicast and IsPrivate; "this network" and the CGNAT shared addresses
+var nonPublicPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),
+	netip.MustParsePrefix("100.64.0.0/10"),
+}
+
+// Payload is the JSON body sent to the webhooks. Redeliveries keep the ID of the event, so receivers can skip duplicates.
+type Payload struct {
+	ID        uuid.UUID `json:"id"`
+	Type      string    `json:"type"`
+	CreatedAt time.Time `json:"createdAt"`
+	Data      any       `json:"data"`
+}
+
+type Response struct {
+	StatusCode int
+	Body       string
+}
+
+// NewSecret generates the secret a webhook signs its deliveries with.
+func NewSecret() (string, error) {
+	b := make([]byte, secretSize)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return secretPrefix + hex.EncodeToString(b), nil
+}
+
+// Sign returns the signature header value of a body sent at the given time; "t=<unix time>,v1=<hex HMAC-SHA256>".
+// The HMAC is of "<unix time>.<body>", so receivers can reject replayed deliveries by their age.
+func Sign(secret string, t time.Time, body []byte) string {
+	ts := strconv.FormatInt(t.Unix(), 10)
+	return "t=" + ts + ",v1=" + hex.EncodeToString(mac(secret, ts, body))
+}
+
+// Verify reports whether the signature header value matches the body, for receivers written in Go.
+func Verify(secret, signature string, body []byte) bool {
+	var ts string
+	var sigs [][]byte
+	for _, part := range strings.Split(signature, ",") {
+		k, v, _ := strings.Cut(part, "=")
+		switch k {
+		case "t":
+			ts = v
+		case "v1":
+			if sig, err := hex.DecodeString(v); err == nil {
+				sigs = append(sigs, sig)
+			}
+		}
+	}
+
+	if ts == "" {
+		return false
+	}
+
+	expected := mac(secret, ts, body)
+	for _, sig := range sigs {
+		if hmac.Equal(sig, expected) {
+			return true
+		}
+	}
+	return false
+}
+
+func mac(secret, ts string, body []byte) []byte {
+	h := hmac.New(sha256.New, []byte(secret))
+	h.Write([]byte(ts))
+	h.Write([]byte("."))
+	h.Write(body)
+	return h.Sum(nil)
+}
+
+// IsPublicAddr reports whether the address is a public unicast one.
+func IsPublicAddr(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	if !addr.IsGlobalUnicast() || addr.IsPrivate() {
+		return false
+	}
+
+	for _, v := range nonPublicPrefixes {
+		if v.Contains(addr) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// ValidateURL checks that the host of the webhook URL resolves to public addresses only.
+func ValidateURL(ctx context.Context, rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return err
+	}
+
+	addrs, err := net.DefaultResolver.LookupNetIP(ctx, "ip", u.Hostname())
+	if err != nil {
+		return err
+	}
+
+	for _, v := range addrs {
+		if !IsPublicAddr(v) {
+			return ErrPrivateAddress
+		}
+	}
+
+	return nil
+}
+
+// dialControl refuses to connect to the private addresses, checked once the host is resolved, so a webhook host
+// resolving to a public address when saved and to a private one when delivering is refused too.
+func dialControl(_, address string, _ syscall.RawConn) error {
+	addrPort, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return err
+	}
+
+	if !IsPublicAddr(addrPort.Addr()) {
+		return ErrPrivateAddress
+	}
+
+	return nil
+}
+
+// NewHTTPClient returns the client of the deliveries; redirects are not followed, as the signed request is for the webhook URL.
+// Only the public addresses are dialed, without the proxies of the environment.
+func NewHTTPClient() *http.Client {
+	dialer := &net.Dialer{
+		Timeout: dialTimeout,
+		Control: dialControl,
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+
+	return &http.Client{
+		Timeout:   deliveryTimeout,
+		Transport: transport,
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
+
+// Deliver posts the signed payload of a delivery to the webhook. Responses other than 2xx are returned with an error.
+func Deliver(ctx context.Context, client *http.Client, webhook *model.Webhook, delivery *model.WebhookDelivery) (*Response, error) {
+	body := []byte(delivery.Payload)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook.URL, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", userAgent)
+	req.Header.Set(HeaderEvent, delivery.EventType)
+	req.Header.Set(HeaderEventID, delivery.EventID.String())
+	req.Header.Set(HeaderDelivery, strconv.FormatInt(delivery.ID, 10))
+	req.Header.Set(HeaderSignature, Sign(webhook.Secret, time.Now(), body))
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(io.LimitReader(resp.Body, responseBodyMaxSize))
+	if err != nil {
+		return nil, err
+	}
+
+	res := &Response{StatusCode: resp.StatusCode, Body: string(respBody)}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return res, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+
+	return res, nil
+}
@@ -0,0 +1,169 @@
+package webhooks_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"web-scraper.dev/internal/model"
+	"web-scraper.dev/internal/webhooks"
+)
+
+func TestSign(t *testing.T) {
+	t.Parallel()
+
+	body := []byte(`{"type":"keyword.completed"}`)
+	ts := time.Unix(1700000000, 0)
+
+	// echo -n '1700000000.{"type":"keyword.completed"}' | openssl dgst -sha256 -hmac secret
+	expected := "t=1700000000,v1=67b100e370c57e3cd92759599be89b9b4343a54c1329a2618be5db10ed008abc"
+	signature := webhooks.Sign("secret", ts, body)
+	if signature != expected {
+		t.Fatalf("Wrong signature: got %v want %v", signature, expected)
+	}
+
+	if !webhooks.Verify("secret", signature, body) {
+		t.Errorf("Signature not verified: %v", signature)
+	}
+
+	if webhooks.Verify("other", signature, body) {
+		t.Errorf("Signature verified with a wrong secret: %v", signature)
+	}
+
+	if webhooks.Verify("secret", signature, []byte(`{}`)) {
+		t.Errorf("Signature verified with a wrong body: %v", signature)
+	}
+
+	if webhooks.Verify("secret", strings.Replace(signature, "t=1700000000", "t=1700000001", 1), body) {
+		t.Errorf("Signature verified with a wrong timestamp: %v", signature)
+	}
+}
+
+func TestNewSecret(t *testing.T) {
+	t.Parallel()
+
+	a, err := webhooks.NewSecret()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	b, _ := webhooks.NewSecret()
+	if !strings.HasPrefix(a, "whsec_") || len(a) != 70 || a == b {
+		t.Errorf("Wrong secrets: %v, %v", a, b)
+	}
+}
+
+func TestDeliver(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		statusCode int
+		wantErr    bool
+	}{
+		{"ok", http.StatusOK, false},
+		{"no content", http.StatusNoContent, false},
+		{"redirect", http.StatusFound, true},
+		{"server error", http.StatusInternalServerError, true},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			webhook := &model.Webhook{Secret: "secret"}
+			delivery := &model.WebhookDelivery{
+				Model2:    model.Model2{ID: 7},
+				EventID:   uuid.New(),
+				EventType: model.WebhookEventKeywordCompleted,
+				Payload:   `{"type":"keyword.completed"}`,
+			}
+
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body, _ := io.ReadAll(r.Body)
+				if !webhooks.Verify(webhook.Secret, r.Header.Get(webhooks.HeaderSignature), body) {
+					t.Errorf("Signature not verified: %v", r.Header.Get(webhooks.HeaderSignature))
+				}
+
+				if r.Header.Get(webhooks.HeaderEvent) != delivery.EventType || r.Header.Get(webhooks.HeaderDelivery) != "7" {
+					t.Errorf("Wrong headers: %v", r.Header)
+				}
+
+				if tc.statusCode == http.StatusFound {
+					w.Header().Set("Location", "/elsewhere")
+				}
+				w.WriteHeader(tc.statusCode)
+				io.WriteString(w, "received")
+			}))
+			defer srv.Close()
+
+			// The test server is on loopback, which the deliveries refuse to dial
+			client := webhooks.NewHTTPClient()
+			client.Transport = http.DefaultTransport
+
+			webhook.URL = srv.URL
+			resp, err := webhooks.Deliver(context.Background(), client, webhook, delivery)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("Wrong error: got %v want error %v", err, tc.wantErr)
+			}
+
+			if resp.StatusCode != tc.statusCode {
+				t.Errorf("Wrong status code: got %v want %v", resp.StatusCode, tc.statusCode)
+			}
+		})
+	}
+}
+
+func TestDeliverPrivateAddress(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("Private address dialed")
+	}))
+	defer srv.Close()
+
+	webhook := &model.Webhook{URL: srv.URL, Secret: "secret"}
+	delivery := &model.WebhookDelivery{EventID: uuid.New(), EventType: model.WebhookEventKeywordCompleted, Payload: `{}`}
+
+	_, err := webhooks.Deliver(context.Background(), webhooks.NewHTTPClient(), webhook, delivery)
+	if !errors.Is(err, webhooks.ErrPrivateAddress) {
+		t.Errorf("Wrong error: got %v want %v", err, webhooks.ErrPrivateAddress)
+	}
+}
+
+func TestIsPublicAddr(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		addr     string
+		expected bool
+	}{
+		{"93.184.216.34", true},
+		{"2606:2800:220:1:248:1893:25c8:1946", true},
+		{"127.0.0.1", false},
+		{"::1", false},
+		{"10.0.0.1", false},
+		{"172.16.0.1", false},
+		{"192.168.1.1", false},
+		{"169.254.169.254", false},
+		{"100.64.0.1", false},
+		{"0.0.0.0", false},
+		{"fc00::1", false},
+		{"fe80::1", false},
+		{"::ffff:127.0.0.1", false},
+	}
+
+	for _, tc := range tests {
+		if got := webhooks.IsPublicAddr(netip.MustParseAddr(tc.addr)); got != tc.expected {
+			t.Errorf("Wrong result for %v: got %v want %v", tc.addr, got, tc.expected)
+		}
+	}
+}
@@ -3,66 +3,165 @@ package workers
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"html"
-	"net/url"
+	"net/http"
+	"os"
+	"slices"
 	"strings"
 	"time"
 
 	"github.com/gocolly/colly/v2"
+	"github.com/google/uuid"
 	"github.com/hibiken/asynq"
+	"github.com/redis/go-redis/v9"
 	"gorm.io/gorm"
 
+	"web-scraper.dev/internal/blocks"
+	"web-scraper.dev/internal/events"
+	"web-scraper.dev/internal/fairqueue"
+	"web-scraper.dev/internal/mailer"
 	"web-scraper.dev/internal/model"
+	"web-scraper.dev/internal/proxies"
+	"web-scraper.dev/internal/ratelimit"
 	"web-scraper.dev/internal/repository"
+	"web-scraper.dev/internal/scheduler"
+	"web-scraper.dev/internal/searchengine"
 	"web-scraper.dev/internal/tasks"
 	l "web-scraper.dev/internal/utils/logger"
+	"web-scraper.dev/internal/webhooks"
 )
 
 const (
-	scraperUserAgent        = `"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/91.0.4472.124 Safari/537.36"`
-	fmtScrapeKeywordPageUrl = "https://www.bing.com/search?q=%s"
+	scraperUserAgent       = `"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/91.0.4472.124 Safari/537.36"`
+	scraperMobileUserAgent = `"Mozilla/5.0 (iPhone; CPU iPhone OS 17_5 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.5 Mobile/15E148 Safari/604.1"`
 )
 
 type ScrapingResult struct {
-	AdCount     int
-	LinkCount   int
-	HTMLContent string
+	searchengine.Result
+	HTMLContent    string
+	Classification string
 }
 
 type ScrapeWorker struct {
-	srv    *asynq.Server
-	db     *repository.Db
-	logger *l.Logger
-}
-
-func NewScrapeWorker(redisOpt asynq.RedisClientOpt, db *gorm.DB, logger *l.Logger) *ScrapeWorker {
-	srv := asynq.NewServer(
-		redisOpt,
-		asynq.Config{
-			Concurrency: 10,
-			Queues: map[string]int{
-				"default": 10,
-			},
-		},
-	)
+	srv        *asynq.Server
+	asyq       *asynq.Client
+	db         *repository.Db
+	rdb        redis.UniversalClient
+	events     *events.Broker
+	webhooks   *webhooks.Dispatcher
+	httpClient *http.Client
+	mailer     *mailer.Mailer
+	logger     *l.Logger
+	host       string
+
+	// The scrapes go through the proxies of the environment and the active ones of the proxies table, if any
+	proxies           *proxies.Pool
+	envProxies        []*proxies.Proxy
+	proxySyncInterval time.Duration
+
+	// The search engines blocking too many scrapes are paused for all the workers
+	blocks *blocks.Monitor
+
+	// The scrapes of a search engine, and through each proxy, are rate limited across all the workers
+	limiter    *ratelimit.Limiter
+	ratePolicy ratelimit.Policy
+
+	// The uploads and the re-scrapes wait in the fair queues of the worker's queues, fed to asynq round-robin across the workspaces
+	inspector    *asynq.Inspector
+	fair         *fairqueue.Queue
+	fairQueues   []fairQueue
+	feedInterval time.Duration
+	feedBacklog  int
+	inFlight     *fairqueue.InFlight
+
+	stopLoops context.CancelFunc
+}
+
+// fairQueue is a fair queue fed to the asynq queue of the same name.
+type fairQueue struct {
+	queue    string
+	priority string
+	engine   string
+}
+
+// fairKeyword is a keyword taken from a fair queue.
+type fairKeyword struct {
+	workspaceID uuid.UUID
+	keywordID   int64
+}
+
+// NewScrapeWorker creates the worker processing the tasks of the queues in srvConf, with the retries of the tasks package.
+func NewScrapeWorker(redisOpt asynq.RedisClientOpt, srvConf asynq.Config, db *gorm.DB, ml *mailer.Mailer, logger *l.Logger, pool *proxies.Pool, envProxies []*proxies.Proxy, proxySyncInterval time.Duration, blockPolicy blocks.Policy, ratePolicy ratelimit.Policy, feedInterval time.Duration, feedBacklog, userMaxInFlight int) *ScrapeWorker {
+	srvConf.RetryDelayFunc = tasks.RetryDelay
+	srvConf.IsFailure = tasks.IsFailure
+	srv := asynq.NewServer(redisOpt, srvConf)
+
+	host, _ := os.Hostname()
+	asyq := asynq.NewClient(redisOpt)
+	rdb := redisOpt.MakeRedisClient().(redis.UniversalClient)
+	repo := repository.New(db)
+
+	var fairQueues []fairQueue
+	for _, engine := range searchengine.Names() {
+		for _, priority := range tasks.FairPriorities {
+			queue := tasks.ScrapeKeywordQueue(priority, engine)
+			if _, ok := srvConf.Queues[queue]; ok {
+				fairQueues = append(fairQueues, fairQueue{queue: queue, priority: priority, engine: engine})
+			}
+		}
+	}
 
 	return &ScrapeWorker{
-		srv:    srv,
-		db:     repository.New(db),
-		logger: logger,
+		srv:        srv,
+		asyq:       asyq,
+		db:         repo,
+		rdb:        rdb,
+		events:     events.NewBroker(rdb),
+		webhooks:   webhooks.NewDispatcher(repo, asyq),
+		httpClient: webhooks.NewHTTPClient(),
+		mailer:     ml,
+		logger:     logger,
+		host:       host,
+
+		proxies:           pool,
+		envProxies:        envProxies,
+		proxySyncInterval: proxySyncInterval,
+
+		blocks: blocks.NewMonitor(rdb, blockPolicy),
+
+		limiter:    ratelimit.NewLimiter(rdb),
+		ratePolicy: ratePolicy,
+
+		inspector:    asynq.NewInspector(redisOpt),
+		fair:         fairqueue.New(rdb),
+		fairQueues:   fairQueues,
+		feedInterval: feedInterval,
+		feedBacklog:  feedBacklog,
+		inFlight:     fairqueue.NewInFlight(rdb, userMaxInFlight),
 	}
 }
 
 func (w *ScrapeWorker) Start() error {
+	ctx, cancel := context.WithCancel(context.Background())
+	w.stopLoops = cancel
+	w.syncProxies()
+	go w.runProxySync(ctx)
+	go w.runFeed(ctx)
+
 	mux := asynq.NewServeMux()
 	mux.HandleFunc(tasks.TypeScrapeKeyword, w.HandleSearchScrapeTask)
+	mux.HandleFunc(tasks.TypeDeliverWebhook, w.HandleDeliverWebhookTask)
+	mux.HandleFunc(tasks.TypeUploadCompleted, w.HandleUploadCompletedTask)
 	return w.srv.Run(mux)
 }
 
 func (w *ScrapeWorker) Stop() error {
+	if w.stopLoops != nil {
+		w.stopLoops()
+	}
 	w.srv.Shutdown()
-	return nil
+	return errors.Join(w.asyq.Close(), w.inspector.Close(), w.rdb.Close())
 }
 
 func (w *ScrapeWorker) HandleSearchScrapeTask(ctx context.Context, t *asynq.Task) error {
@@ -74,95 +173,642 @@ func (w *ScrapeWorker) HandleSearchScrapeTask(ctx context.Context, t *asynq.Task
 
 	keywordID := p.KeywordID
 
-	if err := w.db.Model(&model.Keyword{}).Where("id = ?", keywordID).Update("status", "processing").Error; err != nil {
+	var keyword model.Keyword
+	if err := w.db.First(&keyword, keywordID).Error; err != nil {
+		w.logger.Error().Err(err).Msg("failed to find keyword")
+		return err
+	}
+
+	// A keyword cancelled while waiting, ex: for the pause of its search engine or its fair queue
+	if keyword.Status == model.KeywordStatusCancelled {
+		w.logger.Info().Msgf("Cancelled KeywordID: %d", keywordID)
+		return nil
+	}
+
+	// The keyword stays pending until the pause of its search engine is over
+	pausedUntil, err := w.blocks.PausedUntil(ctx, keyword.SearchEngine)
+	if err != nil {
+		w.logger.Error().Err(err).Str("search_engine", keyword.SearchEngine).Msg("failed to check search engine pause")
+	} else if pausedUntil != nil {
+		return &tasks.EnginePausedError{Engine: keyword.SearchEngine, Until: *pausedUntil}
+	}
+
+	// The keyword stays pending while its user has too many scrapes in flight, letting the scrapes of the other users through
+	taskID, _ := asynq.GetTaskID(ctx)
+	acquired, err := w.inFlight.Acquire(ctx, keyword.UserID, taskID)
+	if err != nil {
+		w.logger.Error().Err(err).Msg("failed to acquire user in-flight slot")
+	} else if !acquired {
+		return &tasks.UserBusyError{UserID: keyword.UserID}
+	}
+	if acquired {
+		defer w.releaseInFlight(keyword.UserID, taskID)
+	}
+
+	res := w.db.Model(&model.Keyword{}).Where("id = ? AND status <> ?", keywordID, model.KeywordStatusCancelled).Update("status", model.KeywordStatusProcessing)
+	if err := res.Error; err != nil {
 		w.logger.Error().Err(err).Msg("failed to update keyword status")
 		return err
 	}
 
-	var keyword model.Keyword
-	if err := w.db.First(&keyword, keywordID).Error; err != nil {
-		w.logger.Error().Err(err).Msg("failed to find keyword")
+	// The keyword has been cancelled since it was loaded
+	if res.RowsAffected == 0 {
+		w.logger.Info().Msgf("Cancelled KeywordID: %d", keywordID)
+		return nil
+	}
+	keyword.Status = model.KeywordStatusProcessing
+
+	w.publishKeyword(ctx, &keyword)
+
+	keywordScrape := model.NewKeywordScrape(&keyword, w.host)
+	if err := w.db.CreateKeywordScrape(keywordScrape); err != nil {
+		w.logger.Error().Err(err).Msg("failed to create keyword scrape")
+		return err
+	}
+
+	if err := w.db.Model(&model.Keyword{}).Where("id = ?", keywordID).Update("last_scrape_id", keywordScrape.ID).Error; err != nil {
+		w.logger.Error().Err(err).Msg("failed to update keyword last scrape")
 		return err
 	}
 
-	result, err := w.scrapeKeyword(keyword.Keyword)
+	se, err := searchengine.Get(keyword.SearchEngine)
 	if err != nil {
+		w.logger.Error().Err(err).Str("search_engine", keyword.SearchEngine).Msg("failed to find search engine")
+		w.updateKeywordError(ctx, &keyword, keywordScrape.ID, err.Error(), "", true)
+		return fmt.Errorf("%w: %w", err, asynq.SkipRetry)
+	}
+
+	var proxy *proxies.Proxy
+	if w.proxies.Len() > 0 {
+		proxy, err = w.proxies.Acquire(ctx)
+		if err != nil {
+			w.logger.Error().Err(err).Msg("failed to acquire proxy")
+			w.updateKeywordError(ctx, &keyword, keywordScrape.ID, err.Error(), "", isFinalAttempt(ctx))
+			return err
+		}
+
+		if err := w.db.UpdateKeywordScrapeById(keywordScrape.ID, map[string]any{"proxy": proxy.Name()}); err != nil {
+			w.logger.Error().Err(err).Msg("failed to update keyword scrape proxy")
+		}
+	}
+
+	result, err := w.scrapeKeyword(ctx, se, &keyword, proxy)
+	if err != nil {
+		if w.isKeywordCancelled(keywordID) {
+			w.logger.Info().Msgf("Cancelled KeywordID: %d", keywordID)
+			w.updateKeywordScrapeCancelled(keywordScrape.ID)
+			w.completeUpload(&keyword)
+			return fmt.Errorf("%w: %w", err, asynq.SkipRetry)
+		}
+
 		w.logger.Error().Err(err).Msg("failed to scrape keyword")
-		w.updateKeywordError(keywordID, err.Error())
+		w.updateKeywordError(ctx, &keyword, keywordScrape.ID, err.Error(), "", isFinalAttempt(ctx))
 		return err
 	}
 
+	blocked := searchengine.IsBlocked(result.Classification)
+	w.recordBlock(ctx, se.Name(), blocked)
+
+	if blocked {
+		w.logger.Warn().Str("search_engine", se.Name()).Str("classification", result.Classification).Msgf("Blocked KeywordID: %d", keywordID)
+		return w.retryBlocked(ctx, &keyword, keywordScrape.ID, result.Classification)
+	}
+
+	if result.Classification == searchengine.ClassificationParseDrift {
+		errMsg := "no result found on the results page, its markup may have changed"
+		w.logger.Error().Str("search_engine", se.Name()).Msgf("Parse drift KeywordID: %d", keywordID)
+		w.updateKeywordError(ctx, &keyword, keywordScrape.ID, errMsg, result.Classification, true)
+		return fmt.Errorf("%s: %w", errMsg, asynq.SkipRetry)
+	}
+
+	finishedAt := time.Now()
 	updates := map[string]interface{}{
-		"status":       "completed",
-		"ad_count":     result.AdCount,
-		"link_count":   result.LinkCount,
-		"html_content": html.EscapeString(result.HTMLContent),
+		"status":          model.KeywordStatusCompleted,
+		"ad_count":        result.AdCount,
+		"link_count":      result.LinkCount,
+		"html_content":    result.HTMLContent,
+		"error_message":   nil,
+		"classification":  result.Classification,
+		"last_scraped_at": finishedAt,
+		"next_scrape_at":  w.nextScrapeAt(&keyword, finishedAt),
+	}
+
+	scrapeUpdates := map[string]interface{}{
+		"status":         model.KeywordStatusCompleted,
+		"ad_count":       result.AdCount,
+		"link_count":     result.LinkCount,
+		"classification": result.Classification,
+		"finished_at":    finishedAt,
 	}
 
-	if err := w.db.Model(&model.Keyword{}).Where("id = ?", keywordID).Updates(updates).Error; err != nil {
+	tx := w.db.TxBegin()
+	res = tx.Model(&model.Keyword{}).Where("id = ? AND status = ?", keywordID, model.KeywordStatusProcessing).Updates(updates)
+	if err := res.Error; err != nil {
+		tx.Rollback()
 		w.logger.Error().Err(err).Msg("failed to update keyword results")
 		return err
 	}
 
+	// The keyword has been cancelled while scraping
+	if res.RowsAffected == 0 {
+		tx.Rollback()
+		w.logger.Info().Msgf("Cancelled KeywordID: %d", keywordID)
+		w.updateKeywordScrapeCancelled(keywordScrape.ID)
+		w.completeUpload(&keyword)
+		return nil
+	}
+
+	if err := tx.UpdateKeywordScrapeById(keywordScrape.ID, scrapeUpdates); err != nil {
+		tx.Rollback()
+		w.logger.Error().Err(err).Msg("failed to update keyword scrape results")
+		return err
+	}
+
+	serpResults := toSerpResults(keywordID, keywordScrape.ID, result.OrganicResults)
+	serpAds := toSerpAds(keywordID, keywordScrape.ID, result.Ads)
+	if err := tx.CreateSerpResultsAndAds(serpResults, serpAds); err != nil {
+		tx.Rollback()
+		w.logger.Error().Err(err).Msg("failed to create keyword serp results")
+		return err
+	}
+	tx.Commit()
+
+	adCount, linkCount := int64(result.AdCount), int64(result.LinkCount)
+	keyword.Status, keyword.AdCount, keyword.LinkCount, keyword.ErrorMessage = model.KeywordStatusCompleted, &adCount, &linkCount, nil
+	keyword.Classification = &result.Classification
+	keyword.LastScrapeID, keyword.LastScrapedAt = &keywordScrape.ID, &finishedAt
+	w.publishKeyword(ctx, &keyword)
+	w.emitKeywordWebhook(&keyword)
+	w.completeUpload(&keyword)
+
 	w.logger.Info().Msgf("Successfully processed KeywordID: %d", keywordID)
 	return nil
 }
 
-func (w *ScrapeWorker) updateKeywordError(keywordID int64, errorMsg string) {
+// updateKeywordError fails the scrape of the keyword, with the classification of the page if any. The keyword is failed
+// only on the final attempt of its task and put back to pending until the retry otherwise, so it's reported failed once.
+func (w *ScrapeWorker) updateKeywordError(ctx context.Context, keyword *model.Keyword, keywordScrapeID int64, errorMsg, classification string, final bool) {
+	if !final {
+		if _, err := w.retryKeyword(ctx, keyword, keywordScrapeID, errorMsg, classification); err != nil {
+			w.logger.Error().Err(err).Msg("failed to update keyword error")
+		}
+		return
+	}
+
+	var classificationValue *string
+	if classification != "" {
+		classificationValue = &classification
+	}
+
+	finishedAt := time.Now()
 	updates := map[string]interface{}{
-		"status":        "failed",
-		"error_message": errorMsg,
+		"status":          model.KeywordStatusFailed,
+		"error_message":   errorMsg,
+		"classification":  classificationValue,
+		"last_scraped_at": finishedAt,
+		"next_scrape_at":  w.nextScrapeAt(keyword, finishedAt),
 	}
 
-	if err := w.db.Model(&model.Keyword{}).Where("id = ?", keywordID).Updates(updates).Error; err != nil {
+	res := w.db.Model(&model.Keyword{}).Where("id = ? AND status <> ?", keyword.ID, model.KeywordStatusCancelled).Updates(updates)
+	if err := res.Error; err != nil {
 		w.logger.Error().Err(err).Msg("failed to update keyword error")
+	} else if res.RowsAffected == 0 {
+		// The keyword has been cancelled while scraping
+		w.logger.Info().Msgf("Cancelled KeywordID: %d", keyword.ID)
+		w.updateKeywordScrapeCancelled(keywordScrapeID)
+		w.completeUpload(keyword)
+		return
+	} else {
+		keyword.Status, keyword.ErrorMessage, keyword.Classification = model.KeywordStatusFailed, &errorMsg, classificationValue
+		keyword.LastScrapeID, keyword.LastScrapedAt = &keywordScrapeID, &finishedAt
+		w.publishKeyword(ctx, keyword)
+		w.emitKeywordWebhook(keyword)
+		w.completeUpload(keyword)
+	}
+
+	scrapeUpdates := map[string]interface{}{
+		"status":         model.KeywordStatusFailed,
+		"error_message":  errorMsg,
+		"classification": classificationValue,
+		"finished_at":    finishedAt,
+	}
+
+	if err := w.db.UpdateKeywordScrapeById(keywordScrapeID, scrapeUpdates); err != nil {
+		w.logger.Error().Err(err).Msg("failed to update keyword scrape error")
+	}
+}
+
+// retryBlocked puts a keyword blocked by its search engine back to pending until the retry of its task, failing only its scrape.
+// The keyword is failed on the last attempt.
+func (w *ScrapeWorker) retryBlocked(ctx context.Context, keyword *model.Keyword, keywordScrapeID int64, classification string) error {
+	blockedErr := fmt.Errorf("%w: %s", tasks.ErrScrapeBlocked, classification)
+	errMsg := blockedErr.Error()
+
+	if isFinalAttempt(ctx) {
+		w.updateKeywordError(ctx, keyword, keywordScrapeID, errMsg, classification, true)
+		return blockedErr
+	}
+
+	retried, err := w.retryKeyword(ctx, keyword, keywordScrapeID, errMsg, classification)
+	if err != nil {
+		w.logger.Error().Err(err).Msg("failed to update keyword block")
+		return err
+	}
+
+	// The keyword has been cancelled while scraping
+	if !retried {
+		return nil
+	}
+
+	return blockedErr
+}
+
+// retryKeyword puts a keyword back to pending until the retry of its task, failing only its scrape.
+// Reports false if the keyword has been cancelled while scraping.
+func (w *ScrapeWorker) retryKeyword(ctx context.Context, keyword *model.Keyword, keywordScrapeID int64, errorMsg, classification string) (bool, error) {
+	var classificationValue *string
+	if classification != "" {
+		classificationValue = &classification
+	}
+
+	updates := map[string]interface{}{
+		"status":         model.KeywordStatusPending,
+		"error_message":  errorMsg,
+		"classification": classificationValue,
+	}
+
+	res := w.db.Model(&model.Keyword{}).Where("id = ? AND status = ?", keyword.ID, model.KeywordStatusProcessing).Updates(updates)
+	if err := res.Error; err != nil {
+		return false, err
+	}
+
+	if res.RowsAffected == 0 {
+		w.logger.Info().Msgf("Cancelled KeywordID: %d", keyword.ID)
+		w.updateKeywordScrapeCancelled(keywordScrapeID)
+		w.completeUpload(keyword)
+		return false, nil
+	}
+
+	scrapeUpdates := map[string]interface{}{
+		"status":         model.KeywordStatusFailed,
+		"error_message":  errorMsg,
+		"classification": classificationValue,
+		"finished_at":    time.Now(),
+	}
+
+	if err := w.db.UpdateKeywordScrapeById(keywordScrapeID, scrapeUpdates); err != nil {
+		w.logger.Error().Err(err).Msg("failed to update keyword scrape error")
+	}
+
+	keyword.Status, keyword.ErrorMessage, keyword.Classification = model.KeywordStatusPending, &errorMsg, classificationValue
+	keyword.LastScrapeID = &keywordScrapeID
+	w.publishKeyword(ctx, keyword)
+
+	return true, nil
+}
+
+// recordBlock counts a scrape for the block rate of its search engine, which pauses the search engine for all the workers past the threshold.
+func (w *ScrapeWorker) recordBlock(ctx context.Context, engine string, blocked bool) {
+	pausedUntil, err := w.blocks.Record(ctx, engine, blocked)
+	if err != nil {
+		w.logger.Error().Err(err).Str("search_engine", engine).Msg("failed to record search engine block")
+		return
+	}
+
+	if pausedUntil != nil {
+		w.logger.Warn().Str("search_engine", engine).Time("paused_until", *pausedUntil).Msg("search engine paused")
+	}
+}
+
+// isFinalAttempt reports whether the task won't be retried once it fails.
+func isFinalAttempt(ctx context.Context) bool {
+	retried, _ := asynq.GetRetryCount(ctx)
+	maxRetry, _ := asynq.GetMaxRetry(ctx)
+	return retried >= maxRetry
+}
+
+func (w *ScrapeWorker) isKeywordCancelled(keywordID int64) bool {
+	var keyword model.Keyword
+	if err := w.db.Select("status").First(&keyword, keywordID).Error; err != nil {
+		w.logger.Error().Err(err).Msg("failed to find keyword")
+		return false
+	}
+
+	return keyword.Status == model.KeywordStatusCancelled
+}
+
+func (w *ScrapeWorker) updateKeywordScrapeCancelled(keywordScrapeID int64) {
+	updates := map[string]interface{}{
+		"status":      model.KeywordStatusCancelled,
+		"finished_at": time.Now(),
+	}
+
+	if err := w.db.UpdateKeywordScrapeById(keywordScrapeID, updates); err != nil {
+		w.logger.Error().Err(err).Msg("failed to update keyword scrape cancellation")
 	}
 }
 
-func (w *ScrapeWorker) scrapeKeyword(keyword string) (*ScrapingResult, error) {
+// publishKeyword pushes the keyword status to the clients of its workspace. Failures are only logged as clients can reload.
+func (w *ScrapeWorker) publishKeyword(ctx context.Context, keyword *model.Keyword) {
+	if err := w.events.PublishKeyword(ctx, keyword); err != nil {
+		w.logger.Error().Err(err).Int64("keyword_id", keyword.ID).Msg("failed to publish keyword event")
+	}
+}
+
+// emitKeywordWebhook sends keyword.completed or keyword.failed to the webhooks of the keyword's user.
+func (w *ScrapeWorker) emitKeywordWebhook(keyword *model.Keyword) {
+	if err := w.webhooks.EmitKeyword(keyword); err != nil {
+		w.logger.Error().Err(err).Int64("keyword_id", keyword.ID).Msg("failed to emit keyword webhook")
+	}
+}
+
+// completeUpload enqueues the upload completed task once the last keyword of the keyword's upload is finished.
+func (w *ScrapeWorker) completeUpload(keyword *model.Keyword) {
+	if keyword.UploadID == nil {
+		return
+	}
+
+	retrying, err := w.retryingUploadKeywordIDs(*keyword.UploadID)
+	if err != nil {
+		w.logger.Error().Err(err).Int64("upload_id", *keyword.UploadID).Msg("failed to complete upload")
+		return
+	}
+
+	completed, err := w.db.CompleteUploadById(*keyword.UploadID, retrying)
+	if err == nil && completed {
+		_, err = w.asyq.Enqueue(tasks.NewUploadCompletedTask(*keyword.UploadID))
+	}
+	if err != nil {
+		w.logger.Error().Err(err).Int64("upload_id", *keyword.UploadID).Msg("failed to complete upload")
+	}
+}
+
+// retryingUploadKeywordIDs returns the IDs of the failed keywords of the upload with their scrape tasks waiting for a retry.
+func (w *ScrapeWorker) retryingUploadKeywordIDs(uploadID int64) ([]int64, error) {
+	failed, err := w.db.ListFailedKeywordEnginesByUploadId(uploadID)
+	if err != nil {
+		return nil, err
+	}
+
+	retrying := make([]int64, 0)
+	for _, v := range failed {
+		ok, err := tasks.TaskRetrying(w.inspector, tasks.ScrapeKeywordQueues(v.SearchEngine), tasks.ScrapeKeywordTaskID(v.ID))
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			retrying = append(retrying, v.ID)
+		}
+	}
+
+	return retrying, nil
+}
+
+// nextScrapeAt returns the next scheduled scrape time of a recurring keyword, nil otherwise.
+func (w *ScrapeWorker) nextScrapeAt(keyword *model.Keyword, t time.Time) *time.Time {
+	if keyword.Schedule == nil {
+		return nil
+	}
+
+	next, err := scheduler.Next(*keyword.Schedule, t)
+	if err != nil {
+		w.logger.Error().Err(err).Int64("keyword_id", keyword.ID).Msg("failed to calculate next scrape time")
+		return nil
+	}
+
+	return &next
+}
+
+// syncProxies loads the active proxies of the proxies table into the pool along with the ones of the environment.
+func (w *ScrapeWorker) syncProxies() {
+	ps, err := w.db.ListActiveProxies()
+	if err != nil {
+		w.logger.Error().Err(err).Msg("failed to list active proxies")
+		return
+	}
+
+	pool := make([]*proxies.Proxy, 0, len(w.envProxies)+len(ps))
+	pool = append(pool, w.envProxies...)
+	for _, v := range ps {
+		u, err := proxies.ParseURL(v.URL)
+		if err != nil {
+			w.logger.Error().Err(err).Int64("proxy_id", v.ID).Msg("failed to parse proxy URL")
+			continue
+		}
+
+		pool = append(pool, proxies.New(v.ID, u))
+	}
+
+	w.proxies.Set(pool)
+}
+
+func (w *ScrapeWorker) runProxySync(ctx context.Context) {
+	ticker := time.NewTicker(w.proxySyncInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			w.syncProxies()
+		}
+	}
+}
+
+// releaseInFlight frees the in-flight slot of the user, even once the task is cancelled.
+func (w *ScrapeWorker) releaseInFlight(userID uuid.UUID, taskID string) {
+	if err := w.inFlight.Release(context.Background(), userID, taskID); err != nil {
+		w.logger.Error().Err(err).Str("user_id", userID.String()).Msg("failed to release user in-flight slot")
+	}
+}
+
+func (w *ScrapeWorker) runFeed(ctx context.Context) {
+	if len(w.fairQueues) == 0 {
+		return
+	}
+
+	ticker := time.NewTicker(w.feedInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			w.feed(ctx)
+		}
+	}
+}
+
+// feed moves the keywords of the fair queues to their asynq queues round-robin across the workspaces, keeping up to
+// feedBacklog unfinished tasks in each, so the order is decided by the fair queues. The workers of a queue all feed it.
+func (w *ScrapeWorker) feed(ctx context.Context) {
+	queues, err := w.inspector.Queues()
+	if err != nil {
+		w.logger.Error().Err(err).Msg("failed to list queues")
+		return
+	}
+
+	for _, v := range w.fairQueues {
+		var waiting int
+		if slices.Contains(queues, v.queue) {
+			info, err := w.inspector.GetQueueInfo(v.queue)
+			if err != nil {
+				w.logger.Error().Err(err).Str("queue", v.queue).Msg("failed to get queue info")
+				continue
+			}
+			// The tasks retrying, ex: for a busy user or a paused search engine, and the active ones still hold their place
+			waiting = info.Pending + info.Scheduled + info.Retry + info.Active
+		}
+
+		// The keywords with their tasks still waiting or being processed are put back behind the others of their workspace
+		// once the queue is fed, to be enqueued again when their tasks are finished
+		var live []fairKeyword
+
+		for waiting < w.feedBacklog {
+			workspaceID, keywordID, ok, err := w.fair.Pop(ctx, v.queue)
+			if err != nil {
+				w.logger.Error().Err(err).Str("queue", v.queue).Msg("failed to pop fair queue")
+				break
+			}
+			if !ok {
+				break
+			}
+
+			err = w.enqueueFair(keywordID, &v)
+			if errors.Is(err, tasks.ErrTaskLive) {
+				live = append(live, fairKeyword{workspaceID: workspaceID, keywordID: keywordID})
+				continue
+			}
+
+			// The keyword is put back for the next feed, so it isn't lost
+			if err != nil {
+				w.logger.Error().Err(err).Int64("keyword_id", keywordID).Msg("failed to enqueue keyword from fair queue")
+				if err := w.fair.Requeue(ctx, v.queue, workspaceID, keywordID); err != nil {
+					w.logger.Error().Err(err).Int64("keyword_id", keywordID).Msg("failed to requeue keyword to fair queue")
+				}
+				break
+			}
+			waiting++
+		}
+
+		for _, k := range live {
+			if err := w.fair.Push(ctx, v.queue, k.workspaceID, k.keywordID); err != nil {
+				w.logger.Error().Err(err).Int64("keyword_id", k.keywordID).Msg("failed to requeue keyword to fair queue")
+			}
+		}
+	}
+}
+
+// enqueueFair enqueues the scrape task of a keyword taken from the fair queue, freeing its task ID held by a previous archived
+// or completed run first if any. Returns tasks.ErrTaskLive if the task of the keyword is still waiting or being processed.
+func (w *ScrapeWorker) enqueueFair(keywordID int64, fq *fairQueue) error {
+	task := tasks.NewScrapeKeywordTask(keywordID, fq.engine, fq.priority)
+	_, err := w.asyq.Enqueue(task)
+	if !errors.Is(err, asynq.ErrTaskIDConflict) {
+		return err
+	}
+
+	// The task IDs are unique by queue
+	if err := tasks.DeleteFinishedTask(w.inspector, []string{fq.queue}, tasks.ScrapeKeywordTaskID(keywordID)); err != nil {
+		return err
+	}
+
+	_, err = w.asyq.Enqueue(task)
+	return err
+}
+
+// reportProxy records the outcome of a scrape through a proxy, which quarantines the proxy after too many blocks or failures in a row.
+func (w *ScrapeWorker) reportProxy(proxy *proxies.Proxy, outcome string) {
+	quarantinedUntil := w.proxies.Report(proxy, outcome)
+	if quarantinedUntil != nil {
+		w.logger.Warn().Str("proxy", proxy.Name()).Time("quarantined_until", *quarantinedUntil).Msg("proxy quarantined")
+	}
+
+	// The proxies of the environment are not in the proxies table
+	if proxy.ID == 0 {
+		return
+	}
+
+	if err := w.db.UpdateProxyUsageById(proxy.ID, outcome, quarantinedUntil); err != nil {
+		w.logger.Error().Err(err).Int64("proxy_id", proxy.ID).Msg("failed to update proxy usage")
+	}
+}
+
+// scrapeKeyword scrapes the results page of a keyword, through the proxy if any.
+func (w *ScrapeWorker) scrapeKeyword(ctx context.Context, se searchengine.SearchEngine, keyword *model.Keyword, proxy *proxies.Proxy) (*ScrapingResult, error) {
+	userAgent := scraperUserAgent
+	if keyword.Device == model.KeywordDeviceMobile {
+		userAgent = scraperMobileUserAgent
+	}
+
 	c := colly.NewCollector(
-		colly.UserAgent(scraperUserAgent),
+		colly.UserAgent(userAgent),
+		colly.StdlibContext(ctx),
 	)
 
-	c.Limit(&colly.LimitRule{
-		DomainGlob:  "*bing.*",
-		Parallelism: 1,
-		Delay:       tasks.ScrapeKeywordDelayInSeconds * time.Second,
-	})
+	c.Limit(se.LimitRule())
 
 	c.SetRequestTimeout(30 * time.Second)
 
+	if proxy != nil {
+		c.SetProxyFunc(http.ProxyURL(proxy.URL))
+	}
+
 	var result ScrapingResult
 	var htmlContent strings.Builder
+	var statusCode int
 
-	// Count Bing ads
-	c.OnHTML(".b_ad, .b_adurl, .sb_add, [data-bm], .b_adSlug", func(e *colly.HTMLElement) {
-		result.AdCount++
-	})
-
-	// Count all links
-	c.OnHTML("a[href]", func(e *colly.HTMLElement) {
-		href := e.Attr("href")
-		if href != "" && !strings.HasPrefix(href, "#") {
-			result.LinkCount++
-		}
+	// Extract ads, links and results, then tell the results pages from the challenge, consent and no results pages
+	c.OnHTML("html", func(e *colly.HTMLElement) {
+		se.Extract(e, &result.Result)
+		result.Classification = searchengine.Classify(se, e, &result.Result)
 	})
 
 	// Capture HTML content
 	c.OnResponse(func(r *colly.Response) {
+		statusCode = r.StatusCode
 		htmlContent.Write(r.Body)
 	})
 
-	// Construct Bing search URL
-	searchURL := fmt.Sprintf(fmtScrapeKeywordPageUrl, url.QueryEscape(keyword))
+	c.OnError(func(r *colly.Response, _ error) {
+		statusCode = r.StatusCode
+	})
+
+	// Wait for the turn of the search engine, and of the proxy, across all the workers
+	buckets := []ratelimit.Bucket{w.ratePolicy.EngineBucket(se.Name())}
+	if proxy != nil {
+		buckets = append(buckets, w.ratePolicy.ProxyBucket(se.Name(), proxy.Name()))
+	}
+	if _, err := w.limiter.Wait(ctx, buckets...); err != nil {
+		return nil, fmt.Errorf("failed to wait for rate limit: %w", err)
+	}
 
 	// Visit the URL
-	err := c.Visit(searchURL)
+	err := c.Visit(se.SearchURL(keyword.Keyword, keyword.Market))
+	c.Wait()
+
+	// The refused requests are blocked scrapes rather than errors
+	if err != nil {
+		if classification := searchengine.ClassifyStatus(statusCode); classification != "" {
+			result.Classification, err = classification, nil
+		}
+	}
+
+	// A cancelled scrape says nothing about the proxy
+	if proxy != nil && ctx.Err() == nil {
+		w.reportProxy(proxy, proxyOutcome(result.Classification, err))
+	}
+
 	if err != nil {
 		return nil, fmt.Errorf("failed to visit URL: %v", err)
 	}
 
-	c.Wait()
+	// A page without an html element has nothing recognizable
+	if result.Classification == "" {
+		result.Classification = searchengine.ClassificationParseDrift
+	}
 
 	// Set HTML content
 	htmlStr := htmlContent.String()
@@ -173,3 +819,48 @@ func (w *ScrapeWorker) scrapeKeyword(keyword string) (*ScrapingResult, error) {
 
 	return &result, nil
 }
+
+// proxyOutcome is the outcome of a scrape through a proxy from the classification of its page.
+func proxyOutcome(classification string, err error) string {
+	switch {
+	case err != nil:
+		return proxies.OutcomeFailed
+	case searchengine.IsBlocked(classification):
+		return proxies.OutcomeBlocked
+	default:
+		return proxies.OutcomeSuccess
+	}
+}
+
+func toSerpResults(keywordID int64, keywordScrapeID int64, results []*searchengine.OrganicResult) model.SerpResults {
+	serpResults := make(model.SerpResults, len(results))
+	for i, v := range results {
+		serpResults[i] = &model.SerpResult{
+			KeywordID:       keywordID,
+			KeywordScrapeID: keywordScrapeID,
+			Position:        v.Position,
+			Title:           v.Title,
+			URL:             v.URL,
+			DisplayURL:      v.DisplayURL,
+			Snippet:         v.Snippet,
+		}
+	}
+
+	return serpResults
+}
+
+func toSerpAds(keywordID int64, keywordScrapeID int64, ads []*searchengine.Ad) model.SerpAds {
+	serpAds := make(model.SerpAds, len(ads))
+	for i, v := range ads {
+		serpAds[i] = &model.SerpAd{
+			KeywordID:        keywordID,
+			KeywordScrapeID:  keywordScrapeID,
+			Position:         v.Position,
+			Block:            v.Block,
+			AdvertiserDomain: v.AdvertiserDomain,
+			Headline:         v.Headline,
+		}
+	}
+
+	return serpAds
+}
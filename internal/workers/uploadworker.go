@@ -0,0 +1,95 @@
+package workers
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/hibiken/asynq"
+
+	"web-scraper.dev/internal/mailer"
+	"web-scraper.dev/internal/model"
+	"web-scraper.dev/internal/tasks"
+)
+
+const uploadSummaryTopKeywords = 5
+
+// HandleUploadCompletedTask sends the upload.completed webhooks and, unless the user turned it off, the summary email of a finished upload.
+// Only the email is retried, so a failing mail server doesn't repeat the webhooks.
+func (w *ScrapeWorker) HandleUploadCompletedTask(ctx context.Context, t *asynq.Task) error {
+	var p tasks.UploadCompletedPayload
+	if err := json.Unmarshal(t.Payload(), &p); err != nil {
+		w.logger.Error().Err(err).Msg("failed to unmarshal payload")
+		return err
+	}
+
+	upload, err := w.db.ReadUploadById(p.UploadID)
+	if err != nil {
+		w.logger.Error().Err(err).Msg("failed to find upload")
+		return err
+	}
+
+	counts, err := w.db.CountKeywordsByUploadIds([]int64{upload.ID})
+	if err != nil {
+		w.logger.Error().Err(err).Msg("failed to count upload keywords")
+		return err
+	}
+
+	if retried, _ := asynq.GetRetryCount(ctx); retried == 0 {
+		if err := w.webhooks.EmitUpload(upload, counts[upload.ID]); err != nil {
+			w.logger.Error().Err(err).Int64("upload_id", upload.ID).Msg("failed to emit upload webhook")
+		}
+	}
+
+	pref, err := w.db.ReadUserPreferenceByUserId(upload.UserID)
+	if err != nil {
+		w.logger.Error().Err(err).Msg("failed to find user preferences")
+		return err
+	}
+
+	if !pref.UploadSummaryEmail {
+		return nil
+	}
+
+	user, err := w.db.ReadUserById(upload.UserID)
+	if err != nil {
+		w.logger.Error().Err(err).Msg("failed to find user")
+		return err
+	}
+
+	topKeywords, err := w.db.ListTopKeywordsByUploadId(upload.ID, uploadSummaryTopKeywords)
+	if err != nil {
+		w.logger.Error().Err(err).Msg("failed to list top keywords")
+		return err
+	}
+
+	if err := w.mailer.UploadSummaryMail(user.Email, upload.ID, toUploadSummaryEmail(upload, counts[upload.ID], topKeywords)); err != nil {
+		w.logger.Error().Err(err).Int64("upload_id", upload.ID).Msg("failed to send upload summary email")
+		return err
+	}
+
+	return nil
+}
+
+func toUploadSummaryEmail(upload *model.Upload, counts model.UploadStatusCounts, topKeywords model.Keywords) *mailer.UploadSummaryEmail {
+	data := &mailer.UploadSummaryEmail{
+		Filename:    upload.Filename,
+		Completed:   counts[model.KeywordStatusCompleted],
+		Failed:      counts[model.KeywordStatusFailed],
+		Cancelled:   counts[model.KeywordStatusCancelled],
+		TopKeywords: make([]*mailer.UploadSummaryKeyword, len(topKeywords)),
+	}
+	data.Total = data.Completed + data.Failed + data.Cancelled
+
+	for i, v := range topKeywords {
+		k := &mailer.UploadSummaryKeyword{Keyword: v.Keyword, SearchEngine: v.SearchEngine}
+		if v.AdCount != nil {
+			k.AdCount = *v.AdCount
+		}
+		if v.LinkCount != nil {
+			k.LinkCount = *v.LinkCount
+		}
+		data.TopKeywords[i] = k
+	}
+
+	return data
+}
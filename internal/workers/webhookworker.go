@@ -0,0 +1,95 @@
+package workers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/hibiken/asynq"
+	"gorm.io/gorm"
+
+	"web-scraper.dev/internal/model"
+	"web-scraper.dev/internal/tasks"
+	"web-scraper.dev/internal/webhooks"
+)
+
+// HandleDeliverWebhookTask sends a webhook delivery and logs the outcome of the attempt.
+// Failed attempts are retried by asynq; the delivery is failed once there are no retries left.
+func (w *ScrapeWorker) HandleDeliverWebhookTask(ctx context.Context, t *asynq.Task) error {
+	var p tasks.DeliverWebhookPayload
+	if err := json.Unmarshal(t.Payload(), &p); err != nil {
+		w.logger.Error().Err(err).Msg("failed to unmarshal payload")
+		return err
+	}
+
+	delivery, err := w.db.ReadWebhookDeliveryById(p.DeliveryID)
+	if err != nil {
+		// The webhook has been deleted with its deliveries
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+
+		w.logger.Error().Err(err).Msg("failed to find webhook delivery")
+		return err
+	}
+
+	webhook, err := w.db.ReadWebhookById(delivery.WebhookID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+
+		w.logger.Error().Err(err).Msg("failed to find webhook")
+		return err
+	}
+
+	if !webhook.Active {
+		w.updateWebhookDelivery(delivery.ID, map[string]any{
+			"status":        model.WebhookDeliveryStatusFailed,
+			"error_message": "webhook is inactive",
+		})
+		return fmt.Errorf("webhook %d is inactive: %w", webhook.ID, asynq.SkipRetry)
+	}
+
+	resp, err := webhooks.Deliver(ctx, w.httpClient, webhook, delivery)
+
+	updates := map[string]any{
+		"status":        model.WebhookDeliveryStatusSucceeded,
+		"attempts":      gorm.Expr("attempts + 1"),
+		"response_code": nil,
+		"response_body": nil,
+		"error_message": nil,
+		"delivered_at":  time.Now(),
+	}
+	if resp != nil {
+		updates["response_code"], updates["response_body"] = resp.StatusCode, resp.Body
+	}
+	if err != nil {
+		updates["error_message"] = err.Error()
+
+		retried, _ := asynq.GetRetryCount(ctx)
+		maxRetry, _ := asynq.GetMaxRetry(ctx)
+		if retried >= maxRetry {
+			updates["status"] = model.WebhookDeliveryStatusFailed
+		} else {
+			updates["status"] = model.WebhookDeliveryStatusPending
+		}
+	}
+
+	w.updateWebhookDelivery(delivery.ID, updates)
+
+	if err != nil {
+		w.logger.Info().Err(err).Int64("webhook_delivery_id", delivery.ID).Msg("failed to deliver webhook")
+		return err
+	}
+
+	return nil
+}
+
+func (w *ScrapeWorker) updateWebhookDelivery(id int64, updates map[string]any) {
+	if err := w.db.UpdateWebhookDeliveryById(id, updates); err != nil {
+		w.logger.Error().Err(err).Int64("webhook_delivery_id", id).Msg("failed to update webhook delivery")
+	}
+}